@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/adryledo/arca-cli/internal/advisory"
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var auditFailOn string
+
+// warnAdvisories prints the advisories affecting the lockfile and the locked
+// versions they could not be checked against; sync reports them without
+// failing.
+func warnAdvisories(matches []advisory.Match, unchecked []models.LockedAsset) {
+	for _, m := range matches {
+		fmt.Printf("⚠️  %s@%s is affected by %s [%s]: %s\n", m.Asset, m.Version, m.Advisory.ID, m.Advisory.Severity, m.Advisory.Summary)
+		if m.Advisory.Remediation != "" {
+			fmt.Printf("   💡 %s\n", m.Advisory.Remediation)
+		}
+	}
+	for _, la := range unchecked {
+		fmt.Printf("⚠️  %s@%s was not checked against its advisories: %q is not a semantic version\n", la.ID, la.Version, la.Version)
+	}
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check locked asset versions against the configured advisory feeds",
+	Long: `Checks every version in .arca-assets.lock against the advisory feeds listed
+under advisories in .arca-assets.yaml. Exits with an error when an advisory
+at or above --fail-on applies; use --fail-on off to only report.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		failOn, err := scan.ParseSeverity(auditFailOn)
+		if err != nil {
+			return err
+		}
+		cwd, _ := os.Getwd()
+		cfgMgr := config.NewManager(cwd)
+		cfg, err := cfgMgr.LoadConfig()
+		if err != nil {
+			return err
+		}
+		lock, err := cfgMgr.LoadLockfile()
+		if err != nil {
+			return err
+		}
+		if len(cfg.Advisories) == 0 {
+			return fmt.Errorf("no advisory feeds configured; add advisories to %s", config.ConfigFileName)
+		}
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
+
+		advisories, err := advisory.Load(cmd.Context(), cfg, cwd, res.Source)
+		if err != nil {
+			return err
+		}
+		matches, unchecked := advisory.Check(cfg, advisories, lock.Assets)
+		failed := 0
+		for _, m := range matches {
+			if scan.Severity(m.Advisory.Severity).AtLeast(failOn) {
+				failed++
+			}
+		}
+
+		if jsonOutput {
+			if matches == nil {
+				matches = []advisory.Match{}
+			}
+			data, _ := json.MarshalIndent(matches, "", "  ")
+			fmt.Println(string(data))
+			for _, la := range unchecked {
+				fmt.Fprintf(os.Stderr, "⚠️  %s@%s (%s) was not checked: %q is not a semantic version\n", la.ID, la.Version, la.Source, la.Version)
+			}
+		} else {
+			for _, m := range matches {
+				icon := "⚠️ "
+				if scan.Severity(m.Advisory.Severity).AtLeast(failOn) {
+					icon = "❌"
+				}
+				fmt.Printf("%s %s@%s (%s): %s [%s] %s\n", icon, m.Asset, m.Version, m.Source, m.Advisory.ID, m.Advisory.Severity, m.Advisory.Summary)
+				if m.Advisory.Remediation != "" {
+					fmt.Printf("   💡 %s\n", m.Advisory.Remediation)
+				}
+				if m.Advisory.URL != "" {
+					fmt.Printf("   🔗 %s\n", m.Advisory.URL)
+				}
+			}
+			for _, la := range unchecked {
+				fmt.Printf("⚠️  %s@%s (%s): not checked, %q is not a semantic version\n", la.ID, la.Version, la.Source, la.Version)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("audit found %d advisory match(es) at or above %s severity", failed, failOn)
+		}
+		if !jsonOutput {
+			fmt.Printf("✨ Audited %d locked asset(s) against %d advisories: %d match(es), %d unchecked.\n", len(lock.Assets), len(advisories), len(matches), len(unchecked))
+		}
+		return nil
+	},
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditFailOn, "fail-on", "low", "Lowest advisory severity that fails the audit: low, medium, high, critical or off")
+	rootCmd.AddCommand(auditCmd)
+}
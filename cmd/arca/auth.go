@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect source authentication",
+}
+
+// sourceAuthStatus is the credential that applies to one configured source.
+type sourceAuthStatus struct {
+	Source string `json:"source"`
+	URL    string `json:"url"`
+	auth.Status
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which credential applies to each configured source",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := os.Getwd()
+		cfg, err := config.NewManager(cwd).LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		aliases := make([]string, 0, len(cfg.Sources))
+		for alias := range cfg.Sources {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+
+		statuses := []sourceAuthStatus{}
+		for _, alias := range aliases {
+			src := cfg.Sources[alias]
+			if src.Type == models.SourceLocal {
+				statuses = append(statuses, sourceAuthStatus{Source: alias, URL: src.Path, Status: auth.Status{Method: "none", From: "local"}})
+				continue
+			}
+			status, err := auth.Describe(src.URL)
+			if err != nil {
+				return fmt.Errorf("failed to resolve credentials for %s: %w", alias, err)
+			}
+			statuses = append(statuses, sourceAuthStatus{Source: alias, URL: src.URL, Status: status})
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(statuses, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(statuses) == 0 {
+			fmt.Println("No sources defined in .arca-assets.yaml")
+			return nil
+		}
+
+		fmt.Println("🔑 Source credentials:")
+		fmt.Println(strings.Repeat("-", 60))
+		for _, s := range statuses {
+			fmt.Printf("%s (%s)\n", s.Source, s.URL)
+			switch {
+			case s.From == "local":
+				fmt.Println("   📁 local path, no credentials needed")
+			case s.Method == "none":
+				fmt.Println("   ⚪ anonymous (no credential applies)")
+			case s.Method == "ssh":
+				fmt.Printf("   🔐 ssh as %s via %s", s.Username, s.From)
+				if s.KeyFile != "" {
+					fmt.Printf(" (%s)", s.KeyFile)
+				}
+				fmt.Println()
+			default:
+				fmt.Printf("   🔐 token for user %q from %s\n", s.Username, s.From)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+	rootCmd.AddCommand(authCmd)
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/downloader"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/resolver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOlderThanDays int
+	pruneDryRun        bool
+	pruneLegacy        bool
+	exportLockPath     string
+	exportOutput       string
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and maintain the global asset cache",
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List cached assets with their size per source, asset and version",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		entries, err := cache.Entries()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			if entries == nil {
+				entries = []downloader.CacheEntry{}
+			}
+			data, _ := json.MarshalIndent(entries, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Printf("Cache at %s is empty.\n", cache.CacheRoot)
+			return nil
+		}
+
+		fmt.Printf("💾 Cache: %s\n", cache.CacheRoot)
+		for _, seed := range cache.Seeds {
+			fmt.Printf("🌱 Seed (read-only): %s\n", seed)
+		}
+		fmt.Println(strings.Repeat("-", 60))
+
+		var total int64
+		source := ""
+		for _, e := range entries {
+			if e.Source != source {
+				source = e.Source
+				fmt.Printf("📁 %s\n", source)
+			}
+			status := ""
+			if e.Missing {
+				status = " (object missing)"
+			}
+			fmt.Printf("   📦 %s@%s  %s  %s%s\n", e.ID, e.Version, humanSize(e.Size), shortHash(e.SHA256), status)
+			total += e.Size
+		}
+		fmt.Println(strings.Repeat("-", 60))
+		fmt.Printf("%d entries, %s referenced\n", len(entries), humanSize(total))
+		return nil
+	},
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Re-hash every cached object and report corruption",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		checks, err := cache.Verify()
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, c := range checks {
+			if !c.OK {
+				failed++
+			}
+		}
+
+		if jsonOutput {
+			if checks == nil {
+				checks = []downloader.ObjectCheck{}
+			}
+			data, _ := json.MarshalIndent(checks, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			for _, c := range checks {
+				if !c.OK {
+					fmt.Printf("❌ %s: %s\n", shortHash(c.SHA256), c.Problem)
+				}
+			}
+			if failed == 0 {
+				fmt.Printf("✅ %d object(s) verified.\n", len(checks))
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("cache verification failed for %d object(s); run 'arca cache prune' or 'arca cache clear'", failed)
+		}
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cache entries not referenced by any known workspace lockfile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+
+		referenced, err := referencedHashes(cache)
+		if err != nil {
+			return err
+		}
+
+		result, err := cache.Prune(downloader.PruneOptions{
+			Referenced: referenced,
+			OlderThan:  time.Duration(pruneOlderThanDays) * 24 * time.Hour,
+			DryRun:     pruneDryRun,
+			Legacy:     pruneLegacy,
+		})
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		verb := "Removed"
+		if pruneDryRun {
+			verb = "Would remove"
+		}
+		for _, e := range result.Entries {
+			fmt.Printf("🗑️  %s %s/%s@%s\n", verb, e.Source, e.ID, e.Version)
+		}
+		for _, dir := range result.Legacy {
+			fmt.Printf("🗑️  %s legacy directory %s\n", verb, dir)
+		}
+		fmt.Printf("✨ %s %d entries and %d object(s), %s freed.\n", verb, len(result.Entries), len(result.Objects), humanSize(result.FreedBytes))
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove everything from the cache",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		if err := cache.Clear(); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(map[string]any{"cleared": true, "path": cache.CacheRoot}, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		fmt.Printf("🧹 Cleared %s\n", cache.CacheRoot)
+		return nil
+	},
+}
+
+var cacheExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Pack the cache entries and manifests a lockfile needs into a bundle",
+	Long: `Writes a .tar.gz bundle with every asset locked in a lockfile and the
+source manifests at the locked commits, together with a checksum index.
+Import it with 'arca cache import' on machines without network access; a
+later 'arca sync' there is then served from the cache. Run 'arca sync' first
+so that every locked asset is cached.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lockPath, err := filepath.Abs(exportLockPath)
+		if err != nil {
+			return err
+		}
+		if filepath.Base(lockPath) != config.LockFileName {
+			return fmt.Errorf("expected a %s file, got %s", config.LockFileName, exportLockPath)
+		}
+		cfgMgr := config.NewManager(filepath.Dir(lockPath))
+		lock, err := cfgMgr.LoadLockfile()
+		if err != nil {
+			return err
+		}
+		if len(lock.Assets) == 0 {
+			return fmt.Errorf("no locked assets found in %s", exportLockPath)
+		}
+		cfg, err := cfgMgr.LoadConfig()
+		if err != nil {
+			return err
+		}
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		res, err := newResolver(cfgMgr.WorkspaceRoot, cache, cfg)
+		if err != nil {
+			return err
+		}
+
+		var entries []downloader.BundleEntry
+		var manifests []downloader.BundleManifest
+		seen := make(map[string]bool)
+		for _, la := range lock.Assets {
+			entries = append(entries, downloader.BundleEntry{
+				Source:  la.Source,
+				ID:      la.ID,
+				Version: la.Version,
+				SHA256:  la.SHA256,
+				Commit:  la.Commit,
+			})
+			src, ok := cfg.Sources[la.Source]
+			if !ok || src.Type == models.SourceLocal || la.Commit == "" || seen[la.Source+"@"+la.Commit] {
+				continue
+			}
+			seen[la.Source+"@"+la.Commit] = true
+			data, err := res.FetchManifest(cmd.Context(), src, la.Commit)
+			if err != nil {
+				return fmt.Errorf("failed to fetch manifest of %s at %s: %w", la.Source, la.Commit, err)
+			}
+			manifests = append(manifests, downloader.BundleManifest{
+				Source: resolver.ManifestSourceKey(src),
+				Ref:    la.Commit,
+				Data:   data,
+			})
+		}
+
+		tmp := exportOutput + ".tmp"
+		f, err := os.Create(tmp)
+		if err != nil {
+			return err
+		}
+		index, err := cache.ExportBundle(f, entries, manifests)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to export bundle: %w", err)
+		}
+		if err := os.Rename(tmp, exportOutput); err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(index, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		info, _ := os.Stat(exportOutput)
+		fmt.Printf("📦 Exported %d entries and %d manifests to %s (%s)\n", len(index.Entries), len(index.Manifests), exportOutput, humanSize(info.Size()))
+		return nil
+	},
+}
+
+var cacheImportCmd = &cobra.Command{
+	Use:   "import [bundle]",
+	Short: "Verify a bundle from 'arca cache export' and add it to the cache",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		index, err := cache.ImportBundle(f)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(index, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		fmt.Printf("📥 Imported %d entries and %d manifests into %s\n", len(index.Entries), len(index.Manifests), cache.CacheRoot)
+		for _, e := range index.Entries {
+			fmt.Printf("   ✅ %s/%s@%s  %s\n", e.Source, e.ID, e.Version, shortHash(e.SHA256))
+		}
+		return nil
+	},
+}
+
+// referencedHashes collects the digests locked by every workspace known to the
+// cache and by the current directory. Workspaces that no longer exist are
+// dropped from the registry.
+func referencedHashes(cache *downloader.CacheProvider) (map[string]bool, error) {
+	workspaces, err := cache.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		workspaces = append(workspaces, cwd)
+	}
+
+	referenced := make(map[string]bool)
+	var gone []string
+	for _, ws := range workspaces {
+		if _, err := os.Stat(ws); os.IsNotExist(err) {
+			gone = append(gone, ws)
+			continue
+		}
+		lock, err := config.NewManager(ws).LoadLockfile()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read lockfile of %s: %w", ws, err)
+		}
+		for _, la := range lock.Assets {
+			referenced[la.SHA256] = true
+		}
+	}
+
+	if len(gone) > 0 && !pruneDryRun {
+		if err := cache.ForgetWorkspaces(gone); err != nil {
+			return nil, err
+		}
+	}
+	return referenced, nil
+}
+
+// humanSize formats a byte count for display.
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// shortHash abbreviates a SHA-256 digest for display.
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func init() {
+	cachePruneCmd.Flags().IntVar(&pruneOlderThanDays, "older-than", 0, "Also remove entries stored more than N days ago")
+	cachePruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Report what would be removed without deleting anything")
+	cachePruneCmd.Flags().BoolVar(&pruneLegacy, "legacy", false, "Also remove asset versions stored in the former <source>/<id>/<version> layout")
+	cacheExportCmd.Flags().StringVar(&exportLockPath, "lock", config.LockFileName, "Lockfile whose assets are exported")
+	cacheExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "arca-bundle.tar.gz", "Bundle file to write")
+	cacheCmd.AddCommand(cacheLsCmd, cacheVerifyCmd, cachePruneCmd, cacheClearCmd, cacheExportCmd, cacheImportCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/adryledo/arca-cli/internal/fsutil"
 	"github.com/adryledo/arca-cli/internal/models"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -72,9 +73,10 @@ var initCmd = &cobra.Command{
 
 				relPath := filepath.ToSlash(filepath.Join(folder, name))
 
+				fm := readFrontmatter(filepath.Join(fullPath, entry.Name()), kind)
 				desc := fmt.Sprintf("Auto-discovered %s", kind)
-				if extDesc := extractDescription(filepath.Join(fullPath, entry.Name()), kind); extDesc != "" {
-					desc = extDesc
+				if fm.Description != "" {
+					desc = fm.Description
 				}
 
 				asset, ok := manifest.Assets[assetID]
@@ -86,6 +88,10 @@ var initCmd = &cobra.Command{
 					}
 				}
 
+				if asset.License == "" {
+					asset.License = fm.License
+				}
+
 				if asset.Versions == nil {
 					asset.Versions = make(map[string]models.ManifestVersion)
 				}
@@ -132,7 +138,7 @@ var initCmd = &cobra.Command{
 			return err
 		}
 
-		if err := os.WriteFile(manifestPath, newData, 0644); err != nil {
+		if err := fsutil.WriteFileAtomic(manifestPath, newData, 0644); err != nil {
 			return err
 		}
 
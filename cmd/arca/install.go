@@ -3,21 +3,22 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/adryledo/arca-cli/internal/config"
-	"github.com/adryledo/arca-cli/internal/downloader"
-	"github.com/adryledo/arca-cli/internal/hasher"
+	"github.com/adryledo/arca-cli/internal/license"
 	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/policy"
 	"github.com/adryledo/arca-cli/internal/projector"
-	"github.com/adryledo/arca-cli/internal/resolver"
+	"github.com/adryledo/arca-cli/internal/scan"
+	"github.com/adryledo/arca-cli/internal/signing"
 	"github.com/spf13/cobra"
 )
 
 var (
 	targetPath string
 	projName   string
+	sourceType string
 )
 
 var installCmd = &cobra.Command{
@@ -33,28 +34,52 @@ var installCmd = &cobra.Command{
 		}
 
 		cwd, _ := os.Getwd()
-		res := resolver.New(cwd)
 		proj := projector.New(cwd)
 		cfgMgr := config.NewManager(cwd)
-		cache := downloader.NewCacheProvider("")
+		wsLock, err := cfgMgr.Lock()
+		if err != nil {
+			return err
+		}
+		defer wsLock.Unlock()
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
 
 		// 1. Load existing config
 		cfg, err := cfgMgr.LoadConfig()
 		if err != nil {
 			return err
 		}
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
+		scanner, err := scan.New(cfg.Scan, cwd)
+		if err != nil {
+			return err
+		}
 
 		// 2. Identify source
-		stype := models.SourceGit
-		if info, err := os.Stat(sourceStr); err == nil && info.IsDir() {
-			stype = models.SourceLocal
+		stype, err := sourceTypeFor(sourceStr, sourceType)
+		if err != nil {
+			return err
 		}
 		sourceAlias := cfgMgr.EnsureSource(cfg, sourceStr, stype)
 
+		ctx := cmd.Context()
+		pol, err := policy.Load(ctx, cwd, res.Source)
+		if err != nil {
+			return err
+		}
+		if err := policy.Err(pol.CheckSource(sourceAlias, cfg.Sources[sourceAlias], res.Rewrites)); err != nil {
+			return err
+		}
+
 		fmt.Printf("🔍 Resolving asset %s from %s (%s)...\n", assetID, sourceStr, sourceAlias)
 
 		// 3. Load Manifest
-		manifest, err := res.LoadManifest(cfg.Sources[sourceAlias], "main")
+		manifest, err := res.LoadManifest(ctx, cfg.Sources[sourceAlias], "")
 		if err != nil {
 			return err
 		}
@@ -76,49 +101,43 @@ var installCmd = &cobra.Command{
 			fmt.Printf("📦 Installing %s@%s...\n", item.ID, item.Version)
 
 			isDir := item.Kind == models.KindSkill
-			commitSHA := ""
-			assetPath := cache.GetAssetPath(sourceAlias, item.ID, item.Version, isDir)
-			cacheDir, _ := cache.EnsureDir(sourceAlias, item.ID, item.Version)
-
-			if stype == models.SourceLocal {
-				absPath := filepath.Join(sourceStr, item.Meta.Path)
-				if isDir {
-					commitSHA = "local"
-				} else {
-					data, err := os.ReadFile(absPath)
-					if err != nil {
-						return err
-					}
-					err = os.WriteFile(assetPath, data, 0644)
-					if err != nil {
-						return err
-					}
-					commitSHA = "local"
-				}
-			} else {
-				gitDownloader := downloader.NewGitDownloader()
-				ref := item.Meta.Ref
-				if ref == "" {
-					ref = "main"
-				}
-				if isDir {
-					sha, err := gitDownloader.FetchDirectory(sourceStr, item.Meta.Path, ref, cacheDir)
-					if err != nil {
-						return err
-					}
-					commitSHA = sha
-				} else {
-					data, sha, err := gitDownloader.FetchFile(sourceStr, item.Meta.Path, ref)
-					if err != nil {
-						return err
-					}
-					err = os.WriteFile(assetPath, []byte(data), 0644)
-					if err != nil {
-						return err
-					}
-					commitSHA = sha
+			toFetch := syncItem{
+				ID:           item.ID,
+				Source:       cfg.Sources[sourceAlias],
+				SourceAlias:  sourceAlias,
+				Version:      item.Version,
+				Meta:         item.Meta,
+				Kind:         item.Kind,
+				License:      item.License,
+				Dependencies: item.Dependencies,
+			}
+			signedBy, err := signing.Verify(toFetch.Source.Trust, toFetch.signedEntry(), item.Meta.Signatures)
+			if err != nil {
+				return abortRun("install", proj, err)
+			}
+			if err := policy.Err(pol.CheckAsset(policy.Asset{ID: item.ID, Version: item.Version, Source: sourceAlias, SignedBy: signedBy})); err != nil {
+				return abortRun("install", proj, err)
+			}
+			if err := license.Check(cfg.Licenses, item.License); err != nil {
+				return abortRun("install", proj, fmt.Errorf("%s@%s: %w", item.ID, item.Version, err))
+			}
+			refSignedBy, err := verifyItemRef(ctx, res, &toFetch, lock, false, cwd)
+			if err != nil {
+				return abortRun("install", proj, err)
+			}
+			if !acceptRefChange {
+				if err := pinLockedCommit(&toFetch, lock); err != nil {
+					return abortRun("install", proj, err)
 				}
 			}
+			fetched, err := fetchItem(ctx, res, toFetch, cache)
+			if err != nil {
+				return abortRun("install", proj, err)
+			}
+
+			if err := scanAsset(scanner, item.ID, item.Version, fetched.Path, isDir); err != nil {
+				return abortRun("install", proj, err)
+			}
 
 			// Projection
 			actualTarget := targetPath
@@ -140,9 +159,9 @@ var installCmd = &cobra.Command{
 				actualTarget = fmt.Sprintf(".arca/assets/%s/%s%s", sourceAlias, item.ID, ext)
 			}
 
-			_, err = proj.Project(assetPath, actualTarget, isDir)
+			_, err = proj.Project(ctx, fetched.Path, actualTarget, isDir)
 			if err != nil {
-				return err
+				return abortRun("install", proj, err)
 			}
 			fmt.Printf("   🔗 Projected %s to %s\n", item.ID, actualTarget)
 
@@ -161,43 +180,35 @@ var installCmd = &cobra.Command{
 			}
 
 			// Update Lockfile Entry
-			var contentHash string
-			if isDir {
-				contentHash, err = hasher.HashDir(assetPath)
-			} else {
-				contentHash, err = hasher.HashFile(assetPath)
-			}
-			if err != nil {
-				return fmt.Errorf("failed to hash asset: %w", err)
-			}
-			locked := models.LockedAsset{
-				ID:         item.ID,
-				Version:    item.Version,
-				Source:     sourceAlias,
-				Commit:     commitSHA,
-				SHA256:     contentHash,
-				ResolvedAt: time.Now(),
-			}
-
-			found := false
-			for i, la := range lock.Assets {
-				if la.ID == item.ID && la.Source == sourceAlias {
-					lock.Assets[i] = locked
-					found = true
-					break
-				}
-			}
-			if !found {
-				lock.Assets = append(lock.Assets, locked)
-			}
+			upsertLocked(lock, models.LockedAsset{
+				ID:          item.ID,
+				Version:     item.Version,
+				Source:      sourceAlias,
+				URL:         cfg.Sources[sourceAlias].URL,
+				Commit:      fetched.Commit,
+				SHA256:      fetched.SHA256,
+				License:     item.License,
+				SignedBy:    signedBy,
+				RefSignedBy: refSignedBy,
+				ResolvedAt:  time.Now(),
+			})
 		}
 
+		if err := ctx.Err(); err != nil {
+			return abortRun("install", proj, err)
+		}
 		if err := cfgMgr.SaveConfig(cfg); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
+			return abortRun("install", proj, fmt.Errorf("failed to save config: %w", err))
 		}
 
 		if err := cfgMgr.SaveLockfile(lock); err != nil {
-			return fmt.Errorf("failed to save lockfile: %w", err)
+			return abortRun("install", proj, fmt.Errorf("failed to save lockfile: %w", err))
+		}
+		if err := proj.Commit(); err != nil {
+			fmt.Printf("Warning: failed to remove replaced projections: %v\n", err)
+		}
+		if err := cache.RegisterWorkspace(cwd); err != nil {
+			fmt.Printf("Warning: failed to register workspace with the cache: %v\n", err)
 		}
 
 		fmt.Println("✨ Installation complete and persisted.")
@@ -208,5 +219,7 @@ var installCmd = &cobra.Command{
 func init() {
 	installCmd.Flags().StringVarP(&targetPath, "target", "t", "", "Projection target path")
 	installCmd.Flags().StringVarP(&projName, "name", "n", "default", "Projection name")
+	installCmd.Flags().BoolVar(&acceptRefChange, "accept-ref-change", false, "Re-lock versions whose ref now points to another commit")
+	installCmd.Flags().StringVar(&sourceType, "source-type", "", "Source type (git, local, http, oci); detected from the argument when empty")
 	rootCmd.AddCommand(installCmd)
 }
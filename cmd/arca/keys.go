@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/signing"
+	"github.com/spf13/cobra"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the keys used to sign published versions",
+}
+
+var keysGenerateCmd = &cobra.Command{
+	Use:   "generate [name]",
+	Short: "Generate an ed25519 signing key",
+	Long: `Generates an ed25519 key pair in the keys directory next to the user config.
+Without a name the key is called "default" and is used by 'arca publish --sign'.
+Add the printed public key to the trust.keys of a source to require it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := signing.DefaultKeyName
+		if len(args) > 0 {
+			name = args[0]
+		}
+		dir, err := config.KeysDir()
+		if err != nil {
+			return err
+		}
+		info, err := signing.Generate(dir, name)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(info, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		fmt.Printf("🔑 Generated %s (%s)\n", info.Path, info.Fingerprint)
+		fmt.Println("   Trust it in .arca-assets.yaml with:")
+		fmt.Printf("   %s\n", info.PublicKey)
+		return nil
+	},
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List signing keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := config.KeysDir()
+		if err != nil {
+			return err
+		}
+		keys, err := signing.ListKeys(dir)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(keys, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		if len(keys) == 0 {
+			fmt.Println("No signing keys found; run 'arca keys generate'")
+			return nil
+		}
+		fmt.Printf("🔑 Signing keys in %s:\n", dir)
+		fmt.Println(strings.Repeat("-", 60))
+		for _, k := range keys {
+			fmt.Printf("%s  %s\n", k.Name, k.Fingerprint)
+			fmt.Printf("   %s\n", k.PublicKey)
+		}
+		return nil
+	},
+}
+
+func init() {
+	keysCmd.AddCommand(keysGenerateCmd)
+	keysCmd.AddCommand(keysListCmd)
+	rootCmd.AddCommand(keysCmd)
+}
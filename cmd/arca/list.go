@@ -23,8 +23,16 @@ var listCmd = &cobra.Command{
 			return err
 		}
 
+		lock, _ := cfgMgr.LoadLockfile()
+		lockedMap := make(map[string]models.LockedAsset)
+		if lock != nil {
+			for _, la := range lock.Assets {
+				lockedMap[la.Source+":"+la.ID] = la
+			}
+		}
+
 		if jsonOutput {
-			data, _ := json.MarshalIndent(cfg.Assets, "", "  ")
+			data, _ := json.MarshalIndent(listedAssets(cfg.Assets, lockedMap), "", "  ")
 			fmt.Println(string(data))
 			return nil
 		}
@@ -34,14 +42,6 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
-		lock, _ := cfgMgr.LoadLockfile()
-		lockedMap := make(map[string]models.LockedAsset)
-		if lock != nil {
-			for _, la := range lock.Assets {
-				lockedMap[la.Source+":"+la.ID] = la
-			}
-		}
-
 		fmt.Printf("📦 Installed Assets (%d):\n", len(cfg.Assets))
 		fmt.Println(strings.Repeat("-", 60))
 
@@ -57,6 +57,9 @@ var listCmd = &cobra.Command{
 
 			fmt.Printf("%s %s (%s) from %s\n", status, asset.ID, asset.Kind, asset.Source)
 			fmt.Printf("   Version: %s\n", versionInfo)
+			if ok && locked.License != "" {
+				fmt.Printf("   License: %s\n", locked.License)
+			}
 			for name, path := range asset.Projections {
 				fmt.Printf("   🔗 %s -> %s\n", name, path)
 			}
@@ -67,6 +70,26 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// listedAsset is an installed asset in the JSON output of list, with the
+// version and license it is locked at.
+type listedAsset struct {
+	models.AssetEntry
+	LockedVersion string `json:",omitempty"`
+	License       string `json:",omitempty"`
+}
+
+func listedAssets(assets []models.AssetEntry, locked map[string]models.LockedAsset) []listedAsset {
+	listed := make([]listedAsset, 0, len(assets))
+	for _, asset := range assets {
+		entry := listedAsset{AssetEntry: asset}
+		if la, ok := locked[asset.Source+":"+asset.ID]; ok {
+			entry.LockedVersion, entry.License = la.Version, la.License
+		}
+		listed = append(listed, entry)
+	}
+	return listed
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 }
@@ -7,8 +7,8 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/adryledo/arca-cli/internal/config"
 	"github.com/adryledo/arca-cli/internal/models"
-	"github.com/adryledo/arca-cli/internal/resolver"
 	"github.com/spf13/cobra"
 )
 
@@ -19,11 +19,22 @@ var listRemoteCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sourceStr := args[0]
 		cwd, _ := os.Getwd()
-		res := resolver.New(cwd)
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.NewManager(cwd).LoadConfig()
+		if err != nil {
+			return err
+		}
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
 
-		stype := models.SourceGit
-		if info, err := os.Stat(sourceStr); err == nil && info.IsDir() {
-			stype = models.SourceLocal
+		stype, err := sourceTypeFor(sourceStr, sourceType)
+		if err != nil {
+			return err
 		}
 
 		sourceCfg := models.SourceConfig{
@@ -32,7 +43,7 @@ var listRemoteCmd = &cobra.Command{
 			Path: sourceStr,
 		}
 
-		manifest, err := res.LoadManifest(sourceCfg, "main")
+		manifest, err := res.LoadManifest(cmd.Context(), sourceCfg, "")
 		if err != nil {
 			return err
 		}
@@ -49,6 +60,9 @@ var listRemoteCmd = &cobra.Command{
 		for id, asset := range manifest.Assets {
 			fmt.Printf("📦 %s (%s)\n", id, asset.Kind)
 			fmt.Printf("   📝 %s\n", asset.Description)
+			if asset.License != "" {
+				fmt.Printf("   ⚖️  License: %s\n", asset.License)
+			}
 			fmt.Print("   📌 Versions: ")
 			versions := []string{}
 			for v := range asset.Versions {
@@ -64,5 +78,6 @@ var listRemoteCmd = &cobra.Command{
 }
 
 func init() {
+	listRemoteCmd.Flags().StringVar(&sourceType, "source-type", "", "Source type (git, local, http, oci); detected from the argument when empty")
 	rootCmd.AddCommand(listRemoteCmd)
 }
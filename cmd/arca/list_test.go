@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+func TestListedAssets_JSON(t *testing.T) {
+	assets := []models.AssetEntry{
+		{ID: "rules", Kind: models.KindInstruction, Source: "team", Version: "^1.0.0"},
+		{ID: "tester", Kind: models.KindSkill, Source: "team", Version: "2.0.0"},
+	}
+	locked := map[string]models.LockedAsset{
+		"team:rules": {ID: "rules", Source: "team", Version: "1.2.0", License: "MIT"},
+	}
+
+	data, err := json.Marshal(listedAssets(assets, locked))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 assets, got %d", len(got))
+	}
+	if got[0]["ID"] != "rules" || got[0]["LockedVersion"] != "1.2.0" || got[0]["License"] != "MIT" {
+		t.Errorf("Expected the locked version and license of rules, got %v", got[0])
+	}
+	if _, ok := got[1]["License"]; ok {
+		t.Errorf("Expected no license for an unlocked asset, got %v", got[1])
+	}
+}
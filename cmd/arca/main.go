@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -12,6 +15,9 @@ var rootCmd = &cobra.Command{
 	Short: "ARCA - Asset Resolution for AI Assistants",
 	Long: `ARCA is a high-performance CLI for managing versioned agentic assets 
 (skills, instructions) from Git-based or local manifests.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return installGitTransport()
+	},
 }
 
 var (
@@ -19,8 +25,12 @@ var (
 )
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the command's context so that it can roll back cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		stop()
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/mirror"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var mirrorBare bool
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror [url|path] [dest]",
+	Short: "Replicate a source into a local directory or a bare git repository",
+	Long: `Copies a source for use where the original cannot be reached, such as
+air-gapped networks. The mirror serves the same manifest, every version's
+content at its pinned ref, and the source's branches and tags, so consumers
+can point the source at it without changing their lockfile.
+
+A destination ending in .git (or --bare) becomes a bare git repository with
+the upstream commits; any other destination becomes a directory that is used
+as a local source. Running the command again updates the mirror.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sourceStr, dest := args[0], args[1]
+		cwd, _ := os.Getwd()
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.NewManager(cwd).LoadConfig()
+		if err != nil {
+			return err
+		}
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
+
+		stype, err := sourceTypeFor(sourceStr, sourceType)
+		if err != nil {
+			return err
+		}
+		sourceCfg := models.SourceConfig{Type: stype, URL: sourceStr, Path: sourceStr}
+		src, err := res.Source(sourceCfg)
+		if err != nil {
+			return err
+		}
+
+		dest, err = filepath.Abs(dest)
+		if err != nil {
+			return err
+		}
+		var result *mirror.Result
+		if mirrorBare || strings.HasSuffix(dest, ".git") {
+			if stype != models.SourceGit {
+				return fmt.Errorf("bare git mirrors need a git source, got %s; mirror into a directory instead", stype)
+			}
+			result, err = mirror.BareGit(cmd.Context(), src, config.RewriteURL(sourceStr, res.Rewrites), dest)
+		} else {
+			result, err = mirror.Directory(cmd.Context(), src, sourceStr, dest)
+		}
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		fmt.Printf("🪞 Mirrored %d versions of %d assets from %s to %s\n", result.Versions, result.Assets, sourceStr, dest)
+		if len(result.Refs) > 0 {
+			fmt.Printf("   🏷️  Refs: %s\n", strings.Join(result.Refs, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	mirrorCmd.Flags().StringVar(&sourceType, "source-type", "", "Source type (git, local, http, oci); detected from the argument when empty")
+	mirrorCmd.Flags().BoolVar(&mirrorBare, "bare", false, "Write a bare git repository even if dest does not end in .git")
+	rootCmd.AddCommand(mirrorCmd)
+}
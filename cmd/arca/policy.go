@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the organization policy of the project",
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check configured sources and locked assets against .arca-policy.yaml",
+	Long: `Checks every source in .arca-assets.yaml and every asset version in the
+lockfile against the project policy and the policies it extends. Exits with
+an error when anything violates the policy, for use in CI.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := os.Getwd()
+		cfgMgr := config.NewManager(cwd)
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		cfg, err := cfgMgr.LoadConfig()
+		if err != nil {
+			return err
+		}
+		lock, err := cfgMgr.LoadLockfile()
+		if err != nil {
+			return err
+		}
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
+
+		pol, err := policy.Load(cmd.Context(), cwd, res.Source)
+		if err != nil {
+			return err
+		}
+		if len(pol) == 0 {
+			fmt.Printf("No %s found; nothing to check.\n", policy.FileName)
+			return nil
+		}
+
+		violations := []policy.Violation{}
+		aliases := make([]string, 0, len(cfg.Sources))
+		for alias := range cfg.Sources {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+		for _, alias := range aliases {
+			violations = append(violations, pol.CheckSource(alias, cfg.Sources[alias], res.Rewrites)...)
+		}
+		for _, la := range lock.Assets {
+			violations = append(violations, pol.CheckAsset(policy.Asset{ID: la.ID, Version: la.Version, Source: la.Source, SignedBy: la.SignedBy})...)
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(violations, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			for _, v := range violations {
+				fmt.Printf("❌ [%s] %s\n", v.Rule, v.Message)
+			}
+		}
+
+		if len(violations) > 0 {
+			return fmt.Errorf("policy check failed with %d violation(s)", len(violations))
+		}
+		if !jsonOutput {
+			fmt.Printf("✨ %d source(s) and %d locked asset(s) comply with the policy.\n", len(cfg.Sources), len(lock.Assets))
+		}
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyCheckCmd)
+	rootCmd.AddCommand(policyCmd)
+}
@@ -1,18 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/fsutil"
 	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/oci"
+	"github.com/adryledo/arca-cli/internal/scan"
+	"github.com/adryledo/arca-cli/internal/signing"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	publishOCI  string
+	publishSign bool
+	publishKey  string
+)
+
 var publishCmd = &cobra.Command{
 	Use:   "publish [id] [version] [kind] [file-path]",
 	Short: "Add or update an asset version in the local arca-manifest.yaml",
@@ -53,9 +69,23 @@ var publishCmd = &cobra.Command{
 			return fmt.Errorf("invalid asset kind: %s. Use 'skill', or 'instruction'", kindStr)
 		}
 
+		// Refuse to publish content the scanner blocks
+		pubCfg, err := config.NewManager(cwd).LoadConfig()
+		if err != nil {
+			return err
+		}
+		scanner, err := scan.New(pubCfg.Scan, cwd)
+		if err != nil {
+			return err
+		}
+		if err := scanAsset(scanner, assetID, version, assetFile, kind == models.KindSkill); err != nil {
+			return err
+		}
+
+		fm := readFrontmatter(assetFile, kind)
 		desc := "Added via arca publish"
-		if extDesc := extractDescription(assetFile, kind); extDesc != "" {
-			desc = extDesc
+		if fm.Description != "" {
+			desc = fm.Description
 		}
 
 		asset, ok := manifest.Assets[assetID]
@@ -71,6 +101,10 @@ var publishCmd = &cobra.Command{
 			// Let's allow updating the kind.
 			asset.Kind = kind
 		}
+		// The frontmatter of the published content decides the license
+		if fm.License != "" {
+			asset.License = fm.License
+		}
 
 		// 4. Checkpointing: Pin the previous version to the current HEAD
 		var highestV *semver.Version
@@ -89,12 +123,7 @@ var publishCmd = &cobra.Command{
 		if highestVStr != "" {
 			prevMeta := asset.Versions[highestVStr]
 			if prevMeta.Ref == "" {
-				// Try to get current commit
-				gitCmd := exec.Command("git", "rev-parse", "HEAD")
-				gitCmd.Dir = cwd
-				out, err := gitCmd.Output()
-				if err == nil {
-					commit := strings.TrimSpace(string(out))
+				if commit, err := gitOutput(cwd, "rev-parse", "HEAD"); err == nil {
 					prevMeta.Ref = commit
 					asset.Versions[highestVStr] = prevMeta
 				}
@@ -106,8 +135,29 @@ var publishCmd = &cobra.Command{
 			asset.Versions = make(map[string]models.ManifestVersion)
 		}
 
+		// Record the content digest so consumers can detect content that
+		// differs from what was published, e.g. behind a force-pushed tag
+		sha, err := hashAsset(assetFile, kind == models.KindSkill)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", assetFile, err)
+		}
+		// Pin the version to the commit holding that content; unpinned, it
+		// would follow the default branch and fail the digest check as soon
+		// as the asset is edited there
+		ref, pinned := committedHead(cwd, assetFile)
+		if !pinned {
+			fmt.Printf("⚠️  %s is not committed; %s@%s follows the default branch until it is. Commit it and publish again to pin it\n", assetFile, assetID, version)
+		}
 		asset.Versions[version] = models.ManifestVersion{
-			Path: assetFile,
+			Ref:    ref,
+			Path:   assetFile,
+			SHA256: sha,
+		}
+		var signer string
+		if publishSign {
+			if signer, err = signVersions(&asset, assetID, version); err != nil {
+				return err
+			}
 		}
 		if manifest.Assets == nil {
 			manifest.Assets = make(map[string]models.ManifestAsset)
@@ -120,15 +170,193 @@ var publishCmd = &cobra.Command{
 			return err
 		}
 
-		if err := os.WriteFile(manifestPath, newData, 0644); err != nil {
+		if err := fsutil.WriteFileAtomic(manifestPath, newData, 0644); err != nil {
 			return err
 		}
 
 		fmt.Printf("🚀 Published %s@%s to arca-manifest.yaml\n", assetID, version)
+		if publishSign {
+			fmt.Printf("   🔏 Signed by %s\n", signer)
+		}
+
+		if publishOCI != "" {
+			return publishToOCI(cmd.Context(), publishOCI, &manifest, assetID, version, assetFile)
+		}
 		return nil
 	},
 }
 
+// gitOutput runs git in dir and returns its trimmed output.
+func gitOutput(dir string, args ...string) (string, error) {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = dir
+	out, err := gitCmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// committedHead returns the HEAD commit of the repository at dir when path
+// is tracked and has no uncommitted changes, that is when HEAD serves the
+// content being published.
+func committedHead(dir, path string) (string, bool) {
+	if _, err := gitOutput(dir, "ls-files", "--error-unmatch", "--", path); err != nil {
+		return "", false
+	}
+	if status, err := gitOutput(dir, "status", "--porcelain", "--", path); err != nil || status != "" {
+		return "", false
+	}
+	commit, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", false
+	}
+	return commit, true
+}
+
+// signVersions signs a version of asset with the key from --key,
+// ARCA_SIGNING_KEY or the default key of 'arca keys generate', and returns
+// the key fingerprint. Signatures also cover the asset's license and
+// dependencies, which every version shares, so the other versions this key
+// signed are signed again.
+func signVersions(asset *models.ManifestAsset, assetID, version string) (string, error) {
+	keyPath := publishKey
+	if keyPath == "" {
+		keyPath = os.Getenv(signing.KeyEnv)
+	}
+	if keyPath == "" {
+		dir, err := config.KeysDir()
+		if err != nil {
+			return "", err
+		}
+		keyPath = filepath.Join(dir, signing.DefaultKeyName)
+	}
+	signer, err := signing.LoadSigner(keyPath)
+	if err != nil {
+		return "", err
+	}
+	fingerprint := ssh.FingerprintSHA256(signer.PublicKey())
+
+	for v, meta := range asset.Versions {
+		signedByKey := slices.ContainsFunc(meta.Signatures, func(s models.Signature) bool { return s.Key == fingerprint })
+		if v != version && (!signedByKey || meta.SHA256 == "") {
+			continue
+		}
+		sig, err := signing.Sign(signer, signing.Payload(signing.NewEntry(assetID, v, *asset)))
+		if err != nil {
+			return "", err
+		}
+		meta.Signatures = signing.AddSignature(meta.Signatures, sig)
+		asset.Versions[v] = meta
+	}
+	return fingerprint, nil
+}
+
+// publishToOCI pushes an asset version to an OCI registry and updates the
+// index artifact there. The registry's manifest is the previously published
+// one with this version added; its ref is the layer digest.
+func publishToOCI(ctx context.Context, rawRef string, local *models.Manifest, assetID, version, assetFile string) error {
+	ref, err := oci.ParseReference(rawRef)
+	if err != nil {
+		return err
+	}
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	client := oci.NewClient(ref)
+	if client.HTTP, err = newHTTPClient(user); err != nil {
+		return err
+	}
+	asset := local.Assets[assetID]
+
+	title := filepath.Base(assetFile)
+	var content []byte
+	if asset.Kind == models.KindSkill {
+		content, err = oci.TarGzDir(assetFile)
+		title += ".tar.gz"
+	} else {
+		content, err = os.ReadFile(assetFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", assetFile, err)
+	}
+
+	layer, err := client.PushAsset(ctx, assetID, version, asset.Kind, title, content)
+	if err != nil {
+		return err
+	}
+
+	// Start from what the registry already serves
+	published := models.Manifest{Schema: local.Schema, Assets: make(map[string]models.ManifestAsset)}
+	layers := map[string]oci.Descriptor{layer.Digest: layer}
+	idx, data, _, err := client.FetchIndex(ctx, oci.IndexTag)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &published); err != nil {
+			return fmt.Errorf("failed to parse published manifest: %w", err)
+		}
+		for _, l := range idx.Layers[1:] {
+			layers[l.Digest] = l
+		}
+	case !errors.Is(err, oci.ErrNotFound):
+		return err
+	}
+	if published.Assets == nil {
+		published.Assets = make(map[string]models.ManifestAsset)
+	}
+
+	pubAsset := published.Assets[assetID]
+	pubAsset.Kind = asset.Kind
+	pubAsset.Description = asset.Description
+	pubAsset.License = asset.License
+	pubAsset.Dependencies = asset.Dependencies
+	if pubAsset.Versions == nil {
+		pubAsset.Versions = make(map[string]models.ManifestVersion)
+	}
+	// Signatures cover the license and dependencies: take those of versions
+	// re-signed locally
+	for v, pub := range pubAsset.Versions {
+		if lm, ok := asset.Versions[v]; ok && lm.SHA256 == pub.SHA256 {
+			pub.Signatures = lm.Signatures
+			pubAsset.Versions[v] = pub
+		}
+	}
+	meta := asset.Versions[version]
+	meta.Ref = layer.Digest
+	pubAsset.Versions[version] = meta
+	published.Assets[assetID] = pubAsset
+
+	// Reference every version's layer from the index so the registry keeps it
+	var refs []string
+	for _, a := range published.Assets {
+		for _, v := range a.Versions {
+			if _, ok := layers[v.Ref]; ok {
+				refs = append(refs, v.Ref)
+			}
+		}
+	}
+	sort.Strings(refs)
+	refs = slices.Compact(refs)
+	assetLayers := make([]oci.Descriptor, 0, len(refs))
+	for _, r := range refs {
+		assetLayers = append(assetLayers, layers[r])
+	}
+
+	manifestData, err := yaml.Marshal(published)
+	if err != nil {
+		return err
+	}
+	digest, err := client.PushIndex(ctx, manifestData, assetLayers)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📤 Pushed %s@%s to %s (%s)\n", assetID, version, ref, layer.Digest)
+	fmt.Printf("   📋 Index %s:%s is now %s\n", ref, oci.IndexTag, digest)
+	return nil
+}
+
 func init() {
+	publishCmd.Flags().StringVar(&publishOCI, "oci", "", "Also push the version to an OCI registry (oci://host/repository)")
+	publishCmd.Flags().BoolVar(&publishSign, "sign", false, "Sign the version and its content sha256")
+	publishCmd.Flags().StringVar(&publishKey, "key", "", "Private key to sign with (ed25519 or SSH key; default $ARCA_SIGNING_KEY or the 'default' key)")
 	rootCmd.AddCommand(publishCmd)
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/hasher"
+	"github.com/adryledo/arca-cli/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPublish_RecordsDigestAndRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := gitOutput(dir, append([]string{"-c", "user.name=Test User", "-c", "user.email=test@example.com"}, args...)...)
+		if err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+		return out
+	}
+	git("init")
+	if err := os.WriteFile(filepath.Join(dir, "rules.md"), []byte("# Rules\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "rules.md")
+	git("commit", "-m", "Add rules")
+	head := git("rev-parse", "HEAD")
+
+	publish := func(version string) models.ManifestVersion {
+		t.Helper()
+		if err := publishCmd.RunE(publishCmd, []string{"rules", version, "instruction", "rules.md"}); err != nil {
+			t.Fatalf("publish failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "arca-manifest.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var manifest models.Manifest
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			t.Fatal(err)
+		}
+		return manifest.Assets["rules"].Versions[version]
+	}
+
+	// Committed content is pinned to the commit that holds it
+	meta := publish("1.0.0")
+	want, _ := hasher.HashFile(filepath.Join(dir, "rules.md"))
+	if meta.SHA256 != want {
+		t.Errorf("Expected sha256 %s, got %q", want, meta.SHA256)
+	}
+	if meta.Ref != head {
+		t.Errorf("Expected the version to be pinned to %s, got %q", head, meta.Ref)
+	}
+
+	// Uncommitted content has no commit to pin to
+	if err := os.WriteFile(filepath.Join(dir, "rules.md"), []byte("# Rules\nEdited.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	meta = publish("1.1.0")
+	want, _ = hasher.HashFile(filepath.Join(dir, "rules.md"))
+	if meta.SHA256 != want {
+		t.Errorf("Expected sha256 %s, got %q", want, meta.SHA256)
+	}
+	if meta.Ref != "" {
+		t.Errorf("Expected uncommitted content not to be pinned, got %q", meta.Ref)
+	}
+	if meta.Path != "rules.md" {
+		t.Errorf("Expected path rules.md, got %q", meta.Path)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/downloader"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/resolver"
+	"github.com/adryledo/arca-cli/internal/sbom"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sbomFormat string
+	sbomOutput string
+)
+
+// buildInventory describes every locked asset version. Kinds, descriptions
+// and dependencies come from the manifests at the locked commits; licenses
+// come from the lockfile, or the frontmatter of the cached content for
+// versions locked before manifests declared them.
+func buildInventory(ctx context.Context, name string, cfg *models.Config, lock *models.Lockfile, res *resolver.Resolver, cache *downloader.CacheProvider) sbom.Inventory {
+	direct := make(map[string]bool)
+	for _, asset := range cfg.Assets {
+		direct[asset.Source+":"+asset.ID] = true
+	}
+	refs := make(map[string]string)
+	for _, la := range lock.Assets {
+		refs[la.Source+":"+la.ID] = la.Source + "/" + la.ID + "@" + la.Version
+	}
+
+	manifests := make(map[string]*models.Manifest)
+	inv := sbom.Inventory{Name: name}
+	for _, la := range lock.Assets {
+		comp := sbom.Component{
+			ID:      la.ID,
+			Version: la.Version,
+			Source:  la.Source,
+			URL:     la.URL,
+			Commit:  la.Commit,
+			SHA256:  la.SHA256,
+			License: la.License,
+			Direct:  direct[la.Source+":"+la.ID],
+		}
+
+		src, ok := cfg.Sources[la.Source]
+		if ok {
+			comp.SourceType = string(src.Type)
+			if comp.URL == "" && src.Type != models.SourceLocal {
+				comp.URL = src.URL
+			}
+			key := la.Source + "@" + la.Commit
+			manifest, loaded := manifests[key]
+			if !loaded {
+				var err error
+				if manifest, err = res.LoadManifest(ctx, src, la.Commit); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Failed to load manifest for %s at %s: %v\n", la.Source, la.Commit, err)
+				}
+				manifests[key] = manifest
+			}
+			if manifest != nil {
+				if ma, ok := manifest.Assets[la.ID]; ok {
+					comp.Kind = string(ma.Kind)
+					comp.Description = ma.Description
+					for dep := range ma.Dependencies {
+						if ref, ok := refs[la.Source+":"+dep]; ok {
+							comp.DependsOn = append(comp.DependsOn, ref)
+						}
+					}
+				}
+			}
+		}
+
+		if path, ok := cache.Object(la.SHA256); ok {
+			kind := models.AssetKind(comp.Kind)
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				kind = models.KindSkill
+			}
+			fm := readFrontmatter(path, kind)
+			if comp.License == "" {
+				comp.License = fm.License
+			}
+			if comp.Description == "" {
+				comp.Description = fm.Description
+			}
+		}
+
+		inv.Components = append(inv.Components, comp)
+	}
+	return inv
+}
+
+var sbomCmd = &cobra.Command{
+	Use:   "sbom",
+	Short: "Generate a software bill of materials for the locked assets",
+	Long: `Generates a CycloneDX or SPDX JSON document describing every asset version in
+.arca-assets.lock: source URLs, commits, SHA-256 digests, licenses and the
+dependencies between assets. Licenses come from the lockfile, falling back to
+the frontmatter of the cached content, so run arca sync first for a complete
+document.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sbomFormat != sbom.FormatCycloneDX && sbomFormat != sbom.FormatSPDX {
+			return fmt.Errorf("unsupported sbom format %q (use %s or %s)", sbomFormat, sbom.FormatCycloneDX, sbom.FormatSPDX)
+		}
+		cwd, _ := os.Getwd()
+		cfgMgr := config.NewManager(cwd)
+		cfg, err := cfgMgr.LoadConfig()
+		if err != nil {
+			return err
+		}
+		lock, err := cfgMgr.LoadLockfile()
+		if err != nil {
+			return err
+		}
+		if len(lock.Assets) == 0 {
+			return fmt.Errorf("no locked assets; run arca sync first")
+		}
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
+
+		inv := buildInventory(cmd.Context(), filepath.Base(cfgMgr.WorkspaceRoot), cfg, lock, res, cache)
+		inv.Created = time.Now()
+		data, err := sbom.Render(inv, sbomFormat)
+		if err != nil {
+			return err
+		}
+
+		if sbomOutput == "" {
+			fmt.Println(string(data))
+			return nil
+		}
+		if err := os.WriteFile(sbomOutput, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write sbom: %w", err)
+		}
+		fmt.Printf("✨ Wrote %s SBOM of %d asset(s) to %s\n", sbomFormat, len(inv.Components), sbomOutput)
+		return nil
+	},
+}
+
+func init() {
+	sbomCmd.Flags().StringVar(&sbomFormat, "format", sbom.FormatCycloneDX, "Output format: cyclonedx or spdx")
+	sbomCmd.Flags().StringVarP(&sbomOutput, "output", "o", "", "Write the document to a file instead of stdout")
+	rootCmd.AddCommand(sbomCmd)
+}
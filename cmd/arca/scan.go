@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var scanFailOn string
+
+// scanAsset scans an asset before it is projected or published, prints what
+// it finds and fails when a finding reaches the blocking threshold.
+func scanAsset(scanner *scan.Scanner, id, version, path string, isDir bool) error {
+	findings, err := scanner.ScanPath(path, isDir)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", id, err)
+	}
+	for _, f := range findings {
+		if !isDir {
+			f.File = id // cached files are named by their hash
+		}
+		fmt.Printf("   ⚠️  %s\n", f)
+	}
+	if blocking := scanner.Blocking(findings); len(blocking) > 0 {
+		return fmt.Errorf("%s@%s blocked by %d scan finding(s) at or above %s severity", id, version, len(blocking), scanner.FailOn)
+	}
+	return nil
+}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [path...]",
+	Short: "Scan assets for prompt injection, hidden Unicode and secrets",
+	Long: `Scans files or directories with the built-in rules and the rule files listed
+under scan.rules in .arca-assets.yaml. Without arguments, the projected
+assets of the project are scanned. Exits with an error when a finding reaches
+the blocking severity (scan.failOn, or --fail-on).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := os.Getwd()
+		cfg, err := config.NewManager(cwd).LoadConfig()
+		if err != nil {
+			return err
+		}
+		scanner, err := scan.New(cfg.Scan, cwd)
+		if err != nil {
+			return err
+		}
+		if scanFailOn != "" {
+			if scanner.FailOn, err = scan.ParseSeverity(scanFailOn); err != nil {
+				return err
+			}
+		}
+
+		targets := args
+		if len(targets) == 0 {
+			seen := make(map[string]bool)
+			for _, asset := range cfg.Assets {
+				for _, target := range asset.Projections {
+					if _, err := os.Stat(filepath.Join(cwd, target)); err == nil && !seen[target] {
+						seen[target] = true
+						targets = append(targets, target)
+					}
+				}
+			}
+			sort.Strings(targets)
+		}
+
+		findings := []scan.Finding{}
+		for _, target := range targets {
+			info, err := os.Stat(target)
+			if err != nil {
+				return err
+			}
+			found, err := scanner.ScanPath(target, info.IsDir())
+			if err != nil {
+				return err
+			}
+			for _, f := range found {
+				if info.IsDir() {
+					f.File = filepath.ToSlash(filepath.Join(target, f.File))
+				} else {
+					f.File = filepath.ToSlash(target)
+				}
+				findings = append(findings, f)
+			}
+		}
+
+		blocking := scanner.Blocking(findings)
+		if jsonOutput {
+			data, _ := json.MarshalIndent(findings, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			for _, f := range findings {
+				icon := "⚠️ "
+				if f.Severity.AtLeast(scanner.FailOn) {
+					icon = "❌"
+				}
+				fmt.Printf("%s %s\n", icon, f)
+			}
+		}
+
+		if len(blocking) > 0 {
+			return fmt.Errorf("scan found %d finding(s) at or above %s severity", len(blocking), scanner.FailOn)
+		}
+		if !jsonOutput {
+			fmt.Printf("✨ Scanned %d path(s): %d finding(s), none blocking.\n", len(targets), len(findings))
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanCmd.Flags().StringVar(&scanFailOn, "fail-on", "", "Lowest blocking severity: low, medium, high, critical or off (default from scan.failOn, else high)")
+	rootCmd.AddCommand(scanCmd)
+}
@@ -1,38 +1,395 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
+	"github.com/adryledo/arca-cli/internal/advisory"
 	"github.com/adryledo/arca-cli/internal/config"
 	"github.com/adryledo/arca-cli/internal/downloader"
 	"github.com/adryledo/arca-cli/internal/hasher"
+	"github.com/adryledo/arca-cli/internal/license"
 	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/policy"
 	"github.com/adryledo/arca-cli/internal/projector"
 	"github.com/adryledo/arca-cli/internal/resolver"
+	"github.com/adryledo/arca-cli/internal/scan"
+	"github.com/adryledo/arca-cli/internal/signing"
+	"github.com/adryledo/arca-cli/internal/source"
 	"github.com/spf13/cobra"
 )
 
+var (
+	checkVendor     bool
+	acceptRefChange bool
+)
+
+// syncItem is a resolved asset (top-level or dependency) that must be
+// present in the cache and projected into the workspace.
+type syncItem struct {
+	ID           string
+	Source       models.SourceConfig
+	SourceAlias  string
+	Version      string
+	Meta         models.ManifestVersion
+	Kind         models.AssetKind
+	License      string
+	Dependencies map[string]string
+	Projections  map[string]string
+	// Commit, when set, is the only commit content may be fetched from; it
+	// pins locked versions and items whose git signature was verified.
+	Commit string
+}
+
+// signedEntry returns the part of the item's manifest entry that signatures
+// cover.
+func (item syncItem) signedEntry() signing.Entry {
+	return signing.Entry{
+		Asset:        item.ID,
+		Version:      item.Version,
+		Kind:         item.Kind,
+		License:      item.License,
+		Dependencies: item.Dependencies,
+		SHA256:       item.Meta.SHA256,
+	}
+}
+
+// defaultProjection returns the projection path used for dependencies.
+func defaultProjection(sourceAlias, id string, kind models.AssetKind) string {
+	ext := ".md"
+	if kind == models.KindSkill {
+		ext = ""
+	}
+	return fmt.Sprintf(".arca/assets/%s/%s%s", sourceAlias, id, ext)
+}
+
+// collectSyncItems resolves the full dependency graph of every configured asset.
+// Assets for which skip returns true are left out. Errors are reported and the
+// offending asset is skipped, matching the best-effort behaviour of sync; only
+// cancellation of ctx stops the walk.
+func collectSyncItems(ctx context.Context, cfg *models.Config, lock *models.Lockfile, res *resolver.Resolver, skip func(models.AssetEntry) bool) (map[string]syncItem, error) {
+	toSync := make(map[string]syncItem)
+
+	for _, asset := range cfg.Assets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if skip != nil && skip(asset) {
+			continue
+		}
+		source, ok := cfg.Sources[asset.Source]
+		if !ok {
+			fmt.Printf("⚠️  Source %s not found for asset %s, skipping.\n", asset.Source, asset.ID)
+			continue
+		}
+
+		// Determine manifest revision (pin to locked commit if available)
+		manifestRef := ""
+		if lock != nil {
+			for _, la := range lock.Assets {
+				if la.ID == asset.ID && la.Source == asset.Source {
+					manifestRef = la.Commit
+					break
+				}
+			}
+		}
+
+		manifest, err := res.LoadManifest(ctx, source, manifestRef)
+		if err != nil && ctx.Err() == nil {
+			manifest, err = res.LoadManifest(ctx, source, "")
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			fmt.Printf("❌ Failed to load manifest for %s: %v\n", asset.Source, err)
+			continue
+		}
+
+		// Resolve full graph for this top-level asset
+		graph, err := res.ResolveGraph(manifest, asset.ID, asset.Version)
+		if err != nil {
+			fmt.Printf("❌ Failed to resolve graph for %s: %v\n", asset.ID, err)
+			continue
+		}
+
+		for id, item := range graph {
+			key := asset.Source + ":" + id
+			projections := make(map[string]string)
+			if id == asset.ID {
+				projections = asset.Projections
+			} else {
+				projections["default"] = defaultProjection(asset.Source, id, item.Kind)
+			}
+
+			toSync[key] = syncItem{
+				ID:           id,
+				Source:       source,
+				SourceAlias:  asset.Source,
+				Version:      item.Version,
+				Meta:         item.Meta,
+				Kind:         item.Kind,
+				License:      item.License,
+				Projections:  projections,
+				Dependencies: item.Dependencies,
+			}
+		}
+	}
+
+	return toSync, nil
+}
+
+// sortedSyncKeys returns the keys of toSync in a stable order.
+func sortedSyncKeys(toSync map[string]syncItem) []string {
+	keys := make([]string, 0, len(toSync))
+	for k := range toSync {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fetchedAsset is an asset stored in the content-addressed cache.
+type fetchedAsset struct {
+	Path   string
+	Commit string
+	SHA256 string
+}
+
+// cachedSyncItem returns the cached object for an item that is already locked
+// at the same version, so that sync does not download it again. Local sources
+// are mutable and are always re-read, and so is content that no longer matches
+// the sha256 the manifest declares.
+func cachedSyncItem(item syncItem, lock *models.Lockfile, cache *downloader.CacheProvider) (fetchedAsset, bool) {
+	if item.Source.Type == models.SourceLocal || lock == nil {
+		return fetchedAsset{}, false
+	}
+	locked, ok := findLocked(lock, item.SourceAlias, item.ID)
+	if !ok || locked.Version != item.Version {
+		return fetchedAsset{}, false
+	}
+	if item.Meta.SHA256 != "" && item.Meta.SHA256 != locked.SHA256 {
+		return fetchedAsset{}, false
+	}
+	objPath, ok := cache.Object(locked.SHA256)
+	if !ok {
+		return fetchedAsset{}, false
+	}
+	return fetchedAsset{Path: objPath, Commit: locked.Commit, SHA256: locked.SHA256}, true
+}
+
+// fetchSyncItem downloads an asset from its source into a cache staging area
+// and stores it in the content-addressed object store.
+func fetchSyncItem(ctx context.Context, item syncItem, src source.Source, cache *downloader.CacheProvider) (fetchedAsset, error) {
+	isDir := item.Kind == models.KindSkill
+	entryLock, err := cache.LockEntry(ctx, item.SourceAlias, item.ID, item.Version)
+	if err != nil {
+		return fetchedAsset{}, err
+	}
+	defer entryLock.Unlock()
+
+	staging, err := cache.NewStaging()
+	if err != nil {
+		return fetchedAsset{}, err
+	}
+	defer staging.Close()
+
+	stagedPath := filepath.Join(staging.Dir, item.ID)
+	if !isDir {
+		stagedPath += ".md"
+	}
+
+	var commitSHA string
+	if isDir {
+		commitSHA, err = src.FetchDirectory(ctx, item.Meta.Path, item.Meta.Ref, stagedPath)
+		if err != nil {
+			return fetchedAsset{}, fmt.Errorf("failed to fetch %s: %w", item.ID, err)
+		}
+	} else {
+		var data []byte
+		data, commitSHA, err = src.FetchFile(ctx, item.Meta.Path, item.Meta.Ref)
+		if err != nil {
+			return fetchedAsset{}, fmt.Errorf("failed to fetch %s: %w", item.ID, err)
+		}
+		if err := os.WriteFile(stagedPath, data, 0644); err != nil {
+			return fetchedAsset{}, err
+		}
+	}
+
+	if item.Commit != "" && commitSHA != item.Commit {
+		return fetchedAsset{}, &refMovedError{Item: item, Got: commitSHA}
+	}
+	if err := verifyDeclaredHash(item, stagedPath); err != nil {
+		return fetchedAsset{}, err
+	}
+
+	objPath, hash, err := cache.Store(item.SourceAlias, item.ID, item.Version, stagedPath, isDir, commitSHA)
+	if err != nil {
+		return fetchedAsset{}, err
+	}
+	return fetchedAsset{Path: objPath, Commit: commitSHA, SHA256: hash}, nil
+}
+
+// fetchItem fetches an item through the provider for its source.
+func fetchItem(ctx context.Context, res *resolver.Resolver, item syncItem, cache *downloader.CacheProvider) (fetchedAsset, error) {
+	src, err := res.Source(item.Source)
+	if err != nil {
+		return fetchedAsset{}, err
+	}
+	return fetchSyncItem(ctx, item, src, cache)
+}
+
+// refMovedError reports a version whose ref now resolves to another commit
+// than the one it is pinned to, e.g. a tag moved upstream.
+type refMovedError struct {
+	Item syncItem
+	Got  string
+}
+
+func (e *refMovedError) Error() string {
+	return fmt.Sprintf("ref moved: %s@%s is locked at %s but ref %q now resolves to %s; review the upstream change and re-run with --accept-ref-change to re-lock it", e.Item.ID, e.Item.Version, e.Item.Commit, e.Item.Meta.Ref, e.Got)
+}
+
+// pinLockedCommit pins an item that is already locked at the same version to
+// the locked commit, so that a ref moved upstream is reported instead of
+// silently re-locked. Only explicit refs of git and oci sources are pinned:
+// versions without a ref follow the default branch by design.
+func pinLockedCommit(item *syncItem, lock *models.Lockfile) error {
+	if lock == nil || item.Meta.Ref == "" {
+		return nil
+	}
+	if item.Source.Type != models.SourceGit && item.Source.Type != models.SourceOCI {
+		return nil
+	}
+	locked, ok := findLocked(lock, item.SourceAlias, item.ID)
+	if !ok || locked.Version != item.Version || locked.Commit == "" {
+		return nil
+	}
+	if item.Commit != "" && item.Commit != locked.Commit {
+		moved := *item
+		moved.Commit = locked.Commit
+		return &refMovedError{Item: moved, Got: item.Commit}
+	}
+	item.Commit = locked.Commit
+	return nil
+}
+
+// verifyItemRef enforces requireSignedRefs on the source of an item: the git
+// commit or tag the item is fetched from must be signed by a trusted key
+// before anything is fetched. The item is pinned to the verified commit and
+// the signer is returned. A cached item whose lock entry already records a
+// signer is not checked again.
+func verifyItemRef(ctx context.Context, res *resolver.Resolver, item *syncItem, lock *models.Lockfile, cached bool, root string) (string, error) {
+	policy := item.Source.Trust
+	if policy == nil || !policy.RequireSignedRefs {
+		return "", nil
+	}
+	if cached {
+		if locked, ok := findLocked(lock, item.SourceAlias, item.ID); ok && locked.RefSignedBy != "" {
+			return locked.RefSignedBy, nil
+		}
+	}
+
+	keys, err := signing.LoadKeyring(policy, root)
+	if err != nil {
+		return "", err
+	}
+	if keys.Empty() {
+		return "", fmt.Errorf("source %s requires signed refs but trusts no keys", item.SourceAlias)
+	}
+	src, err := res.Source(item.Source)
+	if err != nil {
+		return "", err
+	}
+	commit, signer, err := source.VerifyRef(ctx, src, item.Meta.Ref, keys)
+	if err != nil {
+		return "", fmt.Errorf("refusing %s@%s: %w", item.ID, item.Version, err)
+	}
+	item.Commit = commit
+	return signer, nil
+}
+
+// verifyDeclaredHash checks downloaded content against the sha256 the
+// manifest declares for the version, if any.
+func verifyDeclaredHash(item syncItem, path string) error {
+	if item.Meta.SHA256 == "" {
+		return nil
+	}
+	actual, err := hashAsset(path, item.Kind == models.KindSkill)
+	if err != nil {
+		return err
+	}
+	if actual != item.Meta.SHA256 {
+		return fmt.Errorf("%s@%s does not match the manifest sha256 (expected %s, got %s)", item.ID, item.Version, item.Meta.SHA256, actual)
+	}
+	return nil
+}
+
+// hashAsset computes the LF-normalized SHA-256 of a cached or vendored asset.
+func hashAsset(path string, isDir bool) (string, error) {
+	if isDir {
+		return hasher.HashDir(path)
+	}
+	return hasher.HashFile(path)
+}
+
+// upsertLocked replaces the lock entry for the same asset and source, or appends it.
+func upsertLocked(lock *models.Lockfile, locked models.LockedAsset) {
+	for i, la := range lock.Assets {
+		if la.ID == locked.ID && la.Source == locked.Source {
+			lock.Assets[i] = locked
+			return
+		}
+	}
+	lock.Assets = append(lock.Assets, locked)
+}
+
+// abortRun puts back the projections a failed or cancelled command replaced,
+// so that the workspace matches the lockfile it leaves untouched.
+func abortRun(command string, proj *projector.Projector, err error) error {
+	if rerr := proj.Rollback(); rerr != nil {
+		fmt.Printf("Warning: failed to restore projections: %v\n", rerr)
+	}
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%s cancelled, workspace restored: %w", command, err)
+	}
+	return err
+}
+
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync all assets defined in .arca-assets.yaml",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cwd, _ := os.Getwd()
 		cfgMgr := config.NewManager(cwd)
-		res := resolver.New(cwd)
+		wsLock, err := cfgMgr.Lock()
+		if err != nil {
+			return err
+		}
+		defer wsLock.Unlock()
 		proj := projector.New(cwd)
-		cache := downloader.NewCacheProvider("")
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
 
 		// 1. Load config and lockfile
 		cfg, err := cfgMgr.LoadConfig()
 		if err != nil {
 			return err
 		}
-		if len(cfg.Assets) == 0 {
-			fmt.Println("No assets defined in .arca-assets.yaml")
-			return nil
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
+		scanner, err := scan.New(cfg.Scan, cwd)
+		if err != nil {
+			return err
 		}
 
 		lock, err := cfgMgr.LoadLockfile()
@@ -40,162 +397,157 @@ var syncCmd = &cobra.Command{
 			return err
 		}
 
-		// Use a map to collect all assets to sync (avoids duplicates)
-		type syncItem struct {
-			ID          string
-			Source      models.SourceConfig
-			SourceAlias string
-			Version     string
-			Meta        models.ManifestVersion
-			Kind        models.AssetKind
-			Projections map[string]string
+		vendorIdx, err := cfgMgr.LoadVendorIndex()
+		if err != nil {
+			return err
 		}
-		toSync := make(map[string]syncItem)
 
-		for _, asset := range cfg.Assets {
-			source, ok := cfg.Sources[asset.Source]
-			if !ok {
-				fmt.Printf("⚠️  Source %s not found for asset %s, skipping.\n", asset.Source, asset.ID)
-				continue
-			}
+		ctx := cmd.Context()
+		if checkVendor {
+			return verifyVendor(cfgMgr, lock, vendorIdx)
+		}
 
-			// Determine manifest revision (pin to locked commit if available)
-			manifestRef := "main"
-			if lock != nil {
-				for _, la := range lock.Assets {
-					if la.ID == asset.ID && la.Source == asset.Source {
-						manifestRef = la.Commit
-						break
-					}
-				}
+		if len(cfg.Assets) == 0 {
+			fmt.Println("No assets defined in .arca-assets.yaml")
+			return nil
+		}
+
+		// 2. Apply the organization policy to every source
+		pol, err := policy.Load(ctx, cwd, res.Source)
+		if err != nil {
+			return abortRun("sync", proj, err)
+		}
+		blocked := make(map[string]bool)
+		for alias, src := range cfg.Sources {
+			if err := policy.Err(pol.CheckSource(alias, src, res.Rewrites)); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				blocked[alias] = true
 			}
+		}
 
-			manifest, err := res.LoadManifest(source, manifestRef)
-			if err != nil {
-				manifest, err = res.LoadManifest(source, "main")
-				if err != nil {
-					fmt.Printf("❌ Failed to load manifest for %s: %v\n", asset.Source, err)
-					continue
-				}
+		// 3. Project vendored assets straight from the in-repo tree
+		vendorIdx = usableVendored(cfg, lock, vendorIdx)
+		if vendorIdx != nil {
+			if err := projectVendored(ctx, cfg, cfgMgr, proj, lock, vendorIdx, vendorChecks{Policy: pol, Blocked: blocked, Scanner: scanner}); err != nil {
+				return abortRun("sync", proj, err)
 			}
+		}
+		isVendored := func(asset models.AssetEntry) bool {
+			if vendorIdx == nil {
+				return false
+			}
+			_, ok := vendorIdx.Find(asset.Source, asset.ID)
+			return ok
+		}
+
+		// 4. Resolve and fetch everything else
+		skip := func(asset models.AssetEntry) bool {
+			return blocked[asset.Source] || isVendored(asset)
+		}
+
+		toSync, err := collectSyncItems(ctx, cfg, lock, res, skip)
+		if err != nil {
+			return abortRun("sync", proj, err)
+		}
 
-			// Resolve full graph for this top-level asset
-			graph, err := res.ResolveGraph(manifest, asset.ID, asset.Version)
+		for _, key := range sortedSyncKeys(toSync) {
+			item := toSync[key]
+			isDir := item.Kind == models.KindSkill
+
+			signedBy, err := signing.Verify(item.Source.Trust, item.signedEntry(), item.Meta.Signatures)
 			if err != nil {
-				fmt.Printf("❌ Failed to resolve graph for %s: %v\n", asset.ID, err)
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			if err := policy.Err(pol.CheckAsset(policy.Asset{ID: item.ID, Version: item.Version, Source: item.SourceAlias, SignedBy: signedBy})); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+			if err := license.Check(cfg.Licenses, item.License); err != nil {
+				fmt.Printf("❌ %s@%s: %v\n", item.ID, item.Version, err)
 				continue
 			}
 
-			for id, item := range graph {
-				key := asset.Source + ":" + id
-				projections := make(map[string]string)
-				if id == asset.ID {
-					projections = asset.Projections
-				} else {
-					ext := ".md"
-					if item.Kind == models.KindSkill {
-						ext = ""
-					}
-					projections["default"] = fmt.Sprintf(".arca/assets/%s/%s%s", asset.Source, id, ext)
-				}
-
-				toSync[key] = syncItem{
-					ID:          id,
-					Source:      source,
-					SourceAlias: asset.Source,
-					Version:     item.Version,
-					Meta:        item.Meta,
-					Kind:        item.Kind,
-					Projections: projections,
+			fetched, ok := cachedSyncItem(item, lock, cache)
+			refSignedBy, err := verifyItemRef(ctx, res, &item, lock, ok, cwd)
+			if err != nil {
+				if ctx.Err() != nil {
+					return abortRun("sync", proj, ctx.Err())
 				}
+				fmt.Printf("❌ %v\n", err)
+				continue
 			}
-		}
-
-		for _, item := range toSync {
-			version := item.Version
-			isDir := item.Kind == models.KindSkill
-			commitSHA := ""
-			assetPath := cache.GetAssetPath(item.SourceAlias, item.ID, version, isDir)
-			cacheDir, _ := cache.EnsureDir(item.SourceAlias, item.ID, version)
-
-			if item.Source.Type == models.SourceLocal {
-				absPath := filepath.Join(item.Source.Path, item.Meta.Path)
-				if isDir {
-					commitSHA = "local"
-				} else {
-					data, err := os.ReadFile(absPath)
-					if err != nil {
-						fmt.Printf("❌ Failed to read %s: %v\n", item.ID, err)
+			if ok && item.Commit != "" && fetched.Commit != item.Commit {
+				ok = false
+			}
+			if !ok {
+				if !acceptRefChange {
+					if err := pinLockedCommit(&item, lock); err != nil {
+						fmt.Printf("❌ %v\n", err)
 						continue
 					}
-					_ = os.WriteFile(assetPath, data, 0644)
-					commitSHA = "local"
-				}
-			} else {
-				gitDownloader := downloader.NewGitDownloader()
-				ref := item.Meta.Ref
-				if ref == "" {
-					ref = "main"
 				}
-				if isDir {
-					sha, err := gitDownloader.FetchDirectory(item.Source.URL, item.Meta.Path, ref, cacheDir)
-					if err != nil {
-						fmt.Printf("❌ Failed to fetch %s: %v\n", item.ID, err)
-						continue
-					}
-					commitSHA = sha
-				} else {
-					data, sha, err := gitDownloader.FetchFile(item.Source.URL, item.Meta.Path, ref)
-					if err != nil {
-						fmt.Printf("❌ Failed to fetch %s: %v\n", item.ID, err)
-						continue
+				fetched, err = fetchItem(ctx, res, item, cache)
+				if err != nil {
+					if ctx.Err() != nil {
+						return abortRun("sync", proj, ctx.Err())
 					}
-					_ = os.WriteFile(assetPath, []byte(data), 0644)
-					commitSHA = sha
+					fmt.Printf("❌ %v\n", err)
+					continue
 				}
 			}
 
+			if err := scanAsset(scanner, item.ID, item.Version, fetched.Path, isDir); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				continue
+			}
+
 			// Project to all defined locations
 			for _, target := range item.Projections {
-				_, err = proj.Project(assetPath, target, isDir)
+				_, err = proj.Project(ctx, fetched.Path, target, isDir)
 				if err != nil {
+					if ctx.Err() != nil {
+						return abortRun("sync", proj, ctx.Err())
+					}
 					fmt.Printf("❌ Failed to project %s to %s: %v\n", item.ID, target, err)
 				}
 			}
 
 			// Update Lockfile Entry
-			var contentHash string
-			if isDir {
-				contentHash, _ = hasher.HashDir(assetPath)
-			} else {
-				contentHash, _ = hasher.HashFile(assetPath)
-			}
-			locked := models.LockedAsset{
-				ID:         item.ID,
-				Version:    version,
-				Source:     item.SourceAlias,
-				Commit:     commitSHA,
-				SHA256:     contentHash,
-				ResolvedAt: time.Now(),
-			}
-
-			found := false
-			for i, la := range lock.Assets {
-				if la.ID == item.ID && la.Source == item.SourceAlias {
-					lock.Assets[i] = locked
-					found = true
-					break
-				}
-			}
-			if !found {
-				lock.Assets = append(lock.Assets, locked)
-			}
+			upsertLocked(lock, models.LockedAsset{
+				ID:          item.ID,
+				Version:     item.Version,
+				Source:      item.SourceAlias,
+				URL:         item.Source.URL,
+				Commit:      fetched.Commit,
+				SHA256:      fetched.SHA256,
+				License:     item.License,
+				SignedBy:    signedBy,
+				RefSignedBy: refSignedBy,
+				ResolvedAt:  time.Now(),
+			})
 
-			fmt.Printf("✅ Synced %s@%s\n", item.ID, version)
+			fmt.Printf("✅ Synced %s@%s\n", item.ID, item.Version)
 		}
 
+		if err := ctx.Err(); err != nil {
+			return abortRun("sync", proj, err)
+		}
+		if len(cfg.Advisories) > 0 {
+			advisories, err := advisory.Load(ctx, cfg, cwd, res.Source)
+			if err != nil {
+				fmt.Printf("Warning: failed to check advisories: %v\n", err)
+			}
+			warnAdvisories(advisory.Check(cfg, advisories, lock.Assets))
+		}
 		if err := cfgMgr.SaveLockfile(lock); err != nil {
-			return err
+			return abortRun("sync", proj, err)
+		}
+		if err := proj.Commit(); err != nil {
+			fmt.Printf("Warning: failed to remove replaced projections: %v\n", err)
+		}
+		if err := cache.RegisterWorkspace(cwd); err != nil {
+			fmt.Printf("Warning: failed to register workspace with the cache: %v\n", err)
 		}
 
 		fmt.Println("✨ Sync complete.")
@@ -204,5 +556,7 @@ var syncCmd = &cobra.Command{
 }
 
 func init() {
+	syncCmd.Flags().BoolVar(&acceptRefChange, "accept-ref-change", false, "Re-lock versions whose ref now points to another commit")
+	syncCmd.Flags().BoolVar(&checkVendor, "check-vendor", false, "Verify that .arca/vendor matches the lockfile without syncing")
 	rootCmd.AddCommand(syncCmd)
 }
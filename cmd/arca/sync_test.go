@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/downloader"
+	"github.com/adryledo/arca-cli/internal/hasher"
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+// fakeSource serves fixed content for every path.
+type fakeSource struct {
+	content string
+	commit  string
+}
+
+func (f *fakeSource) FetchManifest(ctx context.Context, ref string) ([]byte, error) { return nil, nil }
+func (f *fakeSource) ListRefs(ctx context.Context) ([]string, error)                { return nil, nil }
+func (f *fakeSource) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	return f.commit, nil
+}
+
+func (f *fakeSource) FetchFile(ctx context.Context, path, ref string) ([]byte, string, error) {
+	return []byte(f.content), f.commit, nil
+}
+
+func (f *fakeSource) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", err
+	}
+	return f.commit, os.WriteFile(filepath.Join(destDir, "SKILL.md"), []byte(f.content), 0644)
+}
+
+func TestFetchSyncItem(t *testing.T) {
+	src := &fakeSource{content: "rules", commit: "abc123"}
+	want := hasher.HashString("rules")
+
+	tests := []struct {
+		name     string
+		declared string
+		wantErr  bool
+	}{
+		{"no declared hash", "", false},
+		{"matching hash", want, false},
+		{"mismatching hash", strings.Repeat("0", 64), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := downloader.NewCacheProvider(t.TempDir())
+			item := syncItem{
+				ID:          "rules",
+				SourceAlias: "fake",
+				Version:     "1.0.0",
+				Meta:        models.ManifestVersion{Path: "rules.md", SHA256: tt.declared},
+				Kind:        models.KindInstruction,
+			}
+
+			fetched, err := fetchSyncItem(t.Context(), item, src, cache)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected hash mismatch error")
+				}
+				if _, _, ok := cache.Lookup("fake", "rules", "1.0.0"); ok {
+					t.Error("Expected mismatching content not to be cached")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchSyncItem failed: %v", err)
+			}
+			if fetched.SHA256 != want || fetched.Commit != "abc123" {
+				t.Errorf("Expected %s@abc123, got %s@%s", want, fetched.SHA256, fetched.Commit)
+			}
+		})
+	}
+}
+
+func TestVerifyDeclaredHash(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rules.md")
+	os.WriteFile(file, []byte("rules"), 0644)
+	skill := filepath.Join(dir, "skill")
+	os.MkdirAll(skill, 0755)
+	os.WriteFile(filepath.Join(skill, "SKILL.md"), []byte("skill"), 0644)
+	fileHash, _ := hasher.HashFile(file)
+	dirHash, _ := hasher.HashDir(skill)
+
+	tests := []struct {
+		name     string
+		kind     models.AssetKind
+		path     string
+		declared string
+		wantErr  bool
+	}{
+		{"file without digest", models.KindInstruction, file, "", false},
+		{"file matching", models.KindInstruction, file, fileHash, false},
+		{"file edited after publish", models.KindInstruction, file, hasher.HashString("published"), true},
+		{"skill matching", models.KindSkill, skill, dirHash, false},
+		{"skill edited after publish", models.KindSkill, skill, fileHash, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := syncItem{ID: "rules", Version: "1.0.0", Kind: tt.kind, Meta: models.ManifestVersion{SHA256: tt.declared}}
+			err := verifyDeclaredHash(item, tt.path)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Expected the content to match, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "does not match the manifest sha256") {
+				t.Errorf("Expected a sha256 mismatch error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFetchSyncItem_PinnedCommit(t *testing.T) {
+	src := &fakeSource{content: "rules", commit: "def456"}
+	cache := downloader.NewCacheProvider(t.TempDir())
+	item := syncItem{
+		ID:          "rules",
+		SourceAlias: "fake",
+		Version:     "1.0.0",
+		Meta:        models.ManifestVersion{Path: "rules.md"},
+		Kind:        models.KindInstruction,
+		Commit:      "abc123",
+	}
+
+	if _, err := fetchSyncItem(t.Context(), item, src, cache); err == nil {
+		t.Fatal("Expected an error for content from another commit than the verified one")
+	}
+	if _, _, ok := cache.Lookup("fake", "rules", "1.0.0"); ok {
+		t.Error("Expected content from an unverified commit not to be cached")
+	}
+
+	item.Commit = "def456"
+	if _, err := fetchSyncItem(t.Context(), item, src, cache); err != nil {
+		t.Errorf("fetchSyncItem failed: %v", err)
+	}
+}
+
+func TestPinLockedCommit(t *testing.T) {
+	lock := &models.Lockfile{Assets: []models.LockedAsset{
+		{ID: "rules", Source: "org", Version: "1.0.0", Commit: "abc123"},
+	}}
+	gitSource := models.SourceConfig{Type: models.SourceGit, URL: "https://example.com/assets.git"}
+
+	tests := []struct {
+		name       string
+		source     models.SourceConfig
+		version    string
+		ref        string
+		verified   string
+		wantCommit string
+		wantMoved  bool
+	}{
+		{"locked tag is pinned", gitSource, "1.0.0", "v1.0.0", "", "abc123", false},
+		{"verified commit matches lock", gitSource, "1.0.0", "v1.0.0", "abc123", "abc123", false},
+		{"verified commit moved", gitSource, "1.0.0", "v1.0.0", "def456", "", true},
+		{"new version is not pinned", gitSource, "1.1.0", "v1.1.0", "", "", false},
+		{"default branch is not pinned", gitSource, "1.0.0", "", "", "", false},
+		{"local source is not pinned", models.SourceConfig{Type: models.SourceLocal}, "1.0.0", "v1.0.0", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := syncItem{
+				ID:          "rules",
+				Source:      tt.source,
+				SourceAlias: "org",
+				Version:     tt.version,
+				Meta:        models.ManifestVersion{Path: "rules.md", Ref: tt.ref},
+				Commit:      tt.verified,
+			}
+			err := pinLockedCommit(&item, lock)
+			var moved *refMovedError
+			if tt.wantMoved {
+				if !errors.As(err, &moved) {
+					t.Fatalf("Expected a ref moved error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pinLockedCommit failed: %v", err)
+			}
+			if item.Commit != tt.wantCommit {
+				t.Errorf("Expected commit %q, got %q", tt.wantCommit, item.Commit)
+			}
+		})
+	}
+}
+
+func TestSourceTypeFor(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		source   string
+		explicit string
+		want     models.SourceType
+		wantErr  bool
+	}{
+		{dir, "", models.SourceLocal, false},
+		{"https://github.com/org/assets", "", models.SourceGit, false},
+		{"https://files.example.com/assets", "http", models.SourceHTTP, false},
+		{"https://files.example.com/assets", "ftp", "", true},
+	}
+	for _, tt := range tests {
+		got, err := sourceTypeFor(tt.source, tt.explicit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sourceTypeFor(%s, %s) error = %v", tt.source, tt.explicit, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, got)
+		}
+	}
+}
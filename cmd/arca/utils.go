@@ -1,19 +1,40 @@
 package main
 
 import (
+	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/downloader"
 	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/netutil"
+	"github.com/adryledo/arca-cli/internal/resolver"
+	"github.com/adryledo/arca-cli/internal/source"
 	"gopkg.in/yaml.v3"
 )
 
+// assetFrontmatter holds the frontmatter fields ARCA reads from assets.
+type assetFrontmatter struct {
+	Description string `yaml:"description"`
+	License     string `yaml:"license"`
+}
+
 func extractDescription(filePath string, kind models.AssetKind) string {
+	return readFrontmatter(filePath, kind).Description
+}
+
+// readFrontmatter reads the YAML frontmatter of an instruction file, or of
+// the SKILL.md of a skill directory. Missing files and frontmatter yield
+// empty fields.
+func readFrontmatter(filePath string, kind models.AssetKind) assetFrontmatter {
 	if kind == models.KindSkill {
 		entries, err := os.ReadDir(filePath)
 		if err != nil {
-			return ""
+			return assetFrontmatter{}
 		}
 		found := false
 		for _, entry := range entries {
@@ -24,24 +45,101 @@ func extractDescription(filePath string, kind models.AssetKind) string {
 			}
 		}
 		if !found {
-			return ""
+			return assetFrontmatter{}
 		}
 	}
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return ""
+		return assetFrontmatter{}
 	}
 	content := string(data)
 	if strings.HasPrefix(content, "---\n") || strings.HasPrefix(content, "---\r\n") {
 		parts := strings.SplitN(content, "---", 3)
 		if len(parts) >= 3 {
-			var fm struct {
-				Description string `yaml:"description"`
-			}
+			var fm assetFrontmatter
 			if err := yaml.Unmarshal([]byte(parts[1]), &fm); err == nil {
-				return strings.TrimSpace(fm.Description)
+				fm.Description = strings.TrimSpace(fm.Description)
+				fm.License = strings.TrimSpace(fm.License)
+				return fm
 			}
 		}
 	}
-	return ""
+	return assetFrontmatter{}
+}
+
+// sourceTypeFor picks the source type for an install/list-remote argument:
+// an explicit --source-type wins, oci:// references are OCI sources, existing
+// directories are local sources and everything else is treated as a git URL.
+func sourceTypeFor(sourceStr, explicit string) (models.SourceType, error) {
+	if explicit != "" {
+		stype := models.SourceType(explicit)
+		if !slices.Contains(source.Types(), stype) {
+			return "", fmt.Errorf("unsupported source type: %s", explicit)
+		}
+		return stype, nil
+	}
+	if strings.HasPrefix(sourceStr, "oci://") {
+		return models.SourceOCI, nil
+	}
+	if info, err := os.Stat(sourceStr); err == nil && info.IsDir() {
+		return models.SourceLocal, nil
+	}
+	return models.SourceGit, nil
+}
+
+// newResolver creates a resolver whose source providers keep their caches
+// inside the asset cache and apply the project and user URL rewrite rules.
+// Manifests at locked commits are kept in the cache for offline syncs.
+func newResolver(workspaceRoot string, cache *downloader.CacheProvider, cfg *models.Config) (*resolver.Resolver, error) {
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	res := resolver.New(workspaceRoot)
+	res.Rewrites = config.URLRewrites(cfg, user)
+	if res.Network, err = config.NetworkPolicy(user); err != nil {
+		return nil, err
+	}
+	if res.HTTPClient, err = newHTTPClient(user); err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		res.CacheDir = cache.SourcesDir()
+		res.Manifests = cache
+	}
+	return res, nil
+}
+
+// newHTTPClient builds the client for every HTTP request from the proxy
+// environment and the TLS settings of the user config.
+func newHTTPClient(user *models.UserConfig) (*http.Client, error) {
+	return netutil.NewHTTPClient(config.NetworkTransport(user))
+}
+
+// installGitTransport makes git clones, including those of `arca mirror`,
+// use the same HTTP client settings. go-git keeps its transports in a global
+// registry, so this runs once, before any command.
+func installGitTransport() error {
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	client, err := newHTTPClient(user)
+	if err != nil {
+		return err
+	}
+	source.UseHTTPClient(client)
+	return nil
+}
+
+// newCache builds the cache provider from ARCA_CACHE_DIR, ARCA_CACHE_SEEDS
+// and the user config.
+func newCache() (*downloader.CacheProvider, error) {
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	cache := downloader.NewCacheProvider(config.CacheDir(user))
+	cache.Seeds = config.CacheSeeds(user)
+	return cache, nil
 }
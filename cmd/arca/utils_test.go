@@ -75,3 +75,27 @@ description: A powerful skill
 		}
 	})
 }
+
+func TestReadFrontmatter_License(t *testing.T) {
+	skillDir := filepath.Join(t.TempDir(), "python-refactor")
+	if err := os.MkdirAll(skillDir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	content := `---
+name: python-refactor
+description: Refactors Python code
+license: Apache-2.0
+---
+# Header`
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %v", err)
+	}
+
+	fm := readFrontmatter(skillDir, models.KindSkill)
+	if fm.License != "Apache-2.0" {
+		t.Errorf("Expected 'Apache-2.0', got '%s'", fm.License)
+	}
+	if fm.Description != "Refactors Python code" {
+		t.Errorf("Expected 'Refactors Python code', got '%s'", fm.Description)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/adryledo/arca-cli/internal/advisory"
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/downloader"
+	"github.com/adryledo/arca-cli/internal/fsutil"
+	"github.com/adryledo/arca-cli/internal/license"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/policy"
+	"github.com/adryledo/arca-cli/internal/projector"
+	"github.com/adryledo/arca-cli/internal/scan"
+	"github.com/adryledo/arca-cli/internal/signing"
+	"github.com/spf13/cobra"
+)
+
+var vendorCmd = &cobra.Command{
+	Use:   "vendor",
+	Short: "Copy all locked assets into the in-repo .arca/vendor tree",
+	Long: `Copies every asset recorded in .arca-assets.lock into .arca/vendor so that
+the project can be synced without network access or a shared cache.
+Commit the .arca/vendor directory together with the lockfile.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, _ := os.Getwd()
+		cfgMgr := config.NewManager(cwd)
+		wsLock, err := cfgMgr.Lock()
+		if err != nil {
+			return err
+		}
+		defer wsLock.Unlock()
+		cache, err := newCache()
+		if err != nil {
+			return err
+		}
+
+		cfg, err := cfgMgr.LoadConfig()
+		if err != nil {
+			return err
+		}
+		res, err := newResolver(cwd, cache, cfg)
+		if err != nil {
+			return err
+		}
+		lock, err := cfgMgr.LoadLockfile()
+		if err != nil {
+			return err
+		}
+		if len(lock.Assets) == 0 {
+			return fmt.Errorf("no locked assets found; run 'arca sync' first")
+		}
+
+		ctx := cmd.Context()
+		pol, err := policy.Load(ctx, cwd, res.Source)
+		if err != nil {
+			return err
+		}
+		scanner, err := scan.New(cfg.Scan, cwd)
+		if err != nil {
+			return err
+		}
+		toSync, err := collectSyncItems(ctx, cfg, lock, res, nil)
+		if err != nil {
+			return err
+		}
+
+		// Rebuild the vendor tree from scratch so removed assets do not linger.
+		// It is built next to the current one and swapped in at the end, so a
+		// cancelled run leaves the current tree untouched.
+		vendorDir := cfgMgr.VendorDir()
+		if err := os.MkdirAll(filepath.Dir(vendorDir), 0755); err != nil {
+			return err
+		}
+		buildDir, err := os.MkdirTemp(filepath.Dir(vendorDir), ".vendor-*")
+		if err != nil {
+			return fmt.Errorf("failed to create vendor directory: %w", err)
+		}
+		defer os.RemoveAll(buildDir)
+		vendorCache := downloader.NewCacheProvider(buildDir)
+		idx := &models.VendorIndex{Assets: []models.VendoredAsset{}}
+
+		for _, key := range sortedSyncKeys(toSync) {
+			item := toSync[key]
+			isDir := item.Kind == models.KindSkill
+
+			locked, ok := findLocked(lock, item.SourceAlias, item.ID)
+			if !ok || locked.Version != item.Version {
+				return fmt.Errorf("%s@%s is not locked; run 'arca sync' before vendoring", item.ID, item.Version)
+			}
+
+			if err := policy.Err(pol.CheckSource(item.SourceAlias, item.Source, res.Rewrites)); err != nil {
+				return err
+			}
+			signedBy, err := signing.Verify(item.Source.Trust, item.signedEntry(), item.Meta.Signatures)
+			if err != nil {
+				return err
+			}
+			if err := license.Check(cfg.Licenses, item.License); err != nil {
+				return fmt.Errorf("%s@%s: %w", item.ID, item.Version, err)
+			}
+
+			fetched, ok := cachedSyncItem(item, lock, cache)
+			if !ok {
+				fetched, err = fetchItem(ctx, res, item, cache)
+				if err != nil {
+					return err
+				}
+			}
+			if fetched.SHA256 != locked.SHA256 {
+				return fmt.Errorf("integrity check failed for %s@%s: expected %s, got %s", item.ID, item.Version, locked.SHA256, fetched.SHA256)
+			}
+			if err := vetAsset(pol, scanner, item.SourceAlias, item.ID, item.Version, signedBy, fetched.Path, isDir); err != nil {
+				return err
+			}
+
+			dest := vendorCache.GetAssetPath(item.SourceAlias, item.ID, item.Version, isDir)
+			if err := fsutil.CopyPath(fetched.Path, dest, isDir); err != nil {
+				return fmt.Errorf("failed to vendor %s: %w", item.ID, err)
+			}
+
+			idx.Assets = append(idx.Assets, models.VendoredAsset{
+				ID:      item.ID,
+				Version: item.Version,
+				Source:  item.SourceAlias,
+				Kind:    item.Kind,
+				Commit:  locked.Commit,
+				SHA256:  locked.SHA256,
+			})
+			fmt.Printf("📦 Vendored %s@%s\n", item.ID, item.Version)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("vendor cancelled: %w", err)
+		}
+		if len(cfg.Advisories) > 0 {
+			advisories, err := advisory.Load(ctx, cfg, cwd, res.Source)
+			if err != nil {
+				fmt.Printf("Warning: failed to check advisories: %v\n", err)
+			}
+			warnAdvisories(advisory.Check(cfg, advisories, lock.Assets))
+		}
+		// The index goes into the new tree so that the swap is the only step
+		// that changes what sync sees
+		if err := config.WriteVendorIndex(buildDir, idx); err != nil {
+			return fmt.Errorf("failed to save vendor index: %w", err)
+		}
+		if err := swapDir(buildDir, vendorDir); err != nil {
+			return fmt.Errorf("failed to replace vendor directory: %w", err)
+		}
+
+		fmt.Printf("✨ Vendored %d asset(s) into %s\n", len(idx.Assets), config.VendorDirName)
+		return nil
+	},
+}
+
+// swapDir replaces dest with src. The current dest is moved aside first and
+// restored if src cannot take its place.
+func swapDir(src, dest string) error {
+	old := src + ".old"
+	if err := os.Rename(dest, old); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		return os.Rename(src, dest)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		os.Rename(old, dest)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// findLocked returns the lockfile entry of an asset from a given source.
+func findLocked(lock *models.Lockfile, source, id string) (models.LockedAsset, bool) {
+	for _, la := range lock.Assets {
+		if la.Source == source && la.ID == id {
+			return la, true
+		}
+	}
+	return models.LockedAsset{}, false
+}
+
+// usableVendored returns the vendored assets sync may project. A declared
+// asset whose locked version no longer satisfies its version in
+// .arca-assets.yaml is left out so that sync resolves it again.
+func usableVendored(cfg *models.Config, lock *models.Lockfile, idx *models.VendorIndex) *models.VendorIndex {
+	if idx == nil {
+		return nil
+	}
+	stale := make(map[string]bool)
+	for _, asset := range cfg.Assets {
+		va, ok := idx.Find(asset.Source, asset.ID)
+		if !ok {
+			continue
+		}
+		locked, ok := findLocked(lock, asset.Source, asset.ID)
+		if ok && versionSatisfies(asset.Version, locked.Version) {
+			continue
+		}
+		fmt.Printf("⚠️  Vendored %s@%s does not match %s in %s; resolving it instead. Run 'arca vendor' afterwards.\n", va.ID, va.Version, asset.Version, config.ConfigFileName)
+		stale[asset.Source+":"+asset.ID] = true
+	}
+
+	usable := &models.VendorIndex{Assets: []models.VendoredAsset{}}
+	for _, va := range idx.Assets {
+		if !stale[va.Source+":"+va.ID] {
+			usable.Assets = append(usable.Assets, va)
+		}
+	}
+	return usable
+}
+
+// versionSatisfies reports whether a locked version still satisfies the
+// version an asset declares: a semver constraint, "latest" or an exact version.
+func versionSatisfies(constraint, version string) bool {
+	if constraint == "latest" {
+		return true
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return constraint == version
+	}
+	v, err := semver.NewVersion(version)
+	return err == nil && c.Check(v)
+}
+
+// vendoredProblem describes how a vendored asset differs from its lockfile
+// entry, re-hashing the vendored content. It returns "" when they match.
+func vendoredProblem(vendorCache *downloader.CacheProvider, la models.LockedAsset, va models.VendoredAsset) string {
+	switch {
+	case va.Version != la.Version:
+		return fmt.Sprintf("vendored version %s does not match locked version", va.Version)
+	case va.SHA256 != la.SHA256:
+		return "vendor index hash does not match lockfile"
+	}
+	isDir := va.Kind == models.KindSkill
+	contentHash, err := hashAsset(vendorCache.GetAssetPath(va.Source, va.ID, va.Version, isDir), isDir)
+	if err != nil {
+		return fmt.Sprintf("failed to hash vendored content: %v", err)
+	}
+	if contentHash != la.SHA256 {
+		return fmt.Sprintf("content hash %s does not match lockfile", contentHash)
+	}
+	return ""
+}
+
+// vendorChecks are the checks sync applies to vendored assets, as it does to
+// fetched ones: the organization policy, with the sources it blocks, and the
+// content scanner.
+type vendorChecks struct {
+	Policy  policy.Set
+	Blocked map[string]bool
+	Scanner *scan.Scanner
+}
+
+// vetAsset applies the organization policy and the content scanner to an
+// asset version held at path.
+func vetAsset(pol policy.Set, scanner *scan.Scanner, alias, id, version, signedBy, path string, isDir bool) error {
+	if err := policy.Err(pol.CheckAsset(policy.Asset{ID: id, Version: version, Source: alias, SignedBy: signedBy})); err != nil {
+		return err
+	}
+	return scanAsset(scanner, id, version, path, isDir)
+}
+
+// projectVendored projects every vendored asset from the in-repo vendor tree
+// after checking it against the lockfile. Assets the policy or the scanner
+// refuse are reported and left unprojected.
+func projectVendored(ctx context.Context, cfg *models.Config, cfgMgr *config.Manager, proj *projector.Projector, lock *models.Lockfile, idx *models.VendorIndex, checks vendorChecks) error {
+	vendorCache := downloader.NewCacheProvider(cfgMgr.VendorDir())
+
+	for _, va := range idx.Assets {
+		isDir := va.Kind == models.KindSkill
+		assetPath := vendorCache.GetAssetPath(va.Source, va.ID, va.Version, isDir)
+		locked, ok := findLocked(lock, va.Source, va.ID)
+		if !ok {
+			return fmt.Errorf("vendored asset %s@%s is not locked; run 'arca vendor'", va.ID, va.Version)
+		}
+		if problem := vendoredProblem(vendorCache, locked, va); problem != "" {
+			return fmt.Errorf("vendored asset %s@%s: %s; run 'arca vendor'", va.ID, va.Version, problem)
+		}
+		if checks.Blocked[va.Source] {
+			fmt.Printf("❌ Skipping vendored %s@%s: source %s is blocked by policy\n", va.ID, va.Version, va.Source)
+			continue
+		}
+		if err := vetAsset(checks.Policy, checks.Scanner, va.Source, va.ID, va.Version, locked.SignedBy, assetPath, isDir); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+
+		projections := map[string]string{"default": defaultProjection(va.Source, va.ID, va.Kind)}
+		for _, asset := range cfg.Assets {
+			if asset.Source == va.Source && asset.ID == va.ID {
+				projections = asset.Projections
+				break
+			}
+		}
+
+		for _, target := range projections {
+			if _, err := proj.Project(ctx, assetPath, target, isDir); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fmt.Printf("❌ Failed to project %s to %s: %v\n", va.ID, target, err)
+			}
+		}
+		fmt.Printf("✅ Synced %s@%s (vendored)\n", va.ID, va.Version)
+	}
+	return nil
+}
+
+// vendorCheck is the result of verifying one locked asset against the vendor tree.
+type vendorCheck struct {
+	ID      string `json:"id"`
+	Source  string `json:"source"`
+	Version string `json:"version"`
+	OK      bool   `json:"ok"`
+	Problem string `json:"problem,omitempty"`
+}
+
+// verifyVendor checks that every locked asset is vendored at the locked
+// version and that the vendored content still hashes to the lockfile SHA-256.
+func verifyVendor(cfgMgr *config.Manager, lock *models.Lockfile, idx *models.VendorIndex) error {
+	if idx == nil {
+		return fmt.Errorf("%s not found; run 'arca vendor' first", filepath.ToSlash(filepath.Join(config.VendorDirName, config.VendorIndexFileName)))
+	}
+	vendorCache := downloader.NewCacheProvider(cfgMgr.VendorDir())
+
+	var results []vendorCheck
+	failed := 0
+	for _, la := range lock.Assets {
+		check := vendorCheck{ID: la.ID, Source: la.Source, Version: la.Version, OK: true}
+		va, ok := idx.Find(la.Source, la.ID)
+		if !ok {
+			check.Problem = "not vendored"
+		} else {
+			check.Problem = vendoredProblem(vendorCache, la, va)
+		}
+		if check.Problem != "" {
+			check.OK = false
+			failed++
+		}
+		results = append(results, check)
+	}
+
+	for _, va := range idx.Assets {
+		if _, ok := findLocked(lock, va.Source, va.ID); !ok {
+			results = append(results, vendorCheck{ID: va.ID, Source: va.Source, Version: va.Version, Problem: "vendored but not locked"})
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		for _, r := range results {
+			if r.OK {
+				fmt.Printf("✅ %s@%s\n", r.ID, r.Version)
+				continue
+			}
+			fmt.Printf("❌ %s@%s: %s\n", r.ID, r.Version, r.Problem)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("vendor check failed for %d asset(s)", failed)
+	}
+	if !jsonOutput {
+		fmt.Println("✨ Vendor tree matches the lockfile.")
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(vendorCmd)
+}
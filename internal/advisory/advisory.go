@@ -0,0 +1,137 @@
+// Package advisory loads advisory feeds and matches locked asset versions
+// against them. A feed is a YAML file of advisories hosted in any source or
+// kept locally.
+package advisory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/policy"
+	"github.com/adryledo/arca-cli/internal/scan"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the advisory file read from a source when a feed names none.
+const DefaultPath = "advisories.yaml"
+
+// Match is a locked asset version affected by an advisory.
+type Match struct {
+	Asset    string          `json:"asset"`
+	Version  string          `json:"version"`
+	Source   string          `json:"source"`
+	Advisory models.Advisory `json:"advisory"`
+}
+
+// Load reads the advisories of every feed configured in cfg. Feeds in a
+// source are fetched through open; local feeds are read relative to root.
+func Load(ctx context.Context, cfg *models.Config, root string, open policy.Opener) ([]models.Advisory, error) {
+	var all []models.Advisory
+	for _, feed := range cfg.Advisories {
+		data, name, err := fetch(ctx, cfg, feed, root, open)
+		if err != nil {
+			return nil, err
+		}
+		advisories, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid advisory feed %s: %w", name, err)
+		}
+		all = append(all, advisories...)
+	}
+	return all, nil
+}
+
+func fetch(ctx context.Context, cfg *models.Config, feed models.AdvisoryFeed, root string, open policy.Opener) ([]byte, string, error) {
+	if feed.File != "" {
+		path := feed.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, feed.File, fmt.Errorf("failed to read advisory feed: %w", err)
+		}
+		return data, feed.File, nil
+	}
+
+	srcCfg, ok := cfg.Sources[feed.Source]
+	if !ok {
+		return nil, "", fmt.Errorf("advisory feed source %q is not configured", feed.Source)
+	}
+	path := feed.Path
+	if path == "" {
+		path = DefaultPath
+	}
+	name := feed.Source + ":" + path
+	src, err := open(srcCfg)
+	if err != nil {
+		return nil, name, err
+	}
+	data, _, err := src.FetchFile(ctx, path, feed.Ref)
+	if err != nil {
+		return nil, name, fmt.Errorf("failed to fetch advisory feed %s: %w", name, err)
+	}
+	return data, name, nil
+}
+
+// Parse decodes an advisory file and validates its entries.
+func Parse(data []byte) ([]models.Advisory, error) {
+	var file models.AdvisoryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for _, a := range file.Advisories {
+		if a.ID == "" || a.Asset == "" {
+			return nil, fmt.Errorf("advisory without an id or asset")
+		}
+		if _, err := semver.NewConstraint(a.Versions); err != nil {
+			return nil, fmt.Errorf("advisory %s: invalid versions %q: %w", a.ID, a.Versions, err)
+		}
+		if _, err := scan.ParseSeverity(a.Severity); err != nil || a.Severity == string(scan.SeverityOff) {
+			return nil, fmt.Errorf("advisory %s: invalid severity %q", a.ID, a.Severity)
+		}
+	}
+	return file.Advisories, nil
+}
+
+// Check returns the advisories that affect the locked assets. An advisory
+// with a source only applies to assets locked from a matching URL; entries
+// locked before the lockfile recorded URLs are matched by the URL of their
+// source in cfg. Locked versions that are not semantic versions, like git
+// refs, cannot be compared with an advisory's range: those that an advisory
+// names are returned as unchecked instead.
+func Check(cfg *models.Config, advisories []models.Advisory, locked []models.LockedAsset) (matches []Match, unchecked []models.LockedAsset) {
+	for _, la := range locked {
+		srcURL := la.URL
+		if srcURL == "" && cfg != nil {
+			srcURL = cfg.Sources[la.Source].URL
+		}
+		v, verr := semver.NewVersion(la.Version)
+		named := false
+		for _, a := range advisories {
+			if a.Asset != la.ID {
+				continue
+			}
+			if a.Source != "" && !policy.MatchURL([]string{a.Source}, srcURL) {
+				continue
+			}
+			if verr != nil {
+				named = true
+				continue
+			}
+			c, err := semver.NewConstraint(a.Versions)
+			if err != nil || !c.Check(v) {
+				continue
+			}
+			matches = append(matches, Match{Asset: la.ID, Version: la.Version, Source: la.Source, Advisory: a})
+		}
+		if named {
+			unchecked = append(unchecked, la)
+		}
+	}
+	return matches, unchecked
+}
@@ -0,0 +1,134 @@
+package advisory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/source"
+)
+
+const feed = `schema: "1.0"
+advisories:
+  - id: ARCA-2026-0001
+    asset: rules
+    versions: ">=1.0.0 <1.2.3"
+    severity: high
+    summary: Exfiltrates environment variables
+    remediation: Upgrade to 1.2.3
+  - id: ARCA-2026-0002
+    asset: review
+    source: github.com/my-org
+    versions: "*"
+    severity: low
+    summary: Outdated guidance
+`
+
+func TestCheck(t *testing.T) {
+	advisories, err := Parse([]byte(feed))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		asset models.LockedAsset
+		want  string
+	}{
+		{"affected", models.LockedAsset{ID: "rules", Version: "1.2.0", URL: "https://example.com/a"}, "ARCA-2026-0001"},
+		{"fixed", models.LockedAsset{ID: "rules", Version: "1.2.3", URL: "https://example.com/a"}, ""},
+		{"matching source", models.LockedAsset{ID: "review", Version: "0.1.0", URL: "https://github.com/my-org/assets"}, "ARCA-2026-0002"},
+		{"other source", models.LockedAsset{ID: "review", Version: "0.1.0", URL: "https://github.com/fork/assets"}, ""},
+		{"other asset", models.LockedAsset{ID: "docs", Version: "1.0.0"}, ""},
+		{"source from config", models.LockedAsset{ID: "review", Source: "team", Version: "0.1.0"}, "ARCA-2026-0002"},
+		{"other source from config", models.LockedAsset{ID: "review", Source: "fork", Version: "0.1.0"}, ""},
+	}
+	cfg := &models.Config{Sources: map[string]models.SourceConfig{
+		"team": {Type: models.SourceGit, URL: "https://github.com/my-org/assets"},
+		"fork": {Type: models.SourceGit, URL: "https://github.com/fork/assets"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, unchecked := Check(cfg, advisories, []models.LockedAsset{tt.asset})
+			if len(unchecked) != 0 {
+				t.Errorf("Expected every version to be checked, got %v", unchecked)
+			}
+			if tt.want == "" {
+				if len(matches) != 0 {
+					t.Errorf("Expected no match, got %v", matches)
+				}
+				return
+			}
+			if len(matches) != 1 || matches[0].Advisory.ID != tt.want {
+				t.Errorf("Expected %s, got %v", tt.want, matches)
+			}
+		})
+	}
+}
+
+func TestCheck_Unchecked(t *testing.T) {
+	advisories, err := Parse([]byte(feed))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	locked := []models.LockedAsset{
+		{ID: "rules", Version: "main", URL: "https://example.com/a"},
+		{ID: "docs", Version: "main", URL: "https://example.com/a"},
+		{ID: "review", Version: "main", URL: "https://github.com/fork/assets"},
+	}
+
+	matches, unchecked := Check(nil, advisories, locked)
+	if len(matches) != 0 {
+		t.Errorf("Expected no match, got %v", matches)
+	}
+	// Only versions an advisory names are reported
+	if len(unchecked) != 1 || unchecked[0].ID != "rules" {
+		t.Errorf("Expected rules@main to be unchecked, got %v", unchecked)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"missing asset", "advisories:\n  - id: A\n    versions: '*'\n    severity: high\n"},
+		{"invalid versions", "advisories:\n  - id: A\n    asset: x\n    versions: 'not a range'\n    severity: high\n"},
+		{"invalid severity", "advisories:\n  - id: A\n    asset: x\n    versions: '*'\n    severity: severe\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	shared := t.TempDir()
+	os.WriteFile(filepath.Join(root, "local.yaml"), []byte(feed), 0644)
+	os.WriteFile(filepath.Join(shared, DefaultPath), []byte(feed), 0644)
+
+	cfg := &models.Config{
+		Sources:    map[string]models.SourceConfig{"org": {Type: models.SourceLocal, Path: shared}},
+		Advisories: []models.AdvisoryFeed{{File: "local.yaml"}, {Source: "org"}},
+	}
+	open := func(cfg models.SourceConfig) (source.Source, error) {
+		return source.New(cfg, source.Options{})
+	}
+	advisories, err := Load(t.Context(), cfg, root, open)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(advisories) != 4 {
+		t.Errorf("Expected 4 advisories from both feeds, got %d", len(advisories))
+	}
+
+	cfg.Advisories = []models.AdvisoryFeed{{Source: "missing"}}
+	if _, err := Load(t.Context(), cfg, root, open); err == nil {
+		t.Error("Expected an error for an unknown feed source")
+	}
+}
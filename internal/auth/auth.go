@@ -2,21 +2,26 @@ package auth
 
 import (
 	"os"
+	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
-// GetGitAuth returns authentication credentials for Git operations
-// based on environment variables (ARCA_GIT_TOKEN, GITHUB_TOKEN, AZURE_DEVOPS_EXTTOKEN).
-func GetGitAuth() *http.BasicAuth {
-	token := os.Getenv("ARCA_GIT_TOKEN")
-	if token == "" {
-		token = os.Getenv("GITHUB_TOKEN")
-	}
-	if token == "" {
-		token = os.Getenv("AZURE_DEVOPS_EXTTOKEN")
-	}
+// hostTokenEnvs maps environment variables to the hosts their token may be
+// sent to, so that e.g. a GitHub token never reaches Azure DevOps.
+var hostTokenEnvs = []struct {
+	name  string
+	hosts []string
+}{
+	{"GITHUB_TOKEN", []string{"github.com"}},
+	{"AZURE_DEVOPS_EXTTOKEN", []string{"dev.azure.com", "*.visualstudio.com"}},
+}
 
+// GetGitAuth returns authentication credentials for Git operations against a
+// host based on environment variables. ARCA_GIT_TOKEN applies to every host;
+// GITHUB_TOKEN and AZURE_DEVOPS_EXTTOKEN only to their own provider.
+func GetGitAuth(host string) *http.BasicAuth {
+	_, token := envToken(host)
 	if token != "" {
 		// For most providers (GitHub, GitLab, etc.), username can be "token"
 		// or any non-empty string when using a personal access token as the password.
@@ -27,3 +32,24 @@ func GetGitAuth() *http.BasicAuth {
 	}
 	return nil
 }
+
+// envToken returns the first token environment variable that applies to host.
+func envToken(host string) (string, string) {
+	if token := os.Getenv("ARCA_GIT_TOKEN"); token != "" {
+		return "ARCA_GIT_TOKEN", token
+	}
+	host = strings.ToLower(host)
+	for _, e := range hostTokenEnvs {
+		token := os.Getenv(e.name)
+		if token == "" {
+			continue
+		}
+		for _, pattern := range e.hosts {
+			suffix, wildcard := strings.CutPrefix(pattern, "*.")
+			if host == pattern || (wildcard && strings.HasSuffix(host, "."+suffix)) {
+				return e.name, token
+			}
+		}
+	}
+	return "", ""
+}
@@ -18,6 +18,7 @@ func TestGetGitAuth(t *testing.T) {
 
 	tests := []struct {
 		name          string
+		host          string
 		arcaToken     string
 		githubToken   string
 		azureToken    string
@@ -26,35 +27,60 @@ func TestGetGitAuth(t *testing.T) {
 	}{
 		{
 			name:          "No tokens set",
+			host:          "github.com",
 			expectedFound: false,
 		},
 		{
 			name:          "ARCA_GIT_TOKEN set",
+			host:          "gitlab.com",
 			arcaToken:     "arca-secret",
 			expectedPass:  "arca-secret",
 			expectedFound: true,
 		},
 		{
 			name:          "GITHUB_TOKEN set",
+			host:          "github.com",
 			githubToken:   "github-secret",
 			expectedPass:  "github-secret",
 			expectedFound: true,
 		},
 		{
 			name:          "AZURE_DEVOPS_EXTTOKEN set",
+			host:          "dev.azure.com",
 			azureToken:    "azure-secret",
 			expectedPass:  "azure-secret",
 			expectedFound: true,
 		},
+		{
+			name:          "AZURE_DEVOPS_EXTTOKEN legacy host",
+			host:          "myorg.visualstudio.com",
+			azureToken:    "azure-secret",
+			expectedPass:  "azure-secret",
+			expectedFound: true,
+		},
+		{
+			name:          "GitHub token not sent to Azure",
+			host:          "dev.azure.com",
+			githubToken:   "github-secret",
+			expectedFound: false,
+		},
+		{
+			name:          "Azure token not sent to GitHub",
+			host:          "github.com",
+			azureToken:    "azure-secret",
+			expectedFound: false,
+		},
 		{
 			name:          "Priority ARCA over GitHub",
+			host:          "github.com",
 			arcaToken:     "arca-secret",
 			githubToken:   "github-secret",
 			expectedPass:  "arca-secret",
 			expectedFound: true,
 		},
 		{
-			name:          "Priority GitHub over Azure",
+			name:          "Host decides between GitHub and Azure",
+			host:          "github.com",
 			githubToken:   "github-secret",
 			azureToken:    "azure-secret",
 			expectedPass:  "github-secret",
@@ -68,7 +94,7 @@ func TestGetGitAuth(t *testing.T) {
 			os.Setenv("GITHUB_TOKEN", tt.githubToken)
 			os.Setenv("AZURE_DEVOPS_EXTTOKEN", tt.azureToken)
 
-			auth := GetGitAuth()
+			auth := GetGitAuth(tt.host)
 
 			if !tt.expectedFound {
 				if auth != nil {
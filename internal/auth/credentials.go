@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+// Credential sources reported by LookupCredential.
+const (
+	FromUserConfig = "user config"
+	FromEnv        = "environment"
+	FromNetrc      = "netrc"
+	FromGitHelper  = "git credential helper"
+)
+
+// HTTPCredential is a username/secret pair for an HTTP(S) host together with
+// a description of where it was found.
+type HTTPCredential struct {
+	Host     string
+	Username string
+	Password string
+	Source   string
+}
+
+// gitCredentialFill runs `git credential fill` for a URL. It is a variable so
+// that tests can replace it.
+var gitCredentialFill = func(u *url.URL) (string, string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	input := fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/"))
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(input)
+	// Never block on an interactive prompt
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", err
+	}
+
+	var username, password string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "username":
+			username = value
+		case "password":
+			password = value
+		}
+	}
+	return username, password, nil
+}
+
+// LookupCredential resolves the HTTP credential for a source URL. Sources are
+// consulted in order: a matching entry in the user config, host-scoped
+// environment tokens, .netrc and finally `git credential fill`. It returns
+// nil when no credential applies, so that no secret is sent to the host.
+func LookupCredential(rawURL string) (*HTTPCredential, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, nil
+	}
+	host := u.Hostname()
+
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c := matchCredential(user.Credentials, host); c != nil {
+		token := c.Token
+		if c.TokenEnv != "" {
+			token = os.Getenv(c.TokenEnv)
+		}
+		if token != "" {
+			return &HTTPCredential{Host: host, Username: usernameOr(c.Username), Password: token, Source: FromUserConfig}, nil
+		}
+	}
+
+	if name, token := envToken(host); token != "" {
+		return &HTTPCredential{Host: host, Username: "token", Password: token, Source: FromEnv + " (" + name + ")"}, nil
+	}
+
+	if login, password, ok := netrcLookup(host); ok {
+		return &HTTPCredential{Host: host, Username: usernameOr(login), Password: password, Source: FromNetrc}, nil
+	}
+
+	if u.Scheme == "http" || u.Scheme == "https" {
+		if login, password, err := gitCredentialFill(u); err == nil && password != "" {
+			return &HTTPCredential{Host: host, Username: usernameOr(login), Password: password, Source: FromGitHelper}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// sshKeyFor returns the key file configured for an SSH host in the user config.
+func sshKeyFor(host string) (string, error) {
+	user, err := config.LoadUserConfig()
+	if err != nil {
+		return "", err
+	}
+	if c := matchCredential(user.Credentials, host); c != nil && c.SSHKey != "" {
+		return expandHome(c.SSHKey), nil
+	}
+	return "", nil
+}
+
+// matchCredential returns the most specific credential entry for a host:
+// an exact match wins over a wildcard.
+func matchCredential(creds []models.Credential, host string) *models.Credential {
+	host = strings.ToLower(host)
+	var wildcard *models.Credential
+	for i := range creds {
+		pattern := strings.ToLower(creds[i].Host)
+		if pattern == host {
+			return &creds[i]
+		}
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok && wildcard == nil {
+			if host == suffix || strings.HasSuffix(host, "."+suffix) {
+				wildcard = &creds[i]
+			}
+		}
+	}
+	return wildcard
+}
+
+func usernameOr(name string) string {
+	if name == "" {
+		return "token"
+	}
+	return name
+}
+
+// netrcPath returns $NETRC, or ~/.netrc (~/_netrc on Windows).
+func netrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(home, name)
+}
+
+// netrcLookup finds the login and password for a machine in the netrc file,
+// falling back to a "default" entry.
+func netrcLookup(host string) (string, string, bool) {
+	path := netrcPath()
+	if path == "" {
+		return "", "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+
+	type entry struct{ login, password string }
+	var found, fallback, current *entry
+	fields := strings.Fields(string(data))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			current = nil
+			if i+1 < len(fields) {
+				i++
+				if found == nil && strings.EqualFold(fields[i], host) {
+					found = &entry{}
+					current = found
+				}
+			}
+		case "default":
+			current = nil
+			if fallback == nil {
+				fallback = &entry{}
+				current = fallback
+			}
+		case "login", "password", "account":
+			if i+1 >= len(fields) {
+				continue
+			}
+			key, value := fields[i], fields[i+1]
+			i++
+			if current == nil {
+				continue
+			}
+			switch key {
+			case "login":
+				current.login = value
+			case "password":
+				current.password = value
+			}
+		case "macdef":
+			// Macro definitions run until a blank line, which Fields cannot
+			// see; they are rare in practice, so stop parsing instead.
+			i = len(fields)
+		}
+	}
+
+	if found != nil && found.password != "" {
+		return found.login, found.password, true
+	}
+	if fallback != nil && fallback.password != "" {
+		return fallback.login, fallback.password, true
+	}
+	return "", "", false
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
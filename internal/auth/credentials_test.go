@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/config"
+)
+
+// isolateCredentials points every credential source at test-controlled
+// locations so that the developer's own tokens never leak into tests.
+func isolateCredentials(t *testing.T, userConfig, netrc string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(userConfig), 0644); err != nil {
+		t.Fatalf("failed to write user config: %v", err)
+	}
+	netrcFile := filepath.Join(dir, "netrc")
+	if err := os.WriteFile(netrcFile, []byte(netrc), 0600); err != nil {
+		t.Fatalf("failed to write netrc: %v", err)
+	}
+
+	t.Setenv(config.UserConfigEnv, configPath)
+	t.Setenv("NETRC", netrcFile)
+	t.Setenv("ARCA_GIT_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("AZURE_DEVOPS_EXTTOKEN", "")
+
+	original := gitCredentialFill
+	t.Cleanup(func() { gitCredentialFill = original })
+	gitCredentialFill = func(u *url.URL) (string, string, error) {
+		return "", "", errors.New("no helper")
+	}
+}
+
+func TestLookupCredential(t *testing.T) {
+	userConfig := `
+credentials:
+  - host: dev.azure.com
+    username: my-org
+    tokenEnv: TEST_AZURE_PAT
+  - host: "*.example.com"
+    username: wildcard
+    token: wildcard-secret
+  - host: git.example.com
+    username: exact
+    token: exact-secret
+`
+	netrc := `machine gitlab.com login alice password netrc-secret
+default login anon password default-secret`
+
+	t.Run("User config with token from environment", func(t *testing.T) {
+		isolateCredentials(t, userConfig, netrc)
+		t.Setenv("TEST_AZURE_PAT", "azure-pat")
+
+		cred, err := LookupCredential("https://dev.azure.com/my-org/project/_git/assets")
+		if err != nil || cred == nil {
+			t.Fatalf("Expected credential, got %v (%v)", cred, err)
+		}
+		if cred.Username != "my-org" || cred.Password != "azure-pat" || cred.Source != FromUserConfig {
+			t.Errorf("Unexpected credential: %+v", cred)
+		}
+	})
+
+	t.Run("Exact host wins over wildcard", func(t *testing.T) {
+		isolateCredentials(t, userConfig, netrc)
+
+		cred, _ := LookupCredential("https://git.example.com/assets.git")
+		if cred == nil || cred.Username != "exact" {
+			t.Errorf("Expected exact match, got %+v", cred)
+		}
+		cred, _ = LookupCredential("https://other.example.com/assets.git")
+		if cred == nil || cred.Username != "wildcard" {
+			t.Errorf("Expected wildcard match, got %+v", cred)
+		}
+	})
+
+	t.Run("Host scoped environment token", func(t *testing.T) {
+		isolateCredentials(t, "", "")
+		t.Setenv("GITHUB_TOKEN", "github-secret")
+
+		cred, _ := LookupCredential("https://github.com/org/assets.git")
+		if cred == nil || cred.Password != "github-secret" {
+			t.Errorf("Expected GitHub token, got %+v", cred)
+		}
+		if cred, _ := LookupCredential("https://dev.azure.com/org/_git/assets"); cred != nil {
+			t.Errorf("GitHub token must not be sent to Azure, got %+v", cred)
+		}
+	})
+
+	t.Run("Netrc machine and default", func(t *testing.T) {
+		isolateCredentials(t, "", netrc)
+
+		cred, _ := LookupCredential("https://gitlab.com/org/assets.git")
+		if cred == nil || cred.Username != "alice" || cred.Password != "netrc-secret" || cred.Source != FromNetrc {
+			t.Errorf("Expected netrc machine credential, got %+v", cred)
+		}
+		cred, _ = LookupCredential("https://unknown.host/assets.git")
+		if cred == nil || cred.Password != "default-secret" {
+			t.Errorf("Expected netrc default credential, got %+v", cred)
+		}
+	})
+
+	t.Run("Git credential helper", func(t *testing.T) {
+		isolateCredentials(t, "", "")
+		gitCredentialFill = func(u *url.URL) (string, string, error) {
+			if u.Host != "git.internal" {
+				t.Errorf("Unexpected host %s", u.Host)
+			}
+			return "helper-user", "helper-secret", nil
+		}
+
+		cred, _ := LookupCredential("https://git.internal/assets.git")
+		if cred == nil || cred.Username != "helper-user" || cred.Source != FromGitHelper {
+			t.Errorf("Expected helper credential, got %+v", cred)
+		}
+	})
+
+	t.Run("No credential", func(t *testing.T) {
+		isolateCredentials(t, "", "")
+
+		if cred, _ := LookupCredential("https://github.com/org/assets.git"); cred != nil {
+			t.Errorf("Expected no credential, got %+v", cred)
+		}
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	isolateCredentials(t, "", "")
+	t.Setenv("GITHUB_TOKEN", "github-secret")
+
+	status, err := Describe("https://github.com/org/assets.git")
+	if err != nil {
+		t.Fatalf("Describe failed: %v", err)
+	}
+	if status.Method != "http" || status.From != FromEnv+" (GITHUB_TOKEN)" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	status, _ = Describe("https://dev.azure.com/org/_git/assets")
+	if status.Method != "none" {
+		t.Errorf("Expected no credential for Azure, got %+v", status)
+	}
+
+	t.Setenv(SSHKeyEnv, "/keys/deploy")
+	status, _ = Describe("git@github.com:org/assets.git")
+	if status.Method != "ssh" || status.KeyFile != "/keys/deploy" || status.Username != "git" {
+		t.Errorf("Unexpected SSH status: %+v", status)
+	}
+}
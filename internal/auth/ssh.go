@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+const (
+	// SSHKeyEnv points at a private key file to use instead of ssh-agent.
+	SSHKeyEnv = "ARCA_SSH_KEY"
+	// SSHKeyPassphraseEnv holds the passphrase of an encrypted key file.
+	SSHKeyPassphraseEnv = "ARCA_SSH_KEY_PASSPHRASE"
+	// SSHKnownHostsEnv lists known_hosts files, separated by the OS path list separator.
+	SSHKnownHostsEnv = "ARCA_SSH_KNOWN_HOSTS"
+)
+
+// defaultKeyFiles are tried in order when neither ARCA_SSH_KEY nor an agent is available.
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// promptPassphrase asks for the passphrase of an encrypted key on the terminal.
+// It is a variable so that tests can replace it.
+var promptPassphrase = func(keyPath string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("key %s is encrypted; set %s", keyPath, SSHKeyPassphraseEnv)
+	}
+	fmt.Fprintf(os.Stderr, "Enter passphrase for %s: ", keyPath)
+	pass, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return pass, err
+}
+
+// ForURL picks the authentication method for a source URL from its scheme.
+// SSH URLs (ssh://host/path or scp-like user@host:path) authenticate with an
+// explicit key file (ARCA_SSH_KEY, then sshKey from the user config), ssh-agent
+// or a default key, verified against known_hosts.
+// Everything else uses HTTP basic auth with the credential configured for the
+// host, if any (see LookupCredential).
+func ForURL(rawURL string) (transport.AuthMethod, error) {
+	if IsSSHURL(rawURL) {
+		return sshAuth(rawURL)
+	}
+	cred, err := LookupCredential(rawURL)
+	if err != nil || cred == nil {
+		return nil, err
+	}
+	return &http.BasicAuth{Username: cred.Username, Password: cred.Password}, nil
+}
+
+// IsSSHURL reports whether a git URL uses the SSH transport.
+func IsSSHURL(rawURL string) bool {
+	if strings.HasPrefix(rawURL, "ssh://") || strings.HasPrefix(rawURL, "git+ssh://") {
+		return true
+	}
+	if strings.Contains(rawURL, "://") {
+		return false
+	}
+	// scp-like syntax: [user@]host:path, but not a Windows drive letter (C:\...)
+	colon := strings.Index(rawURL, ":")
+	if colon <= 1 {
+		return false
+	}
+	slash := strings.IndexAny(rawURL, `/\`)
+	return slash == -1 || colon < slash
+}
+
+// sshUserAndHost extracts the login user (defaulting to "git") and the
+// host:port of an SSH URL.
+func sshUserAndHost(rawURL string) (string, string) {
+	user, host := gitssh.DefaultUsername, ""
+	if strings.Contains(rawURL, "://") {
+		if u, err := url.Parse(rawURL); err == nil {
+			if u.User != nil && u.User.Username() != "" {
+				user = u.User.Username()
+			}
+			host = u.Host
+		}
+	} else {
+		hostPart := rawURL[:strings.Index(rawURL, ":")]
+		if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+			user = hostPart[:at]
+			hostPart = hostPart[at+1:]
+		}
+		host = hostPart
+	}
+	if host != "" {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, "22")
+		}
+	}
+	return user, host
+}
+
+func sshAuth(rawURL string) (transport.AuthMethod, error) {
+	user, host := sshUserAndHost(rawURL)
+	helper, err := knownHostsHelper(host)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyPath := os.Getenv(SSHKeyEnv); keyPath != "" {
+		return publicKeysFromFile(user, keyPath, helper)
+	}
+	hostname, _, _ := net.SplitHostPort(host)
+	keyPath, err := sshKeyFor(hostname)
+	if err != nil {
+		return nil, err
+	}
+	if keyPath != "" {
+		return publicKeysFromFile(user, keyPath, helper)
+	}
+
+	if os.Getenv("SSH_AUTH_SOCK") != "" {
+		if agentAuth, err := gitssh.NewSSHAgentAuth(user); err == nil {
+			agentAuth.HostKeyCallbackHelper = helper
+			return agentAuth, nil
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range defaultKeyFiles {
+			keyPath := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(keyPath); err == nil {
+				return publicKeysFromFile(user, keyPath, helper)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no SSH credentials for %s: start ssh-agent or set %s", rawURL, SSHKeyEnv)
+}
+
+// publicKeysFromFile loads a private key, asking for its passphrase through
+// ARCA_SSH_KEY_PASSPHRASE or an interactive prompt when it is encrypted.
+func publicKeysFromFile(user, keyPath string, helper gitssh.HostKeyCallbackHelper) (*gitssh.PublicKeys, error) {
+	pemBytes, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(pemBytes)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		passphrase := []byte(os.Getenv(SSHKeyPassphraseEnv))
+		if len(passphrase) == 0 {
+			if passphrase, err = promptPassphrase(keyPath); err != nil {
+				return nil, err
+			}
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pemBytes, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", keyPath, err)
+	}
+
+	return &gitssh.PublicKeys{User: user, Signer: signer, HostKeyCallbackHelper: helper}, nil
+}
+
+// knownHostsHelper builds strict host key verification from ARCA_SSH_KNOWN_HOSTS,
+// SSH_KNOWN_HOSTS or the default ~/.ssh/known_hosts and /etc/ssh/ssh_known_hosts.
+func knownHostsHelper(host string) (gitssh.HostKeyCallbackHelper, error) {
+	var files []string
+	for _, f := range filepath.SplitList(os.Getenv(SSHKnownHostsEnv)) {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	db, err := gitssh.NewKnownHostsDb(files...)
+	if err != nil {
+		return gitssh.HostKeyCallbackHelper{}, fmt.Errorf("failed to load known_hosts: %w", err)
+	}
+	helper := gitssh.HostKeyCallbackHelper{HostKeyCallback: db.HostKeyCallback()}
+	if host != "" {
+		helper.HostKeyAlgorithms = db.HostKeyAlgorithms(host)
+	}
+	return helper, nil
+}
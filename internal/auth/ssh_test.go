@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestIsSSHURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"git@github.com:org/assets.git", true},
+		{"ssh://git@github.com/org/assets.git", true},
+		{"ssh://github.com:2222/org/assets.git", true},
+		{"github.com:org/assets.git", true},
+		{"https://github.com/org/assets.git", false},
+		{"file:///tmp/repo", false},
+		{`C:\assets`, false},
+		{"../local/assets", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := IsSSHURL(tt.url); got != tt.expected {
+				t.Errorf("IsSSHURL(%q) = %v; want %v", tt.url, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSSHUserAndHost(t *testing.T) {
+	tests := []struct {
+		url          string
+		expectedUser string
+		expectedHost string
+	}{
+		{"git@github.com:org/assets.git", "git", "github.com:22"},
+		{"deploy@git.example.com:assets.git", "deploy", "git.example.com:22"},
+		{"ssh://ci@example.com:2222/org/assets.git", "ci", "example.com:2222"},
+		{"ssh://example.com/org/assets.git", "git", "example.com:22"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			user, host := sshUserAndHost(tt.url)
+			if user != tt.expectedUser || host != tt.expectedHost {
+				t.Errorf("sshUserAndHost(%q) = %q, %q; want %q, %q", tt.url, user, host, tt.expectedUser, tt.expectedHost)
+			}
+		})
+	}
+}
+
+// writeTestKey writes an ed25519 private key (optionally encrypted) and a
+// known_hosts file, and points the SSH environment variables at them.
+func writeTestKey(t *testing.T, passphrase string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(priv, "test")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "test", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := "github.com " + string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+	if err := os.WriteFile(knownHosts, []byte(line), 0644); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+
+	t.Setenv(SSHKeyEnv, keyPath)
+	t.Setenv(SSHKnownHostsEnv, knownHosts)
+	t.Setenv(SSHKeyPassphraseEnv, "")
+	return keyPath
+}
+
+func TestForURL_SSHKeyFile(t *testing.T) {
+	writeTestKey(t, "")
+
+	method, err := ForURL("deploy@github.com:org/assets.git")
+	if err != nil {
+		t.Fatalf("ForURL failed: %v", err)
+	}
+	keys, ok := method.(*gitssh.PublicKeys)
+	if !ok {
+		t.Fatalf("Expected *ssh.PublicKeys, got %T", method)
+	}
+	if keys.User != "deploy" {
+		t.Errorf("Expected user 'deploy', got '%s'", keys.User)
+	}
+	if keys.HostKeyCallback == nil {
+		t.Errorf("Expected known_hosts verification to be configured")
+	}
+	if len(keys.HostKeyAlgorithms) == 0 {
+		t.Errorf("Expected host key algorithms from known_hosts")
+	}
+}
+
+func TestForURL_EncryptedSSHKey(t *testing.T) {
+	keyPath := writeTestKey(t, "s3cret")
+
+	t.Run("Passphrase from environment", func(t *testing.T) {
+		t.Setenv(SSHKeyPassphraseEnv, "s3cret")
+		if _, err := ForURL("git@github.com:org/assets.git"); err != nil {
+			t.Fatalf("ForURL failed: %v", err)
+		}
+	})
+
+	t.Run("Passphrase from prompt", func(t *testing.T) {
+		original := promptPassphrase
+		defer func() { promptPassphrase = original }()
+
+		prompted := ""
+		promptPassphrase = func(path string) ([]byte, error) {
+			prompted = path
+			return []byte("s3cret"), nil
+		}
+		if _, err := ForURL("git@github.com:org/assets.git"); err != nil {
+			t.Fatalf("ForURL failed: %v", err)
+		}
+		if prompted != keyPath {
+			t.Errorf("Expected prompt for %s, got %q", keyPath, prompted)
+		}
+	})
+
+	t.Run("Wrong passphrase", func(t *testing.T) {
+		t.Setenv(SSHKeyPassphraseEnv, "wrong")
+		if _, err := ForURL("git@github.com:org/assets.git"); err == nil {
+			t.Errorf("Expected an error for a wrong passphrase")
+		}
+	})
+}
+
+func TestForURL_MissingKnownHosts(t *testing.T) {
+	writeTestKey(t, "")
+	t.Setenv(SSHKnownHostsEnv, filepath.Join(t.TempDir(), "missing"))
+
+	if _, err := ForURL("git@github.com:org/assets.git"); err == nil {
+		t.Errorf("Expected an error without a known_hosts file")
+	}
+}
+
+func TestForURL_HTTPS(t *testing.T) {
+	isolateCredentials(t, "", "")
+	t.Setenv("ARCA_GIT_TOKEN", "arca-secret")
+
+	method, err := ForURL("https://github.com/org/assets.git")
+	if err != nil {
+		t.Fatalf("ForURL failed: %v", err)
+	}
+	basic, ok := method.(*http.BasicAuth)
+	if !ok || basic.Password != "arca-secret" {
+		t.Errorf("Expected token basic auth, got %#v", method)
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// Status describes which credential applies to a source URL. It never
+// contains the secret itself.
+type Status struct {
+	Method   string `json:"method"`         // ssh, http or none
+	From     string `json:"from,omitempty"` // where the credential comes from
+	Username string `json:"username,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+}
+
+// Describe reports the credential ForURL would use for a source URL without
+// unlocking keys or revealing secrets.
+func Describe(rawURL string) (Status, error) {
+	if !IsSSHURL(rawURL) {
+		cred, err := LookupCredential(rawURL)
+		if err != nil {
+			return Status{}, err
+		}
+		if cred == nil {
+			return Status{Method: "none"}, nil
+		}
+		return Status{Method: "http", From: cred.Source, Username: cred.Username}, nil
+	}
+
+	user, host := sshUserAndHost(rawURL)
+	status := Status{Method: "ssh", Username: user}
+	if keyPath := os.Getenv(SSHKeyEnv); keyPath != "" {
+		status.From, status.KeyFile = FromEnv+" ("+SSHKeyEnv+")", keyPath
+		return status, nil
+	}
+	hostname, _, _ := net.SplitHostPort(host)
+	keyPath, err := sshKeyFor(hostname)
+	if err != nil {
+		return Status{}, err
+	}
+	if keyPath != "" {
+		status.From, status.KeyFile = FromUserConfig, keyPath
+		return status, nil
+	}
+	if os.Getenv("SSH_AUTH_SOCK") != "" {
+		status.From = "ssh-agent"
+		return status, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range defaultKeyFiles {
+			keyPath := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(keyPath); err == nil {
+				status.From, status.KeyFile = "default key", keyPath
+				return status, nil
+			}
+		}
+	}
+	return Status{Method: "none"}, nil
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/adryledo/arca-cli/internal/fsutil"
+	"github.com/adryledo/arca-cli/internal/hasher"
 	"github.com/adryledo/arca-cli/internal/models"
 	"gopkg.in/yaml.v3"
 )
@@ -15,6 +17,10 @@ const (
 	ConfigFileName   = ".arca-assets.yaml"
 	LockFileName     = ".arca-assets.lock"
 	DefaultSchemaVer = "1.0"
+
+	// VendorDirName is the in-repo tree holding vendored asset copies.
+	VendorDirName       = ".arca/vendor"
+	VendorIndexFileName = "vendor.json"
 )
 
 type Manager struct {
@@ -25,6 +31,22 @@ func NewManager(workspaceRoot string) *Manager {
 	return &Manager{WorkspaceRoot: workspaceRoot}
 }
 
+// Lock acquires the cross-process workspace lock that serializes updates of
+// .arca-assets.yaml and .arca-assets.lock. The lock file lives in the system
+// temp directory so that it never shows up in the project tree.
+func (m *Manager) Lock() (*fsutil.FileLock, error) {
+	root, err := filepath.Abs(m.WorkspaceRoot)
+	if err != nil {
+		return nil, err
+	}
+	name := "arca-" + hasher.HashString(root)[:16] + ".lock"
+	lock, err := fsutil.Lock(filepath.Join(os.TempDir(), name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock workspace: %w", err)
+	}
+	return lock, nil
+}
+
 // LoadConfig loads the .arca-assets.yaml file.
 func (m *Manager) LoadConfig() (*models.Config, error) {
 	path := filepath.Join(m.WorkspaceRoot, ConfigFileName)
@@ -54,14 +76,17 @@ func (m *Manager) SaveConfig(cfg *models.Config) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	return fsutil.WriteFileAtomic(path, data, 0644)
 }
 
 // EnsureSource registers a source if it doesn't exist and returns its alias.
 func (m *Manager) EnsureSource(cfg *models.Config, url string, stype models.SourceType) string {
 	// Check if URL already exists
 	for alias, src := range cfg.Sources {
-		if (stype == models.SourceGit && src.URL == url) || (stype == models.SourceLocal && src.Path == url) {
+		if stype == models.SourceLocal && src.Path == url {
+			return alias
+		}
+		if stype != models.SourceLocal && src.Type == stype && src.URL == url {
 			return alias
 		}
 	}
@@ -81,16 +106,21 @@ func (m *Manager) EnsureSource(cfg *models.Config, url string, stype models.Sour
 	}
 
 	srcCfg := models.SourceConfig{Type: stype}
-	if stype == models.SourceGit {
+	switch stype {
+	case models.SourceGit:
 		srcCfg.URL = url
 		// Simplified: infer provider
 		if strings.Contains(url, "github.com") {
 			srcCfg.Provider = "github"
-		} else if strings.Contains(url, "azure.com") {
+		} else if strings.Contains(url, "gitlab.com") {
+			srcCfg.Provider = "gitlab"
+		} else if strings.Contains(url, "azure.com") || strings.Contains(url, "visualstudio.com") {
 			srcCfg.Provider = "azure"
 		}
-	} else {
+	case models.SourceLocal:
 		srcCfg.Path = url
+	default:
+		srcCfg.URL = url
 	}
 
 	if cfg.Sources == nil {
@@ -136,7 +166,50 @@ func (m *Manager) SaveLockfile(lock *models.Lockfile) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	return fsutil.WriteFileAtomic(path, data, 0644)
+}
+
+// VendorDir returns the absolute path of the in-repo vendor tree.
+func (m *Manager) VendorDir() string {
+	return filepath.Join(m.WorkspaceRoot, filepath.FromSlash(VendorDirName))
+}
+
+// LoadVendorIndex loads .arca/vendor/vendor.json.
+// It returns nil without error when the project has not been vendored.
+func (m *Manager) LoadVendorIndex() (*models.VendorIndex, error) {
+	path := filepath.Join(m.VendorDir(), VendorIndexFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var idx models.VendorIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse vendor index: %w", err)
+	}
+	return &idx, nil
+}
+
+// SaveVendorIndex saves the vendor index to .arca/vendor/vendor.json.
+func (m *Manager) SaveVendorIndex(idx *models.VendorIndex) error {
+	return WriteVendorIndex(m.VendorDir(), idx)
+}
+
+// WriteVendorIndex writes idx as the vendor.json of the vendor tree at dir,
+// e.g. a tree being built before it replaces .arca/vendor.
+func WriteVendorIndex(dir string, idx *models.VendorIndex) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, VendorIndexFileName)
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0644)
 }
 
 func (m *Manager) deriveAlias(input string) string {
@@ -33,6 +33,15 @@ func TestManager_Config(t *testing.T) {
 		t.Errorf("Expected alias 'repo-1', got '%s'", alias2)
 	}
 
+	// http sources keep their URL and do not collide with git sources
+	alias3 := mgr.EnsureSource(cfg, "https://files.example.com/assets", models.SourceHTTP)
+	if cfg.Sources[alias3].URL != "https://files.example.com/assets" || cfg.Sources[alias3].Type != models.SourceHTTP {
+		t.Errorf("Expected http source with URL, got %+v", cfg.Sources[alias3])
+	}
+	if again := mgr.EnsureSource(cfg, "https://files.example.com/assets", models.SourceHTTP); again != alias3 {
+		t.Errorf("Expected existing alias '%s', got '%s'", alias3, again)
+	}
+
 	// 3. Add asset
 	mgr.AddAsset(cfg, models.AssetEntry{
 		ID:      "test-skill",
@@ -102,6 +111,40 @@ func TestManager_Lockfile(t *testing.T) {
 	}
 }
 
+func TestManager_VendorIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+
+	// 1. Missing index means the project is not vendored
+	idx, err := mgr.LoadVendorIndex()
+	if err != nil {
+		t.Fatalf("Failed to load missing vendor index: %v", err)
+	}
+	if idx != nil {
+		t.Errorf("Expected nil vendor index, got %+v", idx)
+	}
+
+	// 2. Save and reload
+	idx = &models.VendorIndex{Assets: []models.VendoredAsset{
+		{ID: "test", Version: "1.0.0", Source: "provider", Kind: models.KindSkill, SHA256: "hash"},
+	}}
+	if err := mgr.SaveVendorIndex(idx); err != nil {
+		t.Fatalf("Failed to save vendor index: %v", err)
+	}
+
+	loaded, err := mgr.LoadVendorIndex()
+	if err != nil {
+		t.Fatalf("Failed to load saved vendor index: %v", err)
+	}
+	va, ok := loaded.Find("provider", "test")
+	if !ok {
+		t.Fatalf("Expected vendored asset to be found")
+	}
+	if va.SHA256 != "hash" || va.Kind != models.KindSkill {
+		t.Errorf("Vendor index did not round-trip, got %+v", va)
+	}
+}
+
 func TestDeriveAlias(t *testing.T) {
 	mgr := NewManager("/tmp")
 
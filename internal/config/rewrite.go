@@ -0,0 +1,38 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+// RewriteURL applies the rule whose InsteadOf is the longest prefix of
+// rawURL, the way git applies url.<base>.insteadOf. Earlier rules win ties,
+// so project rules listed before user rules take precedence.
+func RewriteURL(rawURL string, rules []models.URLRewrite) string {
+	best := -1
+	for i, r := range rules {
+		if r.InsteadOf == "" || !strings.HasPrefix(rawURL, r.InsteadOf) {
+			continue
+		}
+		if best < 0 || len(r.InsteadOf) > len(rules[best].InsteadOf) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return rawURL
+	}
+	return rules[best].URL + strings.TrimPrefix(rawURL, rules[best].InsteadOf)
+}
+
+// URLRewrites returns the rewrite rules of a project followed by the user's.
+func URLRewrites(project *models.Config, user *models.UserConfig) []models.URLRewrite {
+	var rules []models.URLRewrite
+	if project != nil {
+		rules = append(rules, project.Rewrites...)
+	}
+	if user != nil {
+		rules = append(rules, user.Rewrites...)
+	}
+	return rules
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+func TestRewriteURL(t *testing.T) {
+	project := &models.Config{Rewrites: []models.URLRewrite{
+		{URL: "https://git.corp.example.com/github/", InsteadOf: "https://github.com/"},
+	}}
+	user := &models.UserConfig{Rewrites: []models.URLRewrite{
+		{URL: "https://user-mirror.example.com/", InsteadOf: "https://github.com/"},
+		{URL: "https://git.corp.example.com/special/", InsteadOf: "https://github.com/org/special"},
+		{URL: "ssh://git@git.corp.example.com/", InsteadOf: "git@github.com:"},
+	}}
+	rules := URLRewrites(project, user)
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://github.com/org/assets.git", "https://git.corp.example.com/github/org/assets.git"},
+		{"https://github.com/org/special-assets", "https://git.corp.example.com/special/-assets"},
+		{"git@github.com:org/assets.git", "ssh://git@git.corp.example.com/org/assets.git"},
+		{"https://gitlab.com/org/assets", "https://gitlab.com/org/assets"},
+	}
+	for _, tt := range tests {
+		if got := RewriteURL(tt.in, rules); got != tt.want {
+			t.Errorf("RewriteURL(%s): expected %s, got %s", tt.in, tt.want, got)
+		}
+	}
+
+	if got := RewriteURL("https://github.com/x", nil); got != "https://github.com/x" {
+		t.Errorf("Expected URL unchanged without rules, got %s", got)
+	}
+}
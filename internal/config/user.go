@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/netutil"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// UserConfigEnv overrides the location of the user config file.
+	UserConfigEnv = "ARCA_CONFIG"
+	// CacheDirEnv overrides the writable cache location.
+	CacheDirEnv = "ARCA_CACHE_DIR"
+	// CacheSeedsEnv lists read-only seed caches, separated by the OS path list separator.
+	CacheSeedsEnv = "ARCA_CACHE_SEEDS"
+	// TimeoutEnv overrides network.timeout.
+	TimeoutEnv = "ARCA_TIMEOUT"
+	// RetriesEnv overrides network.retries.
+	RetriesEnv = "ARCA_RETRIES"
+	// CABundleEnv overrides network.caBundle.
+	CABundleEnv = "ARCA_CA_BUNDLE"
+)
+
+// UserConfigPath returns the location of the user config file: $ARCA_CONFIG,
+// or arca/config.yaml inside the OS user config directory.
+func UserConfigPath() (string, error) {
+	if p := os.Getenv(UserConfigEnv); p != "" {
+		return expandHome(p), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "arca", "config.yaml"), nil
+}
+
+// KeysDir returns where `arca keys` keeps signing keys: keys/ next to the
+// user config file.
+func KeysDir() (string, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "keys"), nil
+}
+
+// LoadUserConfig loads the user config file. A missing file yields an empty config.
+func LoadUserConfig() (*models.UserConfig, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return &models.UserConfig{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &models.UserConfig{}, nil
+		}
+		return nil, err
+	}
+
+	var cfg models.UserConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse user config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// CacheDir resolves the writable cache location, in order of precedence:
+// $ARCA_CACHE_DIR, cache.dir from the user config, $XDG_CACHE_HOME/arca,
+// and finally ~/.arca-cache.
+func CacheDir(user *models.UserConfig) string {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return expandHome(dir)
+	}
+	if user != nil && user.Cache.Dir != "" {
+		return expandHome(user.Cache.Dir)
+	}
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "arca")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".arca-cache")
+}
+
+// CacheSeeds returns the read-only seed caches from $ARCA_CACHE_SEEDS followed
+// by cache.seeds from the user config.
+func CacheSeeds(user *models.UserConfig) []string {
+	var seeds []string
+	for _, s := range filepath.SplitList(os.Getenv(CacheSeedsEnv)) {
+		if s != "" {
+			seeds = append(seeds, expandHome(s))
+		}
+	}
+	if user != nil {
+		for _, s := range user.Cache.Seeds {
+			seeds = append(seeds, expandHome(s))
+		}
+	}
+	return seeds
+}
+
+// NetworkPolicy returns the timeout and retry policy for sources:
+// netutil.DefaultPolicy with network.timeout and network.retries from the
+// user config applied, then $ARCA_TIMEOUT and $ARCA_RETRIES.
+func NetworkPolicy(user *models.UserConfig) (netutil.Policy, error) {
+	policy := netutil.DefaultPolicy
+	timeout, retries := "", ""
+	if user != nil {
+		timeout = user.Network.Timeout
+		if user.Network.Retries != nil {
+			retries = strconv.Itoa(*user.Network.Retries)
+		}
+	}
+	if v := os.Getenv(TimeoutEnv); v != "" {
+		timeout = v
+	}
+	if v := os.Getenv(RetriesEnv); v != "" {
+		retries = v
+	}
+
+	if timeout != "" {
+		d, err := parseTimeout(timeout)
+		if err != nil {
+			return policy, err
+		}
+		policy.Timeout = d
+	}
+	if retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return policy, fmt.Errorf("invalid network retries %q: expected a number >= 0", retries)
+		}
+		policy.Retries = n
+	}
+	return policy, nil
+}
+
+// NetworkTransport returns the TLS settings for every HTTP request:
+// network.caBundle (or $ARCA_CA_BUNDLE) and network.insecureHosts from the
+// user config.
+func NetworkTransport(user *models.UserConfig) netutil.TransportConfig {
+	var cfg netutil.TransportConfig
+	if user != nil {
+		cfg.CABundle = user.Network.CABundle
+		cfg.InsecureHosts = user.Network.InsecureHosts
+	}
+	if v := os.Getenv(CABundleEnv); v != "" {
+		cfg.CABundle = v
+	}
+	if cfg.CABundle != "" {
+		cfg.CABundle = expandHome(cfg.CABundle)
+	}
+	return cfg
+}
+
+// parseTimeout parses a duration such as "30s"; a bare "0" disables the timeout.
+func parseTimeout(s string) (time.Duration, error) {
+	if s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid network timeout %q: expected a duration such as 30s", s)
+	}
+	return d, nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/netutil"
+)
+
+func TestLoadUserConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(UserConfigEnv, path)
+
+	// Missing file yields an empty config
+	cfg, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig failed: %v", err)
+	}
+	if cfg.Cache.Dir != "" {
+		t.Errorf("Expected empty config, got %+v", cfg)
+	}
+
+	content := `
+cache:
+  dir: /data/arca
+  seeds:
+    - /opt/arca-seed
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err = LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig failed: %v", err)
+	}
+	if cfg.Cache.Dir != "/data/arca" || len(cfg.Cache.Seeds) != 1 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	user := &models.UserConfig{Cache: models.CacheSettings{Dir: "/from/config"}}
+
+	tests := []struct {
+		name     string
+		env      string
+		xdg      string
+		user     *models.UserConfig
+		expected string
+	}{
+		{"Default", "", "", nil, filepath.Join(home, ".arca-cache")},
+		{"XDG", "", "/xdg", nil, filepath.Join("/xdg", "arca")},
+		{"Config over XDG", "", "/xdg", user, "/from/config"},
+		{"Env over config", "/from/env", "/xdg", user, "/from/env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CacheDirEnv, tt.env)
+			t.Setenv("XDG_CACHE_HOME", tt.xdg)
+			if got := CacheDir(tt.user); got != tt.expected {
+				t.Errorf("CacheDir() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCacheSeeds(t *testing.T) {
+	t.Setenv(CacheSeedsEnv, "/ci/seed"+string(os.PathListSeparator)+"/team/seed")
+	user := &models.UserConfig{Cache: models.CacheSettings{Seeds: []string{"/config/seed"}}}
+
+	want := []string{"/ci/seed", "/team/seed", "/config/seed"}
+	if got := CacheSeeds(user); !reflect.DeepEqual(got, want) {
+		t.Errorf("CacheSeeds() = %v; want %v", got, want)
+	}
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	one := 1
+
+	tests := []struct {
+		name        string
+		user        *models.UserConfig
+		timeoutEnv  string
+		retriesEnv  string
+		wantTimeout time.Duration
+		wantRetries int
+		wantErr     bool
+	}{
+		{name: "defaults", user: nil, wantTimeout: netutil.DefaultPolicy.Timeout, wantRetries: netutil.DefaultPolicy.Retries},
+		{name: "user config", user: &models.UserConfig{Network: models.NetworkConfig{Timeout: "30s", Retries: &one}}, wantTimeout: 30 * time.Second, wantRetries: 1},
+		{name: "env wins", user: &models.UserConfig{Network: models.NetworkConfig{Timeout: "30s", Retries: &one}}, timeoutEnv: "5s", retriesEnv: "0", wantTimeout: 5 * time.Second, wantRetries: 0},
+		{name: "no timeout", user: &models.UserConfig{Network: models.NetworkConfig{Timeout: "0"}}, wantTimeout: 0, wantRetries: netutil.DefaultPolicy.Retries},
+		{name: "bad timeout", user: &models.UserConfig{Network: models.NetworkConfig{Timeout: "soon"}}, wantErr: true},
+		{name: "bad retries", retriesEnv: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(TimeoutEnv, tt.timeoutEnv)
+			t.Setenv(RetriesEnv, tt.retriesEnv)
+			p, err := NetworkPolicy(tt.user)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NetworkPolicy failed: %v", err)
+			}
+			if p.Timeout != tt.wantTimeout || p.Retries != tt.wantRetries {
+				t.Errorf("Expected timeout %s and %d retries, got %s and %d", tt.wantTimeout, tt.wantRetries, p.Timeout, p.Retries)
+			}
+		})
+	}
+}
+
+func TestNetworkTransport(t *testing.T) {
+	user := &models.UserConfig{Network: models.NetworkConfig{CABundle: "/etc/corp-ca.pem", InsecureHosts: []string{"git.lab.internal"}}}
+
+	t.Setenv(CABundleEnv, "")
+	cfg := NetworkTransport(user)
+	if cfg.CABundle != "/etc/corp-ca.pem" || !reflect.DeepEqual(cfg.InsecureHosts, []string{"git.lab.internal"}) {
+		t.Errorf("Unexpected transport config: %+v", cfg)
+	}
+
+	t.Setenv(CABundleEnv, "/opt/ca.pem")
+	if cfg := NetworkTransport(user); cfg.CABundle != "/opt/ca.pem" {
+		t.Errorf("Expected %s to win, got %s", CABundleEnv, cfg.CABundle)
+	}
+	if cfg := NetworkTransport(nil); cfg.CABundle != "/opt/ca.pem" || cfg.InsecureHosts != nil {
+		t.Errorf("Unexpected transport config without a user config: %+v", cfg)
+	}
+}
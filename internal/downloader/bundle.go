@@ -0,0 +1,342 @@
+package downloader
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BundleFormat is the layout version written by ExportBundle.
+const BundleFormat = 1
+
+// bundleIndexName is the checksum index at the root of a bundle. Objects use
+// the cache layout (sha256/<hash>) and manifests live at manifests/<sha256>.yaml.
+const bundleIndexName = "bundle.json"
+
+// BundleEntry is a cache index entry carried by a bundle.
+type BundleEntry struct {
+	Source  string `json:"source"`
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	SHA256  string `json:"sha256"`
+	IsDir   bool   `json:"isDir"`
+	Commit  string `json:"commit"`
+}
+
+// BundleManifest is a source manifest at a ref carried by a bundle. SHA256
+// is the digest of the raw manifest bytes.
+type BundleManifest struct {
+	Source string `json:"source"`
+	Ref    string `json:"ref"`
+	SHA256 string `json:"sha256"`
+
+	Data []byte `json:"-"`
+}
+
+// BundleIndex lists the content of a bundle with the digest of every part.
+type BundleIndex struct {
+	Format    int              `json:"format"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Entries   []BundleEntry    `json:"entries"`
+	Manifests []BundleManifest `json:"manifests"`
+}
+
+// ExportBundle writes a gzipped tar with the objects of entries and the given
+// manifests, preceded by a checksum index. Every entry's object must be in
+// the cache (or a seed); IsDir is taken from the object.
+func (c *CacheProvider) ExportBundle(w io.Writer, entries []BundleEntry, manifests []BundleManifest) (*BundleIndex, error) {
+	index := &BundleIndex{
+		Format:    BundleFormat,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Entries:   append([]BundleEntry{}, entries...),
+		Manifests: make([]BundleManifest, 0, len(manifests)),
+	}
+	objects := make(map[string]string)
+	var missing []error
+	for i, e := range index.Entries {
+		objPath, ok := objects[e.SHA256]
+		if !ok {
+			objPath, ok = c.Object(e.SHA256)
+		}
+		if !ok {
+			missing = append(missing, fmt.Errorf("%s/%s@%s (%s) is not in the cache", e.Source, e.ID, e.Version, e.SHA256))
+			continue
+		}
+		objects[e.SHA256] = objPath
+		info, err := os.Stat(objPath)
+		if err != nil {
+			return nil, err
+		}
+		index.Entries[i].IsDir = info.IsDir()
+	}
+	if len(missing) > 0 {
+		return nil, errors.Join(missing...)
+	}
+	for _, m := range manifests {
+		m.SHA256 = rawHash(m.Data)
+		index.Manifests = append(index.Manifests, m)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, bundleIndexName, data, 0644, index.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(objects))
+	for h := range objects {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+	for _, h := range hashes {
+		if err := writeTarTree(tw, path.Join(objectsDir, h), objects[h], index.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range index.Manifests {
+		if err := writeTarFile(tw, path.Join(manifestsDir, m.SHA256+".yaml"), m.Data, 0644, index.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// ImportBundle unpacks a bundle written by ExportBundle into a staging area,
+// verifies every object and manifest against the checksum index and only
+// then places them into the cache. Nothing is imported when a check fails.
+func (c *CacheProvider) ImportBundle(r io.Reader) (*BundleIndex, error) {
+	staging, err := c.NewStaging()
+	if err != nil {
+		return nil, err
+	}
+	defer staging.Close()
+
+	if err := extractBundle(r, staging.Dir); err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(staging.Dir, bundleIndexName))
+	if err != nil {
+		return nil, fmt.Errorf("bundle has no %s: %w", bundleIndexName, err)
+	}
+	var index BundleIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", bundleIndexName, err)
+	}
+	if index.Format < 1 || index.Format > BundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format %d", index.Format)
+	}
+
+	// Verify everything before touching the cache
+	var problems []error
+	verified := make(map[string]bool)
+	for _, e := range index.Entries {
+		if !validName(e.Source) || !validName(e.ID) || !validName(e.Version) {
+			problems = append(problems, fmt.Errorf("entry %s/%s@%s has an invalid name", e.Source, e.ID, e.Version))
+			continue
+		}
+		if verified[e.SHA256] {
+			continue
+		}
+		if err := verifyBundleObject(staging.Dir, e); err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		verified[e.SHA256] = true
+	}
+	for i, m := range index.Manifests {
+		if !validDigest(m.SHA256) {
+			problems = append(problems, fmt.Errorf("manifest of %s at %s has an invalid sha256 %q", m.Source, m.Ref, m.SHA256))
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(staging.Dir, manifestsDir, m.SHA256+".yaml"))
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Errorf("manifest of %s at %s is missing", m.Source, m.Ref))
+		case rawHash(data) != m.SHA256:
+			problems = append(problems, fmt.Errorf("manifest of %s at %s does not match its checksum", m.Source, m.Ref))
+		default:
+			index.Manifests[i].Data = data
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("bundle verification failed: %w", errors.Join(problems...))
+	}
+
+	for h := range verified {
+		writeEntries := func() error {
+			for _, e := range index.Entries {
+				if e.SHA256 != h {
+					continue
+				}
+				entry := IndexEntry{SHA256: e.SHA256, IsDir: e.IsDir, Commit: e.Commit, StoredAt: time.Now()}
+				if err := c.writeIndex(e.Source, e.ID, e.Version, entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if _, err := c.placeObject(filepath.Join(staging.Dir, objectsDir, h), h, writeEntries); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range index.Manifests {
+		if err := c.StoreManifest(m.Source, m.Ref, m.Data); err != nil {
+			return nil, err
+		}
+	}
+	return &index, nil
+}
+
+// verifyBundleObject checks that the staged object of e has the digest it is named by.
+func verifyBundleObject(dir string, e BundleEntry) error {
+	if !validDigest(e.SHA256) {
+		return fmt.Errorf("%s/%s@%s has an invalid sha256 %q", e.Source, e.ID, e.Version, e.SHA256)
+	}
+	objPath := filepath.Join(dir, objectsDir, e.SHA256)
+	info, err := os.Stat(objPath)
+	if err != nil {
+		return fmt.Errorf("object of %s/%s@%s is missing", e.Source, e.ID, e.Version)
+	}
+	if info.IsDir() != e.IsDir {
+		return fmt.Errorf("object of %s/%s@%s has the wrong type", e.Source, e.ID, e.Version)
+	}
+	got, err := hashPath(objPath, e.IsDir)
+	if err != nil {
+		return err
+	}
+	if got != e.SHA256 {
+		return fmt.Errorf("object of %s/%s@%s hashes to %s, expected %s", e.Source, e.ID, e.Version, got, e.SHA256)
+	}
+	return nil
+}
+
+// validDigest reports whether s is a lowercase hex SHA-256 digest, so that it
+// can safely name a file.
+func validDigest(s string) bool {
+	if len(s) != sha256.Size*2 || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// validName reports whether s can be used as a single path element of the cache index.
+func validName(s string) bool {
+	return s != "" && filepath.IsLocal(s) && !strings.ContainsAny(s, `/\`)
+}
+
+// rawHash returns the SHA-256 of data as is, without line ending normalization.
+func rawHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, mode int64, modTime time.Time) error {
+	hdr := &tar.Header{Name: name, Mode: mode, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// writeTarTree adds a file or directory tree at root to the tar under name.
+func writeTarTree(tw *tar.Writer, name, root string, modTime time.Time) error {
+	return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		entry := path.Join(name, filepath.ToSlash(rel))
+		if info.IsDir() {
+			return tw.WriteHeader(&tar.Header{Name: entry + "/", Mode: 0755, ModTime: modTime, Typeflag: tar.TypeDir})
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		mode := int64(0644)
+		if info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+		return writeTarFile(tw, entry, data, mode, modTime)
+	})
+}
+
+// extractBundle unpacks the regular files and directories of a gzipped tar
+// into dir, refusing paths that leave it.
+func extractBundle(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(path.Clean(hdr.Name))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("unsafe path %q", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			mode := os.FileMode(0644)
+			if hdr.Mode&0111 != 0 {
+				mode = 0755
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package downloader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rewriteBundle returns a copy of a bundle with the content of matching
+// files replaced by edit.
+func rewriteBundle(t *testing.T, data []byte, edit func(name string, content []byte) []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tr)
+		if hdr.Typeflag == tar.TypeReg {
+			content = edit(hdr.Name, content)
+			hdr.Size = int64(len(content))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(content)
+	}
+	tw.Close()
+	gw.Close()
+	return out.Bytes()
+}
+
+func TestCacheProvider_Bundle(t *testing.T) {
+	src := NewCacheProvider(t.TempDir())
+	fileHash := storeTestAsset(t, src, "org", "rules", "1.0.0", "be careful\n")
+
+	staging, err := src.NewStaging()
+	if err != nil {
+		t.Fatal(err)
+	}
+	skillDir := filepath.Join(staging.Dir, "review")
+	os.MkdirAll(filepath.Join(skillDir, "scripts"), 0755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("review"), 0644)
+	os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	_, dirHash, err := src.Store("org", "review", "2.0.0", skillDir, true, "def")
+	staging.Close()
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	entries := []BundleEntry{
+		{Source: "org", ID: "rules", Version: "1.0.0", SHA256: fileHash, Commit: "abc"},
+		{Source: "org", ID: "review", Version: "2.0.0", SHA256: dirHash, Commit: "def"},
+	}
+	manifests := []BundleManifest{{Source: "https://example.com/org", Ref: "abc", Data: []byte("schema: \"1.0\"\r\n")}}
+
+	var buf bytes.Buffer
+	index, err := src.ExportBundle(&buf, entries, manifests)
+	if err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+	if !index.Entries[1].IsDir || index.Entries[0].IsDir {
+		t.Errorf("Expected IsDir to follow the objects, got %+v", index.Entries)
+	}
+	bundle := buf.Bytes()
+
+	t.Run("import", func(t *testing.T) {
+		dst := NewCacheProvider(t.TempDir())
+		if _, err := dst.ImportBundle(bytes.NewReader(bundle)); err != nil {
+			t.Fatalf("ImportBundle failed: %v", err)
+		}
+		for _, e := range entries {
+			_, entry, ok := dst.Lookup(e.Source, e.ID, e.Version)
+			if !ok || entry.SHA256 != e.SHA256 || entry.Commit != e.Commit {
+				t.Errorf("Expected %s@%s to be imported, got %+v (%v)", e.ID, e.Version, entry, ok)
+			}
+		}
+		data, ok := dst.Manifest("https://example.com/org", "abc")
+		if !ok || string(data) != "schema: \"1.0\"\r\n" {
+			t.Errorf("Expected the manifest byte for byte, got %q (%v)", data, ok)
+		}
+	})
+
+	tests := []struct {
+		name string
+		edit func(name string, content []byte) []byte
+		want string
+	}{
+		{
+			name: "tampered object",
+			edit: func(name string, content []byte) []byte {
+				if strings.HasSuffix(name, "SKILL.md") {
+					return []byte("tampered")
+				}
+				return content
+			},
+			want: "hashes to",
+		},
+		{
+			name: "tampered manifest",
+			edit: func(name string, content []byte) []byte {
+				if strings.HasPrefix(name, manifestsDir+"/") {
+					return []byte("schema: \"2.0\"")
+				}
+				return content
+			},
+			want: "does not match its checksum",
+		},
+		{
+			name: "unsafe entry name",
+			edit: func(name string, content []byte) []byte {
+				if name == bundleIndexName {
+					return bytes.Replace(content, []byte(`"id": "rules"`), []byte(`"id": "../rules"`), 1)
+				}
+				return content
+			},
+			want: "invalid name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := NewCacheProvider(t.TempDir())
+			_, err := dst.ImportBundle(bytes.NewReader(rewriteBundle(t, bundle, tt.edit)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Expected error containing %q, got %v", tt.want, err)
+			}
+			if got, _ := dst.Entries(); len(got) != 0 {
+				t.Errorf("Expected nothing to be imported, got %d entries", len(got))
+			}
+			if _, ok := dst.Object(fileHash); ok {
+				t.Error("Expected no object to be placed")
+			}
+		})
+	}
+
+	t.Run("missing object", func(t *testing.T) {
+		missing := []BundleEntry{{Source: "org", ID: "gone", Version: "1.0.0", SHA256: strings.Repeat("0", 64)}}
+		if _, err := src.ExportBundle(io.Discard, missing, nil); err == nil {
+			t.Fatal("Expected an error for an uncached entry")
+		}
+	})
+}
@@ -1,12 +1,58 @@
 package downloader
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/fsutil"
+	"github.com/adryledo/arca-cli/internal/hasher"
+)
+
+const (
+	// objectsDir holds immutable content-addressed objects (sha256/<hash>).
+	objectsDir = "sha256"
+	// indexDir holds pointers from <source>/<id>/<version> to objects.
+	indexDir = "index"
+	// stagingDir holds in-progress downloads before they are stored.
+	stagingDir = "tmp"
+	// locksDir holds the lock files guarding cache entries and objects.
+	locksDir = "locks"
+	// sourcesDir holds caches kept by source providers (e.g. HTTP responses).
+	sourcesDir = "sources"
+	// markerFile tags a directory as an ARCA cache. Destructive commands
+	// refuse roots without it, so that a misconfigured cache location never
+	// wipes unrelated data. It follows the Cache Directory Tagging convention,
+	// which backup tools honour as well.
+	markerFile = "CACHEDIR.TAG"
+	markerData = "Signature: 8a477f597d28d172789f06886806bc55\n# This file marks the ARCA asset cache (https://github.com/adryledo/arca-cli).\n"
+
+	// abandonedAfter is how old an unlocked staging area must be before it is
+	// treated as left behind by an interrupted run.
+	abandonedAfter = time.Minute
 )
 
+// CacheProvider manages the global asset cache. Asset content is stored once
+// per SHA-256 digest under sha256/<hash>; the source/id/version layout only
+// keeps small index pointers to those objects.
 type CacheProvider struct {
 	CacheRoot string
+	// Seeds are read-only caches with the same layout, consulted before
+	// CacheRoot. They are typically baked into a CI image or shared on a
+	// team volume, and are never written to or repaired.
+	Seeds []string
+}
+
+// IndexEntry is the pointer stored for a source/id/version triple.
+type IndexEntry struct {
+	SHA256   string    `json:"sha256"`
+	IsDir    bool      `json:"isDir"`
+	Commit   string    `json:"commit"`
+	StoredAt time.Time `json:"storedAt"`
 }
 
 func NewCacheProvider(customPath string) *CacheProvider {
@@ -17,12 +63,14 @@ func NewCacheProvider(customPath string) *CacheProvider {
 	return &CacheProvider{CacheRoot: customPath}
 }
 
-// GetAssetDir returns the path to a specific asset version in the cache.
+// GetAssetDir returns the human-readable source/id/version directory of an asset.
+// The cache itself only uses this layout for index pointers; it is used as-is
+// by trees that mirror assets verbatim, such as .arca/vendor.
 func (c *CacheProvider) GetAssetDir(sourceAlias, assetID, version string) string {
 	return filepath.Join(c.CacheRoot, sourceAlias, assetID, version)
 }
 
-// GetAssetPath returns the path to the actual asset file/directory.
+// GetAssetPath returns the path to the actual asset file/directory in the readable layout.
 func (c *CacheProvider) GetAssetPath(sourceAlias, assetID, version string, isDir bool) string {
 	dir := c.GetAssetDir(sourceAlias, assetID, version)
 	if isDir {
@@ -31,7 +79,7 @@ func (c *CacheProvider) GetAssetPath(sourceAlias, assetID, version string, isDir
 	return filepath.Join(dir, assetID+".md")
 }
 
-// EnsureDir makes sure the cache directory for an asset exists.
+// EnsureDir makes sure the readable directory for an asset exists.
 func (c *CacheProvider) EnsureDir(sourceAlias, assetID, version string) (string, error) {
 	dir := c.GetAssetDir(sourceAlias, assetID, version)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -40,7 +88,308 @@ func (c *CacheProvider) EnsureDir(sourceAlias, assetID, version string) (string,
 	return dir, nil
 }
 
-// Clear removes everything from the cache.
+// ObjectPath returns the location of the object with the given SHA-256 digest.
+func (c *CacheProvider) ObjectPath(hash string) string {
+	return filepath.Join(c.CacheRoot, objectsDir, hash)
+}
+
+// IndexPath returns the location of the index pointer for an asset version.
+func (c *CacheProvider) IndexPath(sourceAlias, assetID, version string) string {
+	return filepath.Join(c.CacheRoot, indexDir, sourceAlias, assetID, version+".json")
+}
+
+// SourcesDir returns the directory source providers keep their own caches in.
+func (c *CacheProvider) SourcesDir() string {
+	return filepath.Join(c.CacheRoot, sourcesDir)
+}
+
+// ensureRoot creates the cache root and tags it with the cache marker.
+func (c *CacheProvider) ensureRoot() error {
+	if err := os.MkdirAll(c.CacheRoot, 0755); err != nil {
+		return err
+	}
+	marker := filepath.Join(c.CacheRoot, markerFile)
+	if _, err := os.Stat(marker); err == nil {
+		return nil
+	}
+	return fsutil.WriteFileAtomic(marker, []byte(markerData), 0644)
+}
+
+// isMarked reports whether the cache root carries the cache marker.
+func (c *CacheProvider) isMarked() bool {
+	_, err := os.Stat(filepath.Join(c.CacheRoot, markerFile))
+	return err == nil
+}
+
+// Staging is a private download area inside the cache. It is locked for as
+// long as it is in use so that concurrent runs never reclaim it.
+type Staging struct {
+	Dir  string
+	lock *fsutil.FileLock
+}
+
+// Close removes the staging area and releases its lock.
+func (s *Staging) Close() error {
+	err := os.RemoveAll(s.Dir)
+	if uerr := s.lock.Unlock(); err == nil {
+		err = uerr
+	}
+	os.Remove(s.Dir + ".lock")
+	return err
+}
+
+// NewStaging creates an empty directory inside the cache to download into.
+// Staging lives on the same filesystem as the object store so that Store can
+// move content into place with a rename. Areas abandoned by interrupted runs
+// are reclaimed first.
+func (c *CacheProvider) NewStaging() (*Staging, error) {
+	if err := c.ensureRoot(); err != nil {
+		return nil, err
+	}
+	root := filepath.Join(c.CacheRoot, stagingDir)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	c.RecoverStaging()
+
+	f, err := os.CreateTemp(root, "stage-*.lock")
+	if err != nil {
+		return nil, err
+	}
+	lockPath := f.Name()
+	f.Close()
+
+	lock, err := fsutil.Lock(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSuffix(lockPath, ".lock")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		lock.Unlock()
+		os.Remove(lockPath)
+		return nil, err
+	}
+	return &Staging{Dir: dir, lock: lock}, nil
+}
+
+// RecoverStaging removes staging areas left behind by interrupted runs.
+// An area is abandoned when nobody holds its lock; very recent areas are
+// skipped because their owner may not have locked them yet.
+func (c *CacheProvider) RecoverStaging() {
+	root := filepath.Join(c.CacheRoot, stagingDir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".lock") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < abandonedAfter {
+			continue
+		}
+		lockPath := filepath.Join(root, e.Name())
+		lock, err := fsutil.TryLock(lockPath)
+		if err != nil {
+			continue
+		}
+		_ = removeObject(strings.TrimSuffix(lockPath, ".lock"))
+		lock.Unlock()
+		os.Remove(lockPath)
+	}
+}
+
+// LockEntry acquires the cross-process lock of an asset version so that only
+// one run downloads and stores it at a time. Waiting for another run stops
+// once ctx is done.
+func (c *CacheProvider) LockEntry(ctx context.Context, sourceAlias, assetID, version string) (*fsutil.FileLock, error) {
+	key := "entry:" + sourceAlias + "/" + assetID + "@" + version
+	return fsutil.LockContext(ctx, filepath.Join(c.CacheRoot, locksDir, hasher.HashString(key)+".lock"))
+}
+
+func (c *CacheProvider) lockObject(hash string) (*fsutil.FileLock, error) {
+	return fsutil.Lock(filepath.Join(c.CacheRoot, locksDir, "object-"+hash+".lock"))
+}
+
+// Store moves staged content into the object store and points the index entry
+// for sourceAlias/assetID/version at it. Objects are written once: if an object
+// with the same digest already exists and verifies, the staged copy is discarded.
+// It returns the object path and its digest.
+func (c *CacheProvider) Store(sourceAlias, assetID, version, stagedPath string, isDir bool, commit string) (string, string, error) {
+	hash, err := hashPath(stagedPath, isDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash staged asset: %w", err)
+	}
+
+	objPath, err := c.placeObject(stagedPath, hash, func() error {
+		entry := IndexEntry{SHA256: hash, IsDir: isDir, Commit: commit, StoredAt: time.Now()}
+		return c.writeIndex(sourceAlias, assetID, version, entry)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return objPath, hash, nil
+}
+
+// placeObject moves staged content whose digest is hash into the object
+// store, unless a verified object with that digest is already there, then
+// runs index to point index entries at it. Both happen under the object
+// lock, so Prune never sees the object without the entries.
+func (c *CacheProvider) placeObject(stagedPath, hash string, index func() error) (string, error) {
+	lock, err := c.lockObject(hash)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock()
+
+	objPath, ok := c.Object(hash)
+	if !ok {
+		if err := c.ensureRoot(); err != nil {
+			return "", err
+		}
+		objPath = c.ObjectPath(hash)
+		if err := os.MkdirAll(filepath.Dir(objPath), 0755); err != nil {
+			return "", err
+		}
+		if err := os.Rename(stagedPath, objPath); err != nil {
+			return "", fmt.Errorf("failed to store object %s: %w", hash, err)
+		}
+		if err := makeReadOnly(objPath); err != nil {
+			return "", err
+		}
+	}
+	if index != nil {
+		if err := index(); err != nil {
+			return "", err
+		}
+	}
+	return objPath, nil
+}
+
+// Object returns the path of the object with the given digest after verifying
+// its content. Seed caches are consulted first. A corrupted object in the
+// writable cache is removed and reported as missing so that callers fall back
+// to downloading it again.
+func (c *CacheProvider) Object(hash string) (string, bool) {
+	if hash == "" {
+		return "", false
+	}
+	for _, seed := range c.Seeds {
+		if objPath, ok := verifiedObject(seed, hash, false); ok {
+			return objPath, true
+		}
+	}
+	return verifiedObject(c.CacheRoot, hash, true)
+}
+
+// verifiedObject checks the object with the given digest under root. When
+// repair is set, a corrupted object is removed.
+func verifiedObject(root, hash string, repair bool) (string, bool) {
+	objPath := filepath.Join(root, objectsDir, hash)
+	info, err := os.Stat(objPath)
+	if err != nil {
+		return "", false
+	}
+	got, err := hashPath(objPath, info.IsDir())
+	if err != nil || got != hash {
+		if repair {
+			_ = removeObject(objPath)
+		}
+		return "", false
+	}
+	return objPath, true
+}
+
+// Lookup resolves the index pointer of an asset version to a verified object,
+// consulting seed caches before the writable cache.
+func (c *CacheProvider) Lookup(sourceAlias, assetID, version string) (string, IndexEntry, bool) {
+	roots := append(append([]string{}, c.Seeds...), c.CacheRoot)
+	for _, root := range roots {
+		data, err := os.ReadFile(filepath.Join(root, indexDir, sourceAlias, assetID, version+".json"))
+		if err != nil {
+			continue
+		}
+		var entry IndexEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		if objPath, ok := c.Object(entry.SHA256); ok {
+			return objPath, entry, true
+		}
+	}
+	return "", IndexEntry{}, false
+}
+
+func (c *CacheProvider) writeIndex(sourceAlias, assetID, version string, entry IndexEntry) error {
+	if err := c.ensureRoot(); err != nil {
+		return err
+	}
+	path := c.IndexPath(sourceAlias, assetID, version)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0644)
+}
+
+// Clear removes everything from the cache. It refuses roots that do not carry
+// the cache marker.
 func (c *CacheProvider) Clear() error {
+	if _, err := os.Stat(c.CacheRoot); os.IsNotExist(err) {
+		return nil
+	}
+	if !c.isMarked() {
+		return fmt.Errorf("%s does not look like an arca cache (no %s); refusing to remove it", c.CacheRoot, markerFile)
+	}
+	if err := makeWritable(filepath.Join(c.CacheRoot, objectsDir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 	return os.RemoveAll(c.CacheRoot)
 }
+
+func hashPath(path string, isDir bool) (string, error) {
+	if isDir {
+		return hasher.HashDir(path)
+	}
+	return hasher.HashFile(path)
+}
+
+// makeReadOnly strips write permission from every file of an object so that
+// edits through a projected symlink cannot silently alter cached content.
+// Other permission bits are kept, so that scripts in skills stay executable.
+func makeReadOnly(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return os.Chmod(path, info.Mode().Perm()&^0222)
+	})
+}
+
+// makeWritable restores write permission so that objects can be removed on
+// platforms that refuse to delete read-only files.
+func makeWritable(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return os.Chmod(path, info.Mode().Perm()|0200)
+	})
+}
+
+func removeObject(objPath string) error {
+	if err := makeWritable(objPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(objPath)
+}
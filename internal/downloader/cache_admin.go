@@ -0,0 +1,490 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/adryledo/arca-cli/internal/fsutil"
+)
+
+// workspacesFile lists the workspaces that have used this cache.
+const workspacesFile = "workspaces.json"
+
+// CacheEntry describes one indexed asset version and the object it points to.
+type CacheEntry struct {
+	Source   string    `json:"source"`
+	ID       string    `json:"id"`
+	Version  string    `json:"version"`
+	SHA256   string    `json:"sha256"`
+	IsDir    bool      `json:"isDir"`
+	Commit   string    `json:"commit"`
+	StoredAt time.Time `json:"storedAt"`
+	Size     int64     `json:"size"`
+	Missing  bool      `json:"missing,omitempty"`
+}
+
+// Entries lists every index pointer in the cache, sorted by source, id and version.
+func (c *CacheProvider) Entries() ([]CacheEntry, error) {
+	root := filepath.Join(c.CacheRoot, indexDir)
+	var entries []CacheEntry
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 3 {
+			return nil
+		}
+
+		var idx IndexEntry
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return nil
+		}
+
+		entry := CacheEntry{
+			Source:   parts[0],
+			ID:       parts[1],
+			Version:  strings.TrimSuffix(parts[2], ".json"),
+			SHA256:   idx.SHA256,
+			IsDir:    idx.IsDir,
+			Commit:   idx.Commit,
+			StoredAt: idx.StoredAt,
+		}
+		size, err := pathSize(c.ObjectPath(idx.SHA256))
+		if err != nil {
+			entry.Missing = true
+		}
+		entry.Size = size
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Version < b.Version
+	})
+	return entries, nil
+}
+
+// ObjectCheck is the verification result of one object.
+type ObjectCheck struct {
+	SHA256  string `json:"sha256"`
+	OK      bool   `json:"ok"`
+	Problem string `json:"problem,omitempty"`
+}
+
+// Verify re-hashes every object and checks that every index pointer resolves.
+// Unlike Object, it only reports problems and never removes anything.
+func (c *CacheProvider) Verify() ([]ObjectCheck, error) {
+	hashes, err := c.objectHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []ObjectCheck
+	for _, hash := range hashes {
+		check := ObjectCheck{SHA256: hash, OK: true}
+		objPath := c.ObjectPath(hash)
+		info, err := os.Stat(objPath)
+		if err != nil {
+			check.OK, check.Problem = false, err.Error()
+			checks = append(checks, check)
+			continue
+		}
+		got, err := hashPath(objPath, info.IsDir())
+		switch {
+		case err != nil:
+			check.OK, check.Problem = false, fmt.Sprintf("failed to hash: %v", err)
+		case got != hash:
+			check.OK, check.Problem = false, fmt.Sprintf("content hashes to %s", got)
+		}
+		checks = append(checks, check)
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Missing {
+			checks = append(checks, ObjectCheck{
+				SHA256:  e.SHA256,
+				Problem: fmt.Sprintf("object for %s/%s@%s is missing", e.Source, e.ID, e.Version),
+			})
+		}
+	}
+	return checks, nil
+}
+
+// RegisterWorkspace records a workspace root so that Prune can find its lockfile.
+func (c *CacheProvider) RegisterWorkspace(root string) error {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	lock, err := fsutil.Lock(filepath.Join(c.CacheRoot, locksDir, workspacesFile+".lock"))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	workspaces, err := c.Workspaces()
+	if err != nil {
+		return err
+	}
+	for _, ws := range workspaces {
+		if ws == abs {
+			return nil
+		}
+	}
+	return c.saveWorkspaces(append(workspaces, abs))
+}
+
+// Workspaces returns the workspace roots that have used this cache.
+func (c *CacheProvider) Workspaces() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(c.CacheRoot, workspacesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	var workspaces []string
+	if err := json.Unmarshal(data, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace registry: %w", err)
+	}
+	return workspaces, nil
+}
+
+func (c *CacheProvider) saveWorkspaces(workspaces []string) error {
+	sort.Strings(workspaces)
+	data, err := json.MarshalIndent(workspaces, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := c.ensureRoot(); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(c.CacheRoot, workspacesFile), data, 0644)
+}
+
+// ForgetWorkspaces removes workspace roots from the registry.
+func (c *CacheProvider) ForgetWorkspaces(roots []string) error {
+	lock, err := fsutil.Lock(filepath.Join(c.CacheRoot, locksDir, workspacesFile+".lock"))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	workspaces, err := c.Workspaces()
+	if err != nil {
+		return err
+	}
+	forget := make(map[string]bool, len(roots))
+	for _, r := range roots {
+		forget[r] = true
+	}
+	kept := []string{}
+	for _, ws := range workspaces {
+		if !forget[ws] {
+			kept = append(kept, ws)
+		}
+	}
+	return c.saveWorkspaces(kept)
+}
+
+// PruneOptions controls which cache content Prune removes.
+type PruneOptions struct {
+	// Referenced holds the digests still needed by known lockfiles.
+	Referenced map[string]bool
+	// OlderThan, when positive, also removes entries stored longer ago than this.
+	OlderThan time.Duration
+	// DryRun reports what would be removed without touching the cache.
+	DryRun bool
+	// Legacy also removes asset version directories left by the former
+	// source/id/version layout.
+	Legacy bool
+}
+
+// PruneResult reports what Prune removed.
+type PruneResult struct {
+	Entries    []CacheEntry `json:"entries"`
+	Objects    []string     `json:"objects"`
+	Legacy     []string     `json:"legacy"`
+	FreedBytes int64        `json:"freedBytes"`
+}
+
+// Prune removes index entries that are unreferenced or too old, then every
+// object no longer pointed to by an entry or a referenced digest. With
+// opts.Legacy, asset versions left over from the pre content-addressed layout
+// are removed as well; nothing else outside the cache layout is touched.
+func (c *CacheProvider) Prune(opts PruneOptions) (PruneResult, error) {
+	result := PruneResult{Entries: []CacheEntry{}, Objects: []string{}, Legacy: []string{}}
+
+	started := time.Now()
+	entries, err := c.Entries()
+	if err != nil {
+		return result, err
+	}
+
+	keep := make(map[string]bool)
+	expired := make(map[string]bool)
+	for _, e := range entries {
+		tooOld := opts.OlderThan > 0 && time.Since(e.StoredAt) > opts.OlderThan
+		if opts.Referenced[e.SHA256] && !tooOld && !e.Missing {
+			keep[e.SHA256] = true
+			continue
+		}
+		if tooOld {
+			expired[e.SHA256] = true
+		}
+		result.Entries = append(result.Entries, e)
+		if opts.DryRun {
+			continue
+		}
+		if err := c.removeEntry(e); err != nil {
+			return result, err
+		}
+	}
+	// Referenced objects without an index entry (e.g. imported ones) survive
+	// unless their only entries just expired.
+	for h := range opts.Referenced {
+		if !expired[h] {
+			keep[h] = true
+		}
+	}
+
+	hashes, err := c.objectHashes()
+	if err != nil {
+		return result, err
+	}
+	for _, hash := range hashes {
+		if keep[hash] {
+			continue
+		}
+		size, _ := pathSize(c.ObjectPath(hash))
+		if !opts.DryRun {
+			removed, err := c.removeUnindexedObject(hash, started)
+			if err != nil {
+				return result, err
+			}
+			if !removed {
+				continue
+			}
+		}
+		result.Objects = append(result.Objects, hash)
+		result.FreedBytes += size
+	}
+
+	if !opts.Legacy {
+		return result, nil
+	}
+	if !c.isMarked() {
+		return result, fmt.Errorf("%s does not look like an arca cache (no %s); refusing to migrate it", c.CacheRoot, markerFile)
+	}
+	legacy, err := c.legacyDirs()
+	if err != nil {
+		return result, err
+	}
+	for _, dir := range legacy {
+		path := filepath.Join(c.CacheRoot, dir)
+		size, _ := pathSize(path)
+		result.Legacy = append(result.Legacy, filepath.ToSlash(dir))
+		result.FreedBytes += size
+		if opts.DryRun {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return result, err
+		}
+		// Drop the id and source directories once they are empty
+		os.Remove(filepath.Dir(path))
+		os.Remove(filepath.Dir(filepath.Dir(path)))
+	}
+
+	return result, nil
+}
+
+func (c *CacheProvider) removeEntry(e CacheEntry) error {
+	lock, err := c.LockEntry(context.Background(), e.Source, e.ID, e.Version)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	path := c.IndexPath(e.Source, e.ID, e.Version)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// Drop empty asset and source directories
+	for dir := filepath.Dir(path); dir != filepath.Join(c.CacheRoot, indexDir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// removeUnindexedObject removes an object Prune found unreferenced, unless
+// an index entry written since the prune started points at it: a concurrent
+// Store may have placed or reused the object after Prune listed the index.
+// Store writes its entry under the object lock, so checking under that lock
+// sees it.
+func (c *CacheProvider) removeUnindexedObject(hash string, since time.Time) (bool, error) {
+	lock, err := c.lockObject(hash)
+	if err != nil {
+		return false, err
+	}
+	defer lock.Unlock()
+
+	indexed, err := c.indexedSince(hash, since)
+	if err != nil || indexed {
+		return false, err
+	}
+	return true, removeObject(c.ObjectPath(hash))
+}
+
+// indexedSince reports whether an index entry modified at or after since
+// points at hash. Since is moved back a second to allow for file systems
+// with coarse modification times.
+func (c *CacheProvider) indexedSince(hash string, since time.Time) (bool, error) {
+	since = since.Add(-time.Second)
+	found := false
+	err := filepath.WalkDir(filepath.Join(c.CacheRoot, indexDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if found || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+		var idx IndexEntry
+		data, err := os.ReadFile(path)
+		if err != nil || json.Unmarshal(data, &idx) != nil {
+			return nil
+		}
+		found = idx.SHA256 == hash
+		return nil
+	})
+	return found, err
+}
+
+// objectHashes lists the digests of all stored objects.
+func (c *CacheProvider) objectHashes() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(c.CacheRoot, objectsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	hashes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		hashes = append(hashes, e.Name())
+	}
+	return hashes, nil
+}
+
+// legacyDirs lists the <source>/<id>/<version> directories written by the
+// former cache layout, relative to the cache root. Only directories of that
+// exact shape qualify: the version must be a semantic version and the
+// directory must hold the asset as <id>.md or a skill's SKILL.md.
+func (c *CacheProvider) legacyDirs() ([]string, error) {
+	sources, err := os.ReadDir(c.CacheRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	known := map[string]bool{objectsDir: true, indexDir: true, stagingDir: true, locksDir: true, sourcesDir: true, manifestsDir: true}
+	var dirs []string
+	for _, src := range sources {
+		if !src.IsDir() || known[src.Name()] {
+			continue
+		}
+		ids, _ := os.ReadDir(filepath.Join(c.CacheRoot, src.Name()))
+		for _, id := range ids {
+			if !id.IsDir() {
+				continue
+			}
+			versions, _ := os.ReadDir(filepath.Join(c.CacheRoot, src.Name(), id.Name()))
+			for _, v := range versions {
+				if !v.IsDir() {
+					continue
+				}
+				if _, err := semver.NewVersion(v.Name()); err != nil {
+					continue
+				}
+				dir := filepath.Join(src.Name(), id.Name(), v.Name())
+				if isLegacyAsset(filepath.Join(c.CacheRoot, dir), id.Name()) {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// isLegacyAsset reports whether dir holds an asset the way the former layout
+// stored it.
+func isLegacyAsset(dir, id string) bool {
+	for _, name := range []string{id + ".md", "SKILL.md"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSize returns the total size in bytes of a file or directory tree.
+func pathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,207 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func storeTestAsset(t *testing.T, cp *CacheProvider, source, id, version, content string) string {
+	t.Helper()
+	staging, err := cp.NewStaging()
+	if err != nil {
+		t.Fatalf("NewStaging failed: %v", err)
+	}
+	defer staging.Close()
+
+	path := filepath.Join(staging.Dir, id+".md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write staged file: %v", err)
+	}
+	_, hash, err := cp.Store(source, id, version, path, false, "abc")
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	return hash
+}
+
+func TestCacheProvider_EntriesAndVerify(t *testing.T) {
+	cp := NewCacheProvider(t.TempDir())
+	storeTestAsset(t, cp, "org", "rules", "1.0.0", "rules v1")
+	hash := storeTestAsset(t, cp, "org", "rules", "2.0.0", "rules v2")
+
+	entries, err := cp.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Version != "1.0.0" || entries[1].Size != int64(len("rules v2")) {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+
+	checks, err := cp.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	for _, c := range checks {
+		if !c.OK {
+			t.Errorf("Expected healthy cache, got problem %s", c.Problem)
+		}
+	}
+
+	// Corrupt one object
+	objPath := cp.ObjectPath(hash)
+	if err := os.Chmod(objPath, 0644); err != nil {
+		t.Fatalf("failed to chmod object: %v", err)
+	}
+	if err := os.WriteFile(objPath, []byte("tampered"), 0644); err != nil {
+		t.Fatalf("failed to tamper object: %v", err)
+	}
+
+	checks, err = cp.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	failed := 0
+	for _, c := range checks {
+		if !c.OK {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 corrupted object, got %d", failed)
+	}
+	if _, err := os.Stat(objPath); err != nil {
+		t.Errorf("Verify must not remove objects: %v", err)
+	}
+}
+
+func TestCacheProvider_Prune(t *testing.T) {
+	cp := NewCacheProvider(t.TempDir())
+	kept := storeTestAsset(t, cp, "org", "rules", "1.0.0", "rules v1")
+	dropped := storeTestAsset(t, cp, "org", "rules", "0.9.0", "rules v0")
+
+	// Leftover asset version from the former layout, next to unrelated data
+	legacy := filepath.Join(cp.CacheRoot, "org", "rules", "0.1.0")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatalf("failed to create legacy dir: %v", err)
+	}
+	os.WriteFile(filepath.Join(legacy, "rules.md"), []byte("rules v0.1"), 0644)
+	unrelated := filepath.Join(cp.CacheRoot, "photos", "2026", "summer")
+	if err := os.MkdirAll(unrelated, 0755); err != nil {
+		t.Fatalf("failed to create unrelated dir: %v", err)
+	}
+
+	opts := PruneOptions{Referenced: map[string]bool{kept: true}, DryRun: true, Legacy: true}
+	result, err := cp.Prune(opts)
+	if err != nil {
+		t.Fatalf("Prune (dry run) failed: %v", err)
+	}
+	if len(result.Entries) != 1 || len(result.Objects) != 1 || len(result.Legacy) != 1 {
+		t.Fatalf("Unexpected dry-run result: %+v", result)
+	}
+	if _, err := os.Stat(cp.ObjectPath(dropped)); err != nil {
+		t.Errorf("Dry run must not remove objects")
+	}
+
+	opts.DryRun = false
+	if _, err := cp.Prune(opts); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := os.Stat(cp.ObjectPath(dropped)); !os.IsNotExist(err) {
+		t.Errorf("Expected unreferenced object to be removed")
+	}
+	if _, err := os.Stat(cp.ObjectPath(kept)); err != nil {
+		t.Errorf("Expected referenced object to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cp.CacheRoot, "org")); !os.IsNotExist(err) {
+		t.Errorf("Expected legacy directory to be removed")
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("Expected unknown directories to be kept: %v", err)
+	}
+
+	// Entries older than the cut-off go even when referenced
+	result, err = cp.Prune(PruneOptions{Referenced: map[string]bool{kept: true}, OlderThan: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(result.Entries) != 1 || len(result.Objects) != 1 {
+		t.Errorf("Expected expired entry and object to be removed, got %+v", result)
+	}
+}
+
+func TestCacheProvider_PruneConcurrentStore(t *testing.T) {
+	cp := NewCacheProvider(t.TempDir())
+
+	// A Store that lands between the index snapshot of Prune and the removal
+	// of objects leaves an entry newer than the start of the prune
+	started := time.Now()
+	hash := storeTestAsset(t, cp, "org", "rules", "1.0.0", "rules v1")
+	removed, err := cp.removeUnindexedObject(hash, started)
+	if err != nil {
+		t.Fatalf("removeUnindexedObject failed: %v", err)
+	}
+	if removed {
+		t.Error("Expected an object indexed during the prune to be kept")
+	}
+	if _, err := os.Stat(cp.ObjectPath(hash)); err != nil {
+		t.Errorf("Expected the object to survive: %v", err)
+	}
+
+	// Entries that predate the prune were in its snapshot
+	removed, err = cp.removeUnindexedObject(hash, time.Now().Add(time.Hour))
+	if err != nil || !removed {
+		t.Errorf("Expected the object to be removed, got %v (%v)", removed, err)
+	}
+}
+
+func TestCacheProvider_PruneLegacyOptIn(t *testing.T) {
+	cp := NewCacheProvider(t.TempDir())
+	legacy := filepath.Join(cp.CacheRoot, "org", "rules", "0.1.0")
+	os.MkdirAll(legacy, 0755)
+	os.WriteFile(filepath.Join(legacy, "rules.md"), []byte("rules v0.1"), 0644)
+
+	// Without the flag the former layout is left alone
+	result, err := cp.Prune(PruneOptions{})
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(result.Legacy) != 0 {
+		t.Errorf("Expected no legacy removal without opts.Legacy, got %v", result.Legacy)
+	}
+
+	// A root without the cache marker is never migrated
+	if _, err := cp.Prune(PruneOptions{Legacy: true}); err == nil {
+		t.Error("Expected an error for a root without the cache marker")
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("Expected the directory to survive: %v", err)
+	}
+}
+
+func TestCacheProvider_Workspaces(t *testing.T) {
+	cp := NewCacheProvider(t.TempDir())
+	ws := t.TempDir()
+
+	if err := cp.RegisterWorkspace(ws); err != nil {
+		t.Fatalf("RegisterWorkspace failed: %v", err)
+	}
+	if err := cp.RegisterWorkspace(ws); err != nil {
+		t.Fatalf("RegisterWorkspace failed: %v", err)
+	}
+	workspaces, err := cp.Workspaces()
+	if err != nil || len(workspaces) != 1 {
+		t.Fatalf("Expected 1 workspace, got %v (%v)", workspaces, err)
+	}
+
+	if err := cp.ForgetWorkspaces(workspaces); err != nil {
+		t.Fatalf("ForgetWorkspaces failed: %v", err)
+	}
+	if workspaces, _ := cp.Workspaces(); len(workspaces) != 0 {
+		t.Errorf("Expected no workspaces, got %v", workspaces)
+	}
+}
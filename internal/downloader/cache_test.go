@@ -3,7 +3,9 @@ package downloader
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestCacheProvider(t *testing.T) {
@@ -47,7 +49,17 @@ func TestCacheProvider(t *testing.T) {
 		t.Errorf("EnsureDir did not create directory")
 	}
 
-	// Clear
+	// Clear refuses roots that are not tagged as a cache
+	if err := cp.Clear(); err == nil {
+		t.Fatal("Expected Clear to refuse a root without the cache marker")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("Clear must not remove an untagged root: %v", err)
+	}
+
+	if err := cp.ensureRoot(); err != nil {
+		t.Fatalf("ensureRoot failed: %v", err)
+	}
 	if err := cp.Clear(); err != nil {
 		t.Fatalf("Clear() failed: %v", err)
 	}
@@ -56,3 +68,201 @@ func TestCacheProvider(t *testing.T) {
 		t.Errorf("Clear did not remove cache root")
 	}
 }
+
+func TestCacheProvider_ContentAddressed(t *testing.T) {
+	cp := NewCacheProvider(t.TempDir())
+
+	stage := func(content string) string {
+		t.Helper()
+		staging, err := cp.NewStaging()
+		if err != nil {
+			t.Fatalf("NewStaging failed: %v", err)
+		}
+		t.Cleanup(func() { staging.Close() })
+		path := filepath.Join(staging.Dir, "asset.md")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write staged file: %v", err)
+		}
+		return path
+	}
+
+	// Same content under two aliases is stored once
+	objA, hashA, err := cp.Store("alias-a", "rules", "1.0.0", stage("same content"), false, "abc")
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	objB, hashB, err := cp.Store("alias-b", "rules", "1.0.0", stage("same content"), false, "abc")
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if hashA != hashB || objA != objB {
+		t.Errorf("Expected identical objects, got %s and %s", objA, objB)
+	}
+	if objA != cp.ObjectPath(hashA) {
+		t.Errorf("Expected object at %s, got %s", cp.ObjectPath(hashA), objA)
+	}
+
+	// Index pointers resolve to the object
+	path, entry, ok := cp.Lookup("alias-b", "rules", "1.0.0")
+	if !ok {
+		t.Fatalf("Lookup did not find stored entry")
+	}
+	if path != objA || entry.SHA256 != hashA || entry.Commit != "abc" {
+		t.Errorf("Unexpected lookup result: %s %+v", path, entry)
+	}
+
+	// A mutated version gets a new object and leaves the old one intact
+	objC, hashC, err := cp.Store("alias-a", "rules", "1.0.0", stage("mutated content"), false, "def")
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if hashC == hashA {
+		t.Fatalf("Expected a different digest for different content")
+	}
+	if content, err := os.ReadFile(objA); err != nil || string(content) != "same content" {
+		t.Errorf("Original object was modified: %q (%v)", string(content), err)
+	}
+	if path, _, _ := cp.Lookup("alias-a", "rules", "1.0.0"); path != objC {
+		t.Errorf("Expected index to point at %s, got %s", objC, path)
+	}
+
+	// Corrupted objects are rejected and removed on read
+	if err := os.Chmod(objC, 0644); err != nil {
+		t.Fatalf("failed to chmod object: %v", err)
+	}
+	if err := os.WriteFile(objC, []byte("tampered"), 0644); err != nil {
+		t.Fatalf("failed to tamper object: %v", err)
+	}
+	if _, ok := cp.Object(hashC); ok {
+		t.Errorf("Expected corrupted object to fail verification")
+	}
+	if _, err := os.Stat(objC); !os.IsNotExist(err) {
+		t.Errorf("Expected corrupted object to be removed")
+	}
+}
+
+func TestCacheProvider_ReadOnlyKeepsExecBits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no exec bits on windows")
+	}
+	cp := NewCacheProvider(t.TempDir())
+	staging, err := cp.NewStaging()
+	if err != nil {
+		t.Fatalf("NewStaging failed: %v", err)
+	}
+	t.Cleanup(func() { staging.Close() })
+	skill := filepath.Join(staging.Dir, "skill")
+	os.MkdirAll(filepath.Join(skill, "scripts"), 0755)
+	os.WriteFile(filepath.Join(skill, "SKILL.md"), []byte("# Skill\n"), 0644)
+	os.WriteFile(filepath.Join(skill, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0755)
+
+	obj, _, err := cp.Store("team", "skill", "1.0.0", skill, true, "abc")
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	for name, want := range map[string]os.FileMode{"SKILL.md": 0444, "scripts/run.sh": 0555} {
+		info, err := os.Stat(filepath.Join(obj, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("Expected %s to have mode %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestCacheProvider_RecoverStaging(t *testing.T) {
+	cp := NewCacheProvider(t.TempDir())
+
+	// Simulate a staging area left behind by an interrupted run
+	abandoned, err := cp.NewStaging()
+	if err != nil {
+		t.Fatalf("NewStaging failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(abandoned.Dir, "partial.md"), []byte("half"), 0644); err != nil {
+		t.Fatalf("failed to write partial file: %v", err)
+	}
+	abandoned.lock.Unlock()
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(abandoned.Dir+".lock", old, old); err != nil {
+		t.Fatalf("failed to age lock file: %v", err)
+	}
+
+	// A staging area still in use must survive recovery
+	active, err := cp.NewStaging()
+	if err != nil {
+		t.Fatalf("NewStaging failed: %v", err)
+	}
+	defer active.Close()
+	if err := os.Chtimes(active.Dir+".lock", old, old); err != nil {
+		t.Fatalf("failed to age lock file: %v", err)
+	}
+
+	cp.RecoverStaging()
+
+	if _, err := os.Stat(abandoned.Dir); !os.IsNotExist(err) {
+		t.Errorf("Expected abandoned staging area to be removed")
+	}
+	if _, err := os.Stat(active.Dir); err != nil {
+		t.Errorf("Expected active staging area to be kept: %v", err)
+	}
+}
+
+func TestCacheProvider_Seeds(t *testing.T) {
+	seed := NewCacheProvider(t.TempDir())
+	staging, err := seed.NewStaging()
+	if err != nil {
+		t.Fatalf("NewStaging failed: %v", err)
+	}
+	defer staging.Close()
+	staged := filepath.Join(staging.Dir, "rules.md")
+	if err := os.WriteFile(staged, []byte("seeded"), 0644); err != nil {
+		t.Fatalf("failed to write staged file: %v", err)
+	}
+	seedObj, hash, err := seed.Store("org", "rules", "1.0.0", staged, false, "abc")
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	cp := NewCacheProvider(t.TempDir())
+	cp.Seeds = []string{seed.CacheRoot}
+
+	// Seed objects and index entries are visible through the writable cache
+	if path, ok := cp.Object(hash); !ok || path != seedObj {
+		t.Errorf("Expected seed object %s, got %s (%v)", seedObj, path, ok)
+	}
+	if path, _, ok := cp.Lookup("org", "rules", "1.0.0"); !ok || path != seedObj {
+		t.Errorf("Expected lookup through seed, got %s (%v)", path, ok)
+	}
+
+	// Storing content already present in a seed only writes the index pointer
+	staging2, err := cp.NewStaging()
+	if err != nil {
+		t.Fatalf("NewStaging failed: %v", err)
+	}
+	defer staging2.Close()
+	staged2 := filepath.Join(staging2.Dir, "rules.md")
+	if err := os.WriteFile(staged2, []byte("seeded"), 0644); err != nil {
+		t.Fatalf("failed to write staged file: %v", err)
+	}
+	if path, _, err := cp.Store("alias", "rules", "1.0.0", staged2, false, "abc"); err != nil || path != seedObj {
+		t.Errorf("Expected Store to reuse seed object, got %s (%v)", path, err)
+	}
+	if _, err := os.Stat(cp.ObjectPath(hash)); !os.IsNotExist(err) {
+		t.Errorf("Expected no copy of the seed object in the writable cache")
+	}
+
+	// Corrupted seed objects are skipped but never removed
+	if err := os.Chmod(seedObj, 0644); err != nil {
+		t.Fatalf("failed to chmod object: %v", err)
+	}
+	if err := os.WriteFile(seedObj, []byte("tampered"), 0644); err != nil {
+		t.Fatalf("failed to tamper object: %v", err)
+	}
+	if _, ok := cp.Object(hash); ok {
+		t.Errorf("Expected corrupted seed object to be rejected")
+	}
+	if _, err := os.Stat(seedObj); err != nil {
+		t.Errorf("Seed objects must not be removed: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/fsutil"
+	"github.com/adryledo/arca-cli/internal/hasher"
+)
+
+// manifestsDir holds source manifests by source and ref so that locked
+// revisions resolve without network access.
+const manifestsDir = "manifests"
+
+// manifestPath returns where the manifest of source at ref is kept under root.
+func manifestPath(root, source, ref string) string {
+	name := strings.NewReplacer(":", "-", "/", "-", "\\", "-").Replace(ref)
+	return filepath.Join(root, manifestsDir, hasher.HashString(source), name+".yaml")
+}
+
+// Manifest returns the cached manifest of source (its URL or path) at ref,
+// consulting seed caches first.
+func (c *CacheProvider) Manifest(source, ref string) ([]byte, bool) {
+	roots := append(append([]string{}, c.Seeds...), c.CacheRoot)
+	for _, root := range roots {
+		if data, err := os.ReadFile(manifestPath(root, source, ref)); err == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// StoreManifest keeps the manifest of source at ref. Only refs that never
+// move, such as commits, should be stored.
+func (c *CacheProvider) StoreManifest(source, ref string, data []byte) error {
+	if err := c.ensureRoot(); err != nil {
+		return err
+	}
+	path := manifestPath(c.CacheRoot, source, ref)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,57 @@
+// Package fsutil provides small filesystem helpers shared by the ARCA commands.
+package fsutil
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CopyFile copies a single file from src to dst, creating parent directories as needed.
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CopyDir recursively copies the directory tree at src into dst.
+func CopyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return CopyFile(path, target)
+	})
+}
+
+// CopyPath copies src to dst as a directory tree or a single file.
+func CopyPath(src, dst string, isDir bool) error {
+	if isDir {
+		return CopyDir(src, dst)
+	}
+	return CopyFile(src, dst)
+}
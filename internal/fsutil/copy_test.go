@@ -0,0 +1,50 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyDir(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "copy")
+
+	if err := os.MkdirAll(filepath.Join(src, "scripts"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "SKILL.md"), []byte("skill"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "scripts", "run.py"), []byte("print()"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := CopyPath(src, dst, true); err != nil {
+		t.Fatalf("CopyPath failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dst, "scripts", "run.py"))
+	if err != nil {
+		t.Fatalf("copied file missing: %v", err)
+	}
+	if string(content) != "print()" {
+		t.Errorf("Expected 'print()', got '%s'", string(content))
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "a.md")
+	dst := filepath.Join(t.TempDir(), "nested", "b.md")
+
+	if err := os.WriteFile(src, []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := CopyPath(src, dst, false); err != nil {
+		t.Fatalf("CopyPath failed: %v", err)
+	}
+	content, err := os.ReadFile(dst)
+	if err != nil || string(content) != "hello" {
+		t.Errorf("Expected 'hello', got '%s' (%v)", string(content), err)
+	}
+}
@@ -0,0 +1,80 @@
+package fsutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned by TryLock when another process holds the lock.
+var ErrLocked = errors.New("file is locked by another process")
+
+// FileLock is an exclusive advisory lock held on a lock file. It protects
+// shared state across processes, e.g. two sync runs writing the same cache
+// entry or the VS Code extension and a terminal updating the same lockfile.
+type FileLock struct {
+	f *os.File
+}
+
+// Lock blocks until it holds an exclusive lock on the file at path.
+// The file and its parent directories are created if needed.
+func Lock(path string) (*FileLock, error) {
+	return acquire(path, true)
+}
+
+// TryLock is like Lock but returns ErrLocked instead of waiting.
+func TryLock(path string) (*FileLock, error) {
+	return acquire(path, false)
+}
+
+// LockContext is like Lock but gives up with ctx's error once ctx is done.
+// It polls the lock with a growing delay, since a blocking lock cannot be
+// interrupted.
+func LockContext(ctx context.Context, path string) (*FileLock, error) {
+	delay := 10 * time.Millisecond
+	for {
+		lock, err := TryLock(path)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay < time.Second {
+			delay *= 2
+		}
+	}
+}
+
+func acquire(path string, block bool) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, block); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileLock{f: f}, nil
+}
+
+// Unlock releases the lock. The lock file itself is left in place because
+// removing it would race with processes waiting on it.
+func (l *FileLock) Unlock() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
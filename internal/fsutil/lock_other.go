@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package fsutil
+
+import "os"
+
+// Platforms without advisory file locking fall back to no locking; atomic
+// writes still prevent torn files there.
+func lockFile(f *os.File, block bool) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
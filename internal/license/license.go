@@ -0,0 +1,207 @@
+// Package license checks the SPDX license expressions declared by assets
+// against the allow and deny lists of a project.
+package license
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+// Check reports whether an asset declaring the SPDX license expression expr
+// may be installed under policy. "MIT OR GPL-3.0" passes when either license
+// is acceptable, "MIT AND CC-BY-4.0" only when both are; an exception added
+// with WITH is judged by the license it modifies. An empty expression is an
+// unknown license.
+func Check(policy *models.LicensePolicy, expr string) error {
+	if policy == nil || (len(policy.Allow) == 0 && len(policy.Deny) == 0) {
+		return nil
+	}
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		if len(policy.Allow) > 0 && !policy.AllowUnknown {
+			return fmt.Errorf("no license declared and licenses.allow is set")
+		}
+		return nil
+	}
+
+	node, err := parse(expr)
+	if err != nil {
+		return err
+	}
+
+	if node.eval(func(id string) bool { return accepted(policy, id) }) {
+		return nil
+	}
+	for _, id := range node.ids() {
+		if !contains(policy.Deny, id) {
+			continue
+		}
+		if id == expr {
+			return fmt.Errorf("license %s is denied", expr)
+		}
+		return fmt.Errorf("license %s is denied (%s)", expr, id)
+	}
+	return fmt.Errorf("license %s is not in licenses.allow", expr)
+}
+
+// Validate reports whether expr is a well-formed SPDX license expression:
+// license identifiers of letters, digits, dots and dashes, optionally
+// followed by + or WITH an exception, joined by AND, OR and parentheses.
+func Validate(expr string) error {
+	_, err := parse(strings.TrimSpace(expr))
+	return err
+}
+
+func parse(expr string) (node, error) {
+	p := &parser{tokens: tokenize(expr)}
+	n, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return node{}, fmt.Errorf("invalid license expression %q: %w", expr, err)
+	}
+	return n, nil
+}
+
+func accepted(policy *models.LicensePolicy, id string) bool {
+	if contains(policy.Deny, id) {
+		return false
+	}
+	return len(policy.Allow) == 0 || contains(policy.Allow, id)
+}
+
+func contains(list []string, id string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits an expression into identifiers, operators and parentheses.
+func tokenize(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)
+	return strings.Fields(expr)
+}
+
+// node is a license identifier (id set) or an AND/OR of its operands.
+type node struct {
+	id       string
+	op       string
+	operands []node
+}
+
+func (n node) eval(ok func(string) bool) bool {
+	switch n.op {
+	case "AND":
+		for _, o := range n.operands {
+			if !o.eval(ok) {
+				return false
+			}
+		}
+		return true
+	case "OR":
+		for _, o := range n.operands {
+			if o.eval(ok) {
+				return true
+			}
+		}
+		return false
+	}
+	return ok(n.id)
+}
+
+func (n node) ids() []string {
+	if n.op == "" {
+		return []string{n.id}
+	}
+	var ids []string
+	for _, o := range n.operands {
+		ids = append(ids, o.ids()...)
+	}
+	return ids
+}
+
+// idPattern matches a license or exception identifier, including
+// LicenseRef- identifiers defined in another document.
+var idPattern = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.-]+:)?[A-Za-z0-9.-]+\+?$`)
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseOp("OR", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseOp("AND", p.parseTerm)
+}
+
+func (p *parser) parseOp(op string, operand func() (node, error)) (node, error) {
+	first, err := operand()
+	if err != nil {
+		return node{}, err
+	}
+	operands := []node{first}
+	for strings.EqualFold(p.peek(), op) {
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return node{}, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return node{op: op, operands: operands}, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return node{}, fmt.Errorf("unexpected end of expression")
+	case tok == "(":
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return node{}, err
+		}
+		if p.peek() != ")" {
+			return node{}, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return n, nil
+	case tok == ")" || strings.EqualFold(tok, "AND") || strings.EqualFold(tok, "OR") || strings.EqualFold(tok, "WITH"):
+		return node{}, fmt.Errorf("unexpected %q", tok)
+	}
+	if !idPattern.MatchString(tok) {
+		return node{}, fmt.Errorf("invalid license identifier %q", tok)
+	}
+	p.pos++
+	if strings.EqualFold(p.peek(), "WITH") {
+		p.pos++
+		if exc := p.peek(); exc == "" || exc == "(" || exc == ")" {
+			return node{}, fmt.Errorf("missing exception after WITH")
+		} else if !idPattern.MatchString(exc) {
+			return node{}, fmt.Errorf("invalid exception identifier %q", exc)
+		}
+		p.pos++
+	}
+	return node{id: tok}, nil
+}
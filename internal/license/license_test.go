@@ -0,0 +1,83 @@
+package license
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+func TestCheck(t *testing.T) {
+	allow := &models.LicensePolicy{Allow: []string{"MIT", "Apache-2.0", "CC-BY-4.0"}, Deny: []string{"GPL-3.0-only"}}
+	denyOnly := &models.LicensePolicy{Deny: []string{"GPL-3.0-only", "AGPL-3.0-only"}}
+
+	tests := []struct {
+		name   string
+		policy *models.LicensePolicy
+		expr   string
+		want   string // substring of the error, empty when accepted
+	}{
+		{"no policy", nil, "GPL-3.0-only", ""},
+		{"allowed", allow, "Apache-2.0", ""},
+		{"case insensitive", allow, "apache-2.0", ""},
+		{"not allowed", allow, "BSD-3-Clause", "not in licenses.allow"},
+		{"denied", allow, "GPL-3.0-only", "denied"},
+		{"or with one acceptable", allow, "MIT OR GPL-3.0-only", ""},
+		{"and with one denied", allow, "MIT AND GPL-3.0-only", "denied"},
+		{"and both allowed", allow, "MIT AND CC-BY-4.0", ""},
+		{"parentheses", allow, "(BSD-3-Clause OR MIT) AND Apache-2.0", ""},
+		{"exception", allow, "Apache-2.0 WITH LLVM-exception", ""},
+		{"unknown with allow list", allow, "", "no license declared"},
+		{"unknown accepted", &models.LicensePolicy{Allow: []string{"MIT"}, AllowUnknown: true}, "", ""},
+		{"deny list only", denyOnly, "BSD-3-Clause", ""},
+		{"deny list only denied", denyOnly, "AGPL-3.0-only", "denied"},
+		{"unknown with deny list only", denyOnly, "", ""},
+		{"invalid", allow, "MIT OR", "invalid license expression"},
+		{"unbalanced", allow, "(MIT", "invalid license expression"},
+		{"invalid identifier", allow, "MIT/X11", "invalid license identifier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.policy, tt.expr)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Expected %q to be accepted, got %v", tt.expr, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		expr  string
+		valid bool
+	}{
+		{"MIT", true},
+		{"GPL-2.0+", true},
+		{"(MIT OR Apache-2.0) AND CC-BY-4.0", true},
+		{"Apache-2.0 WITH LLVM-exception", true},
+		{"LicenseRef-Internal", true},
+		{"DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2", true},
+		{"Apache 2.0", false},
+		{"MIT/X11", false},
+		{"See LICENSE file", false},
+		{"MIT OR", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			err := Validate(tt.expr)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be valid, got %v", tt.expr, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Expected %q to be invalid", tt.expr)
+			}
+		})
+	}
+}
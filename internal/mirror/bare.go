@@ -0,0 +1,147 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+	"github.com/adryledo/arca-cli/internal/source"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// pinnedRefPrefix keeps pinned commits that no branch or tag reaches.
+const pinnedRefPrefix = "refs/arca/pinned/"
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// BareGit replicates the git repository at url into a bare repository at
+// dest: every branch and tag, the default branch, and the commits manifest
+// versions are pinned to. Commits keep their hashes, so the mirror serves a
+// hash-identical manifest. src reads the manifest to find the pinned refs.
+func BareGit(ctx context.Context, src source.Source, url, dest string) (*Result, error) {
+	_, versions, err := loadManifest(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	gitAuth, err := auth.ForURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpen(dest)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return nil, err
+		}
+		repo, err = git.PlainInit(dest, true)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", dest, err)
+	}
+
+	_ = repo.DeleteRemote("origin")
+	remote, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}})
+	if err != nil {
+		return nil, err
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: []config.RefSpec{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"},
+		Auth:     gitAuth,
+		Tags:     git.NoTags,
+		Force:    true,
+		Prune:    true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	if err := setHead(ctx, repo, remote, gitAuth); err != nil {
+		return nil, err
+	}
+
+	for _, v := range versions {
+		ref := v.Meta.Ref
+		if ref == "" {
+			continue
+		}
+		if !commitPattern.MatchString(ref) {
+			if _, err := repo.ResolveRevision(plumbing.Revision(ref)); err != nil {
+				return nil, fmt.Errorf("%s@%s: ref %s is not in %s", v.ID, v.Version, ref, url)
+			}
+			continue
+		}
+		if _, err := repo.CommitObject(plumbing.NewHash(ref)); err == nil {
+			continue
+		}
+		// Keep commits no branch or tag reaches anymore under their own ref
+		err := remote.FetchContext(ctx, &git.FetchOptions{
+			RefSpecs: []config.RefSpec{config.RefSpec(ref + ":" + pinnedRefPrefix + ref)},
+			Auth:     gitAuth,
+			Tags:     git.NoTags,
+		})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil, fmt.Errorf("%s@%s: failed to fetch pinned commit %s: %w", v.ID, v.Version, ref, err)
+		}
+	}
+
+	names, err := refNames(repo)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(versions, names), nil
+}
+
+// setHead points HEAD of the mirror at the remote default branch.
+func setHead(ctx context.Context, repo *git.Repository, remote *git.Remote, gitAuth transport.AuthMethod) error {
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: gitAuth})
+	if err != nil {
+		return fmt.Errorf("failed to list refs: %w", err)
+	}
+	var head *plumbing.Reference
+	for _, r := range refs {
+		if r.Name() == plumbing.HEAD {
+			head = r
+			break
+		}
+	}
+	if head == nil {
+		return nil
+	}
+	target := head.Target()
+	if head.Type() != plumbing.SymbolicReference {
+		// Servers without the symref capability only tell the hash
+		for _, r := range refs {
+			if r.Name().IsBranch() && r.Hash() == head.Hash() {
+				target = r.Name()
+				break
+			}
+		}
+	}
+	if target == "" {
+		return nil
+	}
+	return repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, target))
+}
+
+// refNames lists the branch and tag names of the mirror.
+func refNames(repo *git.Repository) ([]string, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	err = iter.ForEach(func(r *plumbing.Reference) error {
+		if r.Name().IsBranch() || r.Name().IsTag() {
+			names = append(names, r.Name().Short())
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
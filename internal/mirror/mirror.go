@@ -0,0 +1,203 @@
+// Package mirror replicates a source so that it can be served from places
+// without access to the original, e.g. air-gapped networks. Mirrors keep the
+// manifest byte for byte and every version at its upstream commit, so
+// consumers can point a source at the mirror without changing their lockfile.
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/adryledo/arca-cli/internal/hasher"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/source"
+	"gopkg.in/yaml.v3"
+)
+
+// Result summarises a mirror run.
+type Result struct {
+	Assets   int      `json:"assets"`
+	Versions int      `json:"versions"`
+	Refs     []string `json:"refs"`
+}
+
+// version is one manifest version to copy.
+type version struct {
+	ID, Version string
+	Kind        models.AssetKind
+	Meta        models.ManifestVersion
+}
+
+// Directory copies the manifest of src, every version's content at its
+// pinned ref and the source's refs into dest. The result is served by a
+// local source, which reports the upstream commits. An existing dest is only
+// replaced when it is an earlier mirror or empty.
+func Directory(ctx context.Context, src source.Source, sourceURL, dest string) (*Result, error) {
+	if err := checkDest(dest); err != nil {
+		return nil, err
+	}
+	raw, versions, err := loadManifest(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	head, err := src.ResolveCommit(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve default revision: %w", err)
+	}
+	index := &source.SnapshotIndex{Source: sourceURL, Head: head, Refs: make(map[string]string)}
+	names, err := src.ListRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		commit, err := src.ResolveCommit(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", name, err)
+		}
+		index.Refs[name] = commit
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dest), ".arca-mirror-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	written := make(map[string]bool)
+	for _, v := range versions {
+		if !filepath.IsLocal(filepath.FromSlash(v.Meta.Path)) {
+			return nil, fmt.Errorf("%s@%s: path %q leaves the source", v.ID, v.Version, v.Meta.Path)
+		}
+		commit, err := src.ResolveCommit(ctx, v.Meta.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s@%s: failed to resolve %s: %w", v.ID, v.Version, v.Meta.Ref, err)
+		}
+		target := filepath.Join(source.SnapshotRoot(tmp, head, commit), filepath.FromSlash(v.Meta.Path))
+		if written[target] {
+			continue
+		}
+		if err := copyVersion(ctx, src, v, target); err != nil {
+			return nil, err
+		}
+		written[target] = true
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, source.ManifestFileName), raw, 0644); err != nil {
+		return nil, err
+	}
+	if err := index.Save(tmp); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return nil, fmt.Errorf("failed to move mirror into place: %w", err)
+	}
+	return newResult(versions, index.RefNames()), nil
+}
+
+// checkDest refuses to overwrite a directory that is not an earlier mirror.
+func checkDest(dest string) error {
+	entries, err := os.ReadDir(dest)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	idx, err := source.LoadSnapshotIndex(dest)
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		return fmt.Errorf("%s is not empty and is not an arca mirror", dest)
+	}
+	return nil
+}
+
+// copyVersion writes the content of v into target and checks it against the
+// digest the manifest declares.
+func copyVersion(ctx context.Context, src source.Source, v version, target string) error {
+	isDir := v.Kind == models.KindSkill
+	if isDir {
+		if _, err := src.FetchDirectory(ctx, v.Meta.Path, v.Meta.Ref, target); err != nil {
+			return fmt.Errorf("%s@%s: failed to fetch %s: %w", v.ID, v.Version, v.Meta.Path, err)
+		}
+	} else {
+		data, _, err := src.FetchFile(ctx, v.Meta.Path, v.Meta.Ref)
+		if err != nil {
+			return fmt.Errorf("%s@%s: failed to fetch %s: %w", v.ID, v.Version, v.Meta.Path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+	}
+	if v.Meta.SHA256 == "" {
+		return nil
+	}
+	var got string
+	var err error
+	if isDir {
+		got, err = hasher.HashDir(target)
+	} else {
+		got, err = hasher.HashFile(target)
+	}
+	if err != nil {
+		return err
+	}
+	if got != v.Meta.SHA256 {
+		return fmt.Errorf("%s@%s: content hash %s does not match manifest sha256 %s", v.ID, v.Version, got, v.Meta.SHA256)
+	}
+	return nil
+}
+
+// loadManifest returns the raw manifest of src and its versions in a stable order.
+func loadManifest(ctx context.Context, src source.Source) ([]byte, []version, error) {
+	raw, err := src.FetchManifest(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest models.Manifest
+	if err := yaml.Unmarshal(raw, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	var versions []version
+	for id, asset := range manifest.Assets {
+		for v, meta := range asset.Versions {
+			// Read each version where consumers resolve it
+			meta.Ref = manifest.VersionRef(v, meta)
+			versions = append(versions, version{ID: id, Version: v, Kind: asset.Kind, Meta: meta})
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].ID != versions[j].ID {
+			return versions[i].ID < versions[j].ID
+		}
+		return versions[i].Version < versions[j].Version
+	})
+	return raw, versions, nil
+}
+
+func newResult(versions []version, refs []string) *Result {
+	assets := make(map[string]bool)
+	for _, v := range versions {
+		assets[v.ID] = true
+	}
+	if refs == nil {
+		refs = []string{}
+	}
+	return &Result{Assets: len(assets), Versions: len(versions), Refs: refs}
+}
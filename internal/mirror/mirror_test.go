@@ -0,0 +1,265 @@
+package mirror
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/source"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v failed: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupUpstream creates a repository whose manifest pins rules@1.0.0 to an
+// older commit, tagged v1, and serves rules@2.0.0 and a skill from the tip.
+func setupUpstream(t *testing.T) (dir, oldCommit string) {
+	t.Helper()
+	dir = t.TempDir()
+	gitRun(t, dir, "init", "-b", "trunk")
+	writeFile(t, filepath.Join(dir, "rules.md"), "old rules")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-m", "v1")
+	gitRun(t, dir, "tag", "v1")
+	oldCommit = gitRun(t, dir, "rev-parse", "HEAD")
+
+	writeFile(t, filepath.Join(dir, "rules.md"), "new rules")
+	writeFile(t, filepath.Join(dir, "skills", "review", "SKILL.md"), "review skill")
+	writeFile(t, filepath.Join(dir, source.ManifestFileName), `schema: "1.0"
+assets:
+  rules:
+    kind: instruction
+    versions:
+      1.0.0:
+        ref: `+oldCommit+`
+        path: rules.md
+      2.0.0:
+        path: rules.md
+  review:
+    kind: skill
+    versions:
+      1.0.0:
+        path: skills/review
+`)
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-m", "v2")
+	return dir, oldCommit
+}
+
+func newSource(t *testing.T, cfg models.SourceConfig) source.Source {
+	t.Helper()
+	src, err := source.New(cfg, source.Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return src
+}
+
+// checkMirror verifies that mirrored serves what upstream serves.
+func checkMirror(t *testing.T, upstream, mirrored source.Source, oldCommit string) {
+	t.Helper()
+	want, _ := upstream.FetchManifest(t.Context(), "")
+	got, err := mirrored.FetchManifest(t.Context(), "")
+	if err != nil {
+		t.Fatalf("FetchManifest failed: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Expected identical manifest, got %q", got)
+	}
+
+	wantHead, _ := upstream.ResolveCommit(t.Context(), "")
+	if head, err := mirrored.ResolveCommit(t.Context(), ""); err != nil || head != wantHead {
+		t.Errorf("Expected head %s, got %s (%v)", wantHead, head, err)
+	}
+
+	tests := []struct {
+		ref, content, commit string
+	}{
+		{"", "new rules", wantHead},
+		{oldCommit, "old rules", oldCommit},
+		{"v1", "old rules", oldCommit},
+	}
+	for _, tt := range tests {
+		data, commit, err := mirrored.FetchFile(t.Context(), "rules.md", tt.ref)
+		if err != nil {
+			t.Errorf("FetchFile(%q) failed: %v", tt.ref, err)
+			continue
+		}
+		if string(data) != tt.content || commit != tt.commit {
+			t.Errorf("FetchFile(%q): expected %q at %s, got %q at %s", tt.ref, tt.content, tt.commit, data, commit)
+		}
+	}
+
+	dest := filepath.Join(t.TempDir(), "review")
+	if _, err := mirrored.FetchDirectory(t.Context(), "skills/review", "", dest); err != nil {
+		t.Fatalf("FetchDirectory failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "SKILL.md")); string(data) != "review skill" {
+		t.Errorf("Expected skill content, got %q", data)
+	}
+
+	refs, err := mirrored.ListRefs(t.Context())
+	if err != nil || !slices.Contains(refs, "v1") || !slices.Contains(refs, "trunk") {
+		t.Errorf("Expected refs trunk and v1, got %v (%v)", refs, err)
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	upstreamDir, oldCommit := setupUpstream(t)
+	url := "file://" + filepath.ToSlash(upstreamDir)
+	upstream := newSource(t, models.SourceConfig{Type: models.SourceGit, URL: url})
+
+	dest := filepath.Join(t.TempDir(), "mirror")
+	result, err := Directory(t.Context(), upstream, url, dest)
+	if err != nil {
+		t.Fatalf("Directory failed: %v", err)
+	}
+	if result.Assets != 2 || result.Versions != 3 {
+		t.Errorf("Expected 2 assets and 3 versions, got %+v", result)
+	}
+
+	mirrored := newSource(t, models.SourceConfig{Type: models.SourceLocal, Path: dest})
+	checkMirror(t, upstream, mirrored, oldCommit)
+
+	// Mirroring again replaces the earlier mirror
+	if _, err := Directory(t.Context(), upstream, url, dest); err != nil {
+		t.Fatalf("Second Directory failed: %v", err)
+	}
+
+	t.Run("refuses foreign directories", func(t *testing.T) {
+		other := t.TempDir()
+		writeFile(t, filepath.Join(other, "notes.txt"), "keep me")
+		if _, err := Directory(t.Context(), upstream, url, other); err == nil {
+			t.Fatal("Expected an error for a non-mirror directory")
+		}
+		if _, err := os.Stat(filepath.Join(other, "notes.txt")); err != nil {
+			t.Errorf("Expected the directory to be left alone: %v", err)
+		}
+	})
+}
+
+func TestDirectory_VersionStrategy(t *testing.T) {
+	// Versions without a ref are read at the tag the template names, which
+	// is not the head of the default branch
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-b", "trunk")
+	writeFile(t, filepath.Join(dir, "rules.md"), "rules 1.0.0")
+	writeFile(t, filepath.Join(dir, source.ManifestFileName), `schema: "1.0"
+version-strategy:
+  template: "v{{version}}"
+assets:
+  rules:
+    kind: instruction
+    versions:
+      1.0.0:
+        path: rules.md
+`)
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-m", "1.0.0")
+	gitRun(t, dir, "tag", "v1.0.0")
+	tagged := gitRun(t, dir, "rev-parse", "HEAD")
+	writeFile(t, filepath.Join(dir, "rules.md"), "unreleased rules")
+	gitRun(t, dir, "commit", "-am", "wip")
+
+	url := "file://" + filepath.ToSlash(dir)
+	upstream := newSource(t, models.SourceConfig{Type: models.SourceGit, URL: url})
+	dest := filepath.Join(t.TempDir(), "mirror")
+	if _, err := Directory(t.Context(), upstream, url, dest); err != nil {
+		t.Fatalf("Directory failed: %v", err)
+	}
+
+	mirrored := newSource(t, models.SourceConfig{Type: models.SourceLocal, Path: dest})
+	data, commit, err := mirrored.FetchFile(t.Context(), "rules.md", "v1.0.0")
+	if err != nil {
+		t.Fatalf("FetchFile(v1.0.0) failed: %v", err)
+	}
+	if string(data) != "rules 1.0.0" || commit != tagged {
+		t.Errorf("Expected the tagged content at %s, got %q at %s", tagged, data, commit)
+	}
+}
+
+func TestDirectory_HashMismatch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rules.md"), "rules")
+	writeFile(t, filepath.Join(dir, source.ManifestFileName), `schema: "1.0"
+assets:
+  rules:
+    kind: instruction
+    versions:
+      1.0.0:
+        path: rules.md
+        sha256: 0000000000000000000000000000000000000000000000000000000000000000
+`)
+	src := newSource(t, models.SourceConfig{Type: models.SourceLocal, Path: dir})
+	dest := filepath.Join(t.TempDir(), "mirror")
+	if _, err := Directory(t.Context(), src, dir, dest); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("Expected a hash mismatch, got %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("Expected no mirror to be written, got %v", err)
+	}
+}
+
+func TestBareGit(t *testing.T) {
+	upstreamDir, oldCommit := setupUpstream(t)
+	url := "file://" + filepath.ToSlash(upstreamDir)
+	upstream := newSource(t, models.SourceConfig{Type: models.SourceGit, URL: url})
+
+	dest := filepath.Join(t.TempDir(), "assets.git")
+	result, err := BareGit(t.Context(), upstream, url, dest)
+	if err != nil {
+		t.Fatalf("BareGit failed: %v", err)
+	}
+	if !slices.Equal(result.Refs, []string{"trunk", "v1"}) {
+		t.Errorf("Expected refs [trunk v1], got %v", result.Refs)
+	}
+	if head := gitRun(t, dest, "symbolic-ref", "HEAD"); head != "refs/heads/trunk" {
+		t.Errorf("Expected HEAD to follow trunk, got %s", head)
+	}
+
+	mirrored := newSource(t, models.SourceConfig{Type: models.SourceGit, URL: "file://" + filepath.ToSlash(dest)})
+	checkMirror(t, upstream, mirrored, oldCommit)
+
+	t.Run("keeps unreachable pinned commits", func(t *testing.T) {
+		// Rewrite history so that no branch or tag reaches the pinned commit
+		gitRun(t, upstreamDir, "config", "uploadpack.allowAnySHA1InWant", "true")
+		gitRun(t, upstreamDir, "tag", "-d", "v1")
+		gitRun(t, upstreamDir, "checkout", "-q", "--orphan", "rewritten")
+		gitRun(t, upstreamDir, "commit", "-q", "-m", "rewritten history")
+		gitRun(t, upstreamDir, "branch", "-D", "trunk")
+		gitRun(t, upstreamDir, "branch", "-m", "trunk")
+
+		upstream := newSource(t, models.SourceConfig{Type: models.SourceGit, URL: url})
+		dest := filepath.Join(t.TempDir(), "assets.git")
+		if _, err := BareGit(t.Context(), upstream, url, dest); err != nil {
+			t.Fatalf("BareGit failed: %v", err)
+		}
+		if ref := gitRun(t, dest, "rev-parse", pinnedRefPrefix+oldCommit); ref != oldCommit {
+			t.Errorf("Expected %s to be kept, got %s", oldCommit, ref)
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // AssetKind defines the type of asset
 type AssetKind string
@@ -13,9 +16,38 @@ const (
 // --- Consumer Config (.arca-assets.yaml) ---
 
 type Config struct {
-	Schema  string                  `yaml:"schema"`
-	Sources map[string]SourceConfig `yaml:"sources"`
-	Assets  []AssetEntry            `yaml:"assets"`
+	Schema   string                  `yaml:"schema"`
+	Sources  map[string]SourceConfig `yaml:"sources"`
+	Assets   []AssetEntry            `yaml:"assets"`
+	Rewrites []URLRewrite            `yaml:"rewrites,omitempty"`
+	Scan     *ScanConfig             `yaml:"scan,omitempty"`
+	// Advisories are the feeds `arca audit` and `sync` check locked versions against
+	Advisories []AdvisoryFeed `yaml:"advisories,omitempty"`
+	Licenses   *LicensePolicy `yaml:"licenses,omitempty"`
+}
+
+// LicensePolicy restricts the licenses of the assets installed in a project.
+// Entries are SPDX license identifiers, matched case-insensitively.
+type LicensePolicy struct {
+	Allow        []string `yaml:"allow,omitempty"`        // when set, only these licenses are accepted
+	Deny         []string `yaml:"deny,omitempty"`         // always refused
+	AllowUnknown bool     `yaml:"allowUnknown,omitempty"` // accept assets without a license when allow is set
+}
+
+// AdvisoryFeed locates an advisory file: a path in a configured source, or a
+// local file relative to the project root.
+type AdvisoryFeed struct {
+	Source string `yaml:"source,omitempty"` // source alias
+	Path   string `yaml:"path,omitempty"`   // in the source; defaults to advisories.yaml
+	Ref    string `yaml:"ref,omitempty"`
+	File   string `yaml:"file,omitempty"`
+}
+
+// ScanConfig configures the content scanner run before assets are projected.
+type ScanConfig struct {
+	FailOn  string   `yaml:"failOn,omitempty"`  // lowest blocking severity: low, medium, high (default), critical or off
+	Rules   []string `yaml:"rules,omitempty"`   // rule files, relative to the project root
+	Disable []string `yaml:"disable,omitempty"` // ids of rules to skip
 }
 
 type SourceType string
@@ -23,13 +55,33 @@ type SourceType string
 const (
 	SourceGit   SourceType = "git"
 	SourceLocal SourceType = "local"
+	SourceHTTP  SourceType = "http"
+	SourceOCI   SourceType = "oci"
 )
 
 type SourceConfig struct {
-	Type     SourceType `yaml:"type"`
-	Provider string     `yaml:"provider,omitempty"` // github, azure, etc.
-	URL      string     `yaml:"url,omitempty"`      // for git
-	Path     string     `yaml:"path,omitempty"`     // for local
+	Type     SourceType   `yaml:"type"`
+	Provider string       `yaml:"provider,omitempty"` // github, gitlab, azure: single files via the host API
+	URL      string       `yaml:"url,omitempty"`      // for git, http and oci
+	Path     string       `yaml:"path,omitempty"`     // for local
+	Mirrors  []string     `yaml:"mirrors,omitempty"`  // tried in order before url
+	Trust    *TrustPolicy `yaml:"trust,omitempty"`
+}
+
+// TrustPolicy lists the keys allowed to sign the versions of a source and,
+// for git sources, its commits and tags.
+type TrustPolicy struct {
+	Keys              []string `yaml:"keys,omitempty"`              // public keys in authorized_keys format
+	RequireSignatures bool     `yaml:"requireSignatures,omitempty"` // refuse versions without a trusted signature
+	GPGKeys           []string `yaml:"gpgKeys,omitempty"`           // armored OpenPGP public keys, or files holding them
+	RequireSignedRefs bool     `yaml:"requireSignedRefs,omitempty"` // refuse git commits and tags without a trusted signature
+}
+
+// URLRewrite replaces a URL prefix, like git's url.<base>.insteadOf: source
+// URLs starting with InsteadOf are fetched from URL instead.
+type URLRewrite struct {
+	URL       string `yaml:"url"`
+	InsteadOf string `yaml:"insteadOf"`
 }
 
 type AssetEntry struct {
@@ -52,9 +104,20 @@ type VersionStrategy struct {
 	Template string `yaml:"template"`
 }
 
+// VersionRef returns the ref version is read at: its own ref, or else the
+// version strategy template with {{version}} replaced. An empty ref is the
+// default branch.
+func (m *Manifest) VersionRef(version string, meta ManifestVersion) string {
+	if meta.Ref != "" || m.VersionStrategy == nil || m.VersionStrategy.Template == "" {
+		return meta.Ref
+	}
+	return strings.ReplaceAll(m.VersionStrategy.Template, "{{version}}", version)
+}
+
 type ManifestAsset struct {
 	Kind         AssetKind                  `yaml:"kind"`
 	Description  string                     `yaml:"description,omitempty"`
+	License      string                     `yaml:"license,omitempty"`      // SPDX license expression
 	Dependencies map[string]string          `yaml:"dependencies,omitempty"` // asset-id -> version-constraint
 	Versions     map[string]ManifestVersion `yaml:"versions"`
 }
@@ -62,7 +125,17 @@ type ManifestAsset struct {
 type ManifestVersion struct {
 	Ref     string        `yaml:"ref,omitempty"`
 	Path    string        `yaml:"path"`
+	SHA256  string        `yaml:"sha256,omitempty"` // LF-normalized content hash, verified after download
 	Runtime *AssetRuntime `yaml:"runtime,omitempty"`
+	// Signatures by publishers over the asset, version, kind, license,
+	// dependencies and sha256; see signing.Entry
+	Signatures []Signature `yaml:"signatures,omitempty"`
+}
+
+// Signature is an SSH signature over a manifest version.
+type Signature struct {
+	Key string `yaml:"key"` // SHA256 fingerprint of the signing key
+	Sig string `yaml:"sig"` // base64 SSH signature
 }
 
 type AssetRuntime struct {
@@ -86,8 +159,121 @@ type LockedAsset struct {
 	ID           string    `json:"id"`
 	Version      string    `json:"version"`
 	Source       string    `json:"source"`
+	URL          string    `json:"url,omitempty"` // canonical source URL, never a mirror
 	Commit       string    `json:"commit"`
 	SHA256       string    `json:"sha256"`
+	License      string    `json:"license,omitempty"` // SPDX license expression from the manifest
 	ManifestHash string    `json:"manifestHash"`
+	SignedBy     string    `json:"signedBy,omitempty"`    // fingerprint of the trusted key that signed the version
+	RefSignedBy  string    `json:"refSignedBy,omitempty"` // fingerprint of the trusted key that signed the git commit or tag
 	ResolvedAt   time.Time `json:"resolvedAt"`
 }
+
+// --- Advisories (advisories.yaml) ---
+
+// AdvisoryFile lists known problems in published asset versions.
+type AdvisoryFile struct {
+	Schema     string     `yaml:"schema"`
+	Advisories []Advisory `yaml:"advisories"`
+}
+
+// Advisory reports a problem in a range of versions of an asset.
+type Advisory struct {
+	ID          string `yaml:"id" json:"id"`
+	Asset       string `yaml:"asset" json:"asset"`
+	Source      string `yaml:"source,omitempty" json:"source,omitempty"` // URL pattern; empty matches every source
+	Versions    string `yaml:"versions" json:"versions"`                 // semver constraint of the affected versions
+	Severity    string `yaml:"severity" json:"severity"`                 // low, medium, high or critical
+	Summary     string `yaml:"summary" json:"summary"`
+	Remediation string `yaml:"remediation,omitempty" json:"remediation,omitempty"`
+	URL         string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// --- Organization policy (.arca-policy.yaml) ---
+
+// Policy restricts the sources and asset versions a project may use.
+type Policy struct {
+	Schema            string            `yaml:"schema"`
+	Extends           *PolicyRef        `yaml:"extends,omitempty"`           // shared policy merged into this one
+	AllowedSources    []string          `yaml:"allowedSources,omitempty"`    // URL patterns; empty allows every source
+	AllowLocalSources bool              `yaml:"allowLocalSources,omitempty"` // local sources are refused when allowedSources is set
+	Deny              []DeniedAsset     `yaml:"deny,omitempty"`
+	MinVersions       map[string]string `yaml:"minVersions,omitempty"`       // asset id -> lowest allowed version
+	RequireSignatures bool              `yaml:"requireSignatures,omitempty"` // every version must be signed by a key its source trusts
+}
+
+// PolicyRef locates a policy file in a source, e.g. a repository shared by
+// an organization.
+type PolicyRef struct {
+	Source SourceConfig `yaml:"source"`
+	Path   string       `yaml:"path"`
+	Ref    string       `yaml:"ref,omitempty"`
+}
+
+// DeniedAsset bans versions of an asset.
+type DeniedAsset struct {
+	ID       string `yaml:"id"`
+	Versions string `yaml:"versions,omitempty"` // semver constraint; empty denies every version
+	Reason   string `yaml:"reason,omitempty"`
+}
+
+// --- Vendor index (.arca/vendor/vendor.json) ---
+
+// VendorIndex records the assets copied into the in-repo vendor tree
+// together with the lockfile hashes they were vendored at.
+type VendorIndex struct {
+	Assets []VendoredAsset `json:"assets"`
+}
+
+type VendoredAsset struct {
+	ID      string    `json:"id"`
+	Version string    `json:"version"`
+	Source  string    `json:"source"`
+	Kind    AssetKind `json:"kind"`
+	Commit  string    `json:"commit"`
+	SHA256  string    `json:"sha256"`
+}
+
+// Find returns the vendored entry for an asset of a given source.
+func (v *VendorIndex) Find(source, id string) (VendoredAsset, bool) {
+	for _, va := range v.Assets {
+		if va.Source == source && va.ID == id {
+			return va, true
+		}
+	}
+	return VendoredAsset{}, false
+}
+
+// --- User config (<user config dir>/arca/config.yaml) ---
+
+// UserConfig holds per-user settings that apply to every workspace.
+type UserConfig struct {
+	Cache       CacheSettings `yaml:"cache,omitempty"`
+	Credentials []Credential  `yaml:"credentials,omitempty"`
+	Rewrites    []URLRewrite  `yaml:"rewrites,omitempty"`
+	Network     NetworkConfig `yaml:"network,omitempty"`
+}
+
+// NetworkConfig bounds the requests made to sources and sets up TLS.
+// Proxies are taken from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+type NetworkConfig struct {
+	Timeout string `yaml:"timeout,omitempty"` // per request, e.g. "30s"; "0" disables it
+	Retries *int   `yaml:"retries,omitempty"` // retries of transient failures
+
+	CABundle      string   `yaml:"caBundle,omitempty"`      // PEM file trusted in addition to the system roots
+	InsecureHosts []string `yaml:"insecureHosts,omitempty"` // hosts whose TLS certificates are not verified
+}
+
+type CacheSettings struct {
+	Dir   string   `yaml:"dir,omitempty"`   // writable cache location
+	Seeds []string `yaml:"seeds,omitempty"` // read-only caches consulted first
+}
+
+// Credential configures authentication for one host.
+type Credential struct {
+	Host     string `yaml:"host"`               // host name, "*.example.com" matches subdomains
+	Username string `yaml:"username,omitempty"` // e.g. the organization name for Azure DevOps
+	Token    string `yaml:"token,omitempty"`
+	TokenEnv string `yaml:"tokenEnv,omitempty"` // environment variable holding the token
+	SSHKey   string `yaml:"sshKey,omitempty"`   // private key file for SSH sources
+}
@@ -0,0 +1,144 @@
+// Package netutil holds the timeout, retry, proxy and TLS settings shared by
+// everything that talks to the network.
+package netutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+)
+
+// Policy bounds each network operation and retries transient failures with
+// exponential backoff. The zero Policy runs an operation once without a
+// timeout.
+type Policy struct {
+	// Timeout limits a single attempt; zero means no limit.
+	Timeout time.Duration
+	// Retries is how many times a transient failure is retried.
+	Retries int
+	// BaseDelay is the wait before the first retry; it doubles every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between retries.
+	MaxDelay time.Duration
+}
+
+// DefaultPolicy is used when nothing is configured.
+var DefaultPolicy = Policy{
+	Timeout:   2 * time.Minute,
+	Retries:   3,
+	BaseDelay: 500 * time.Millisecond,
+	MaxDelay:  10 * time.Second,
+}
+
+// IsZero reports whether p neither limits nor retries anything.
+func (p Policy) IsZero() bool {
+	return p.Timeout <= 0 && p.Retries <= 0
+}
+
+// Transient is implemented by errors that know whether trying again may help,
+// e.g. HTTP status errors.
+type Transient interface {
+	Transient() bool
+}
+
+// Do runs fn until it succeeds, fails with a permanent error, or the retries
+// are used up. Each attempt gets its own timeout derived from ctx. Do stops
+// as soon as ctx is done and then returns an error wrapping ctx's error.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := attemptOnce(ctx, p.Timeout, fn)
+		if err == nil {
+			return nil
+		}
+		if cerr := ctx.Err(); cerr != nil {
+			if errors.Is(err, cerr) {
+				return err
+			}
+			return fmt.Errorf("%w: %v", cerr, err)
+		}
+		if attempt >= p.Retries || !IsTransient(err) {
+			return err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %v", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+func attemptOnce(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := fn(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &timeoutError{after: timeout, err: err}
+	}
+	return err
+}
+
+// backoff returns the wait before retry number attempt+1, with jitter.
+func (p Policy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	if d <= 0 {
+		return 0
+	}
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	// Up to 20% jitter so that parallel runs do not retry in lockstep
+	return d - time.Duration(rand.Int64N(int64(d)/5+1))
+}
+
+// timeoutError reports an attempt that ran out of time. Timeouts are transient.
+type timeoutError struct {
+	after time.Duration
+	err   error
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s: %v", e.after, e.err)
+}
+
+func (e *timeoutError) Unwrap() error   { return e.err }
+func (e *timeoutError) Transient() bool { return true }
+
+// IsTransient reports whether err is worth retrying: timeouts, dropped or
+// refused connections, and errors that say so themselves. Cancellation is
+// never transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var t Transient
+	if errors.As(err, &t) {
+		return t.Transient()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,106 @@
+package netutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type statusErr int
+
+func (e statusErr) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusErr) Transient() bool { return e >= 500 }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", errors.New("not found"), false},
+		{"canceled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"unexpected eof", fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"server error", fmt.Errorf("fetch: %w", statusErr(503)), true},
+		{"client error", fmt.Errorf("fetch: %w", statusErr(404)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	fast := Policy{Retries: 2, BaseDelay: time.Millisecond}
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		calls := 0
+		err := Do(context.Background(), fast, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return statusErr(502)
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("Expected success after 3 calls, got %d calls (%v)", calls, err)
+		}
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		calls := 0
+		err := Do(context.Background(), fast, func(ctx context.Context) error {
+			calls++
+			return statusErr(500)
+		})
+		if err == nil || calls != 3 {
+			t.Errorf("Expected failure after 3 calls, got %d calls (%v)", calls, err)
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		calls := 0
+		Do(context.Background(), fast, func(ctx context.Context) error {
+			calls++
+			return statusErr(404)
+		})
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("times out each attempt", func(t *testing.T) {
+		calls := 0
+		p := Policy{Timeout: 10 * time.Millisecond, Retries: 1, BaseDelay: time.Millisecond}
+		err := Do(context.Background(), p, func(ctx context.Context) error {
+			calls++
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		if !errors.Is(err, context.DeadlineExceeded) || calls != 2 {
+			t.Errorf("Expected 2 timed out calls, got %d calls (%v)", calls, err)
+		}
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := Do(ctx, Policy{Retries: 5, BaseDelay: time.Hour}, func(ctx context.Context) error {
+			calls++
+			cancel()
+			return statusErr(503)
+		})
+		if !errors.Is(err, context.Canceled) || calls != 1 {
+			t.Errorf("Expected cancellation after 1 call, got %d calls (%v)", calls, err)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package netutil
+
+import (
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	// Rejected handshakes are expected
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		cfg    TransportConfig
+		wantOK bool
+	}{
+		{"system roots only", TransportConfig{}, false},
+		{"ca bundle", TransportConfig{CABundle: bundle}, true},
+		{"insecure host", TransportConfig{InsecureHosts: []string{"127.0.0.1"}}, true},
+		{"other insecure host", TransportConfig{InsecureHosts: []string{"*.example.com"}}, false},
+		{"ca bundle and other insecure host", TransportConfig{CABundle: bundle, InsecureHosts: []string{"git.example.com"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewHTTPClient(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewHTTPClient failed: %v", err)
+			}
+			resp, err := client.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if ok := err == nil; ok != tt.wantOK {
+				t.Errorf("Expected success %v, got error %v", tt.wantOK, err)
+			}
+		})
+	}
+
+	if _, err := NewHTTPClient(TransportConfig{CABundle: empty}); err == nil {
+		t.Error("Expected an error for a bundle without certificates")
+	}
+	if _, err := NewHTTPClient(TransportConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("Expected an error for a missing bundle")
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	patterns := []string{"git.corp.example.com", "*.internal"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"git.corp.example.com", true},
+		{"GIT.corp.example.com", true},
+		{"corp.example.com", false},
+		{"internal", true},
+		{"registry.internal", true},
+		{"registry.internal.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := MatchHost(patterns, tt.host); got != tt.want {
+			t.Errorf("MatchHost(%q): expected %v, got %v", tt.host, tt.want, got)
+		}
+	}
+}
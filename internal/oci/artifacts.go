@@ -0,0 +1,77 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+// AssetMediaType returns the layer media type for an asset kind.
+func AssetMediaType(kind models.AssetKind) string {
+	if kind == models.KindSkill {
+		return MediaTypeSkill
+	}
+	return MediaTypeInstruction
+}
+
+// PushAsset uploads the content of an asset version as a single-layer
+// artifact tagged VersionTag(assetID, version) and returns the layer.
+// Skill content must already be packed with TarGzDir.
+func (c *Client) PushAsset(ctx context.Context, assetID, version string, kind models.AssetKind, title string, content []byte) (Descriptor, error) {
+	layer, err := c.PushBlob(ctx, content, AssetMediaType(kind))
+	if err != nil {
+		return Descriptor{}, err
+	}
+	layer.Annotations = map[string]string{
+		AnnotationTitle:   title,
+		AnnotationAssetID: assetID,
+		AnnotationVersion: version,
+	}
+	if err := c.pushEmptyConfig(ctx); err != nil {
+		return Descriptor{}, err
+	}
+	m := NewManifest(ArtifactTypeAsset, []Descriptor{layer})
+	m.Annotations = map[string]string{AnnotationAssetID: assetID, AnnotationVersion: version}
+	if _, err := c.PutManifest(ctx, VersionTag(assetID, version), m); err != nil {
+		return Descriptor{}, err
+	}
+	return layer, nil
+}
+
+// FetchIndex returns the index artifact at a tag or digest together with the
+// arca-manifest.yaml it carries and the artifact digest.
+func (c *Client) FetchIndex(ctx context.Context, reference string) (*Manifest, []byte, string, error) {
+	m, digest, err := c.GetManifest(ctx, reference)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(m.Layers) == 0 || m.Layers[0].MediaType != MediaTypeManifest {
+		return nil, nil, "", fmt.Errorf("%s@%s is not an ARCA index", c.Ref, reference)
+	}
+	data, err := c.GetBlob(ctx, m.Layers[0].Digest)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return m, data, digest, nil
+}
+
+// PushIndex uploads an ARCA manifest together with the asset layers it refers
+// to, so that the registry keeps them alive, and tags it IndexTag.
+func (c *Client) PushIndex(ctx context.Context, manifestYAML []byte, assets []Descriptor) (string, error) {
+	layer, err := c.PushBlob(ctx, manifestYAML, MediaTypeManifest)
+	if err != nil {
+		return "", err
+	}
+	layer.Annotations = map[string]string{AnnotationTitle: "arca-manifest.yaml"}
+	if err := c.pushEmptyConfig(ctx); err != nil {
+		return "", err
+	}
+	layers := append([]Descriptor{layer}, assets...)
+	return c.PutManifest(ctx, IndexTag, NewManifest(ArtifactTypeIndex, layers))
+}
+
+func (c *Client) pushEmptyConfig(ctx context.Context) error {
+	_, err := c.PushBlob(ctx, emptyJSON, MediaTypeEmptyJSON)
+	return err
+}
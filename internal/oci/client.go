@@ -0,0 +1,338 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+)
+
+// maxManifestSize bounds manifest downloads; registries reject larger ones anyway.
+const maxManifestSize = 4 << 20
+
+// maxBlobSize bounds blob downloads, matching the cap on archives from other
+// sources. A variable so that tests can lower it.
+var maxBlobSize int64 = 128 << 20
+
+// Client talks to one repository of an OCI registry.
+type Client struct {
+	Ref  Reference
+	HTTP *http.Client
+
+	credOnce sync.Once
+	cred     *auth.HTTPCredential
+	credErr  error
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewClient creates a client for the repository ref points to.
+func NewClient(ref Reference) *Client {
+	return &Client{Ref: ref, HTTP: http.DefaultClient}
+}
+
+// ErrNotFound is returned when a manifest or blob does not exist.
+var ErrNotFound = errors.New("not found in registry")
+
+// GetManifest fetches the manifest for a tag or digest and returns it with its digest.
+func (c *Client) GetManifest(ctx context.Context, reference string) (*Manifest, string, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.url("manifests/"+reference), nil, map[string]string{"Accept": MediaTypeImageManifest})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, http.StatusOK); err != nil {
+		return nil, "", fmt.Errorf("failed to get manifest %s: %w", reference, err)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, "", err
+	}
+	digest := Digest(data)
+	if IsDigest(reference) && digest != reference {
+		return nil, "", fmt.Errorf("manifest digest mismatch: expected %s, got %s", reference, digest)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, "", fmt.Errorf("failed to parse manifest %s: %w", reference, err)
+	}
+	return &m, digest, nil
+}
+
+// PutManifest uploads a manifest under a tag and returns its digest.
+func (c *Client) PutManifest(ctx context.Context, tag string, m *Manifest) (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(ctx, http.MethodPut, c.url("manifests/"+tag), data, map[string]string{"Content-Type": MediaTypeImageManifest})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, http.StatusCreated); err != nil {
+		return "", fmt.Errorf("failed to push manifest %s: %w", tag, err)
+	}
+	return Digest(data), nil
+}
+
+// GetBlob downloads a blob and verifies it against its digest.
+func (c *Client) GetBlob(ctx context.Context, digest string) ([]byte, error) {
+	if !IsDigest(digest) {
+		return nil, fmt.Errorf("invalid digest %q", digest)
+	}
+	resp, err := c.do(ctx, http.MethodGet, c.url("blobs/"+digest), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", digest, err)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBlobSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBlobSize {
+		return nil, fmt.Errorf("blob %s exceeds %d bytes", digest, maxBlobSize)
+	}
+	if actual := Digest(data); actual != digest {
+		return nil, fmt.Errorf("blob digest mismatch: expected %s, got %s", digest, actual)
+	}
+	return data, nil
+}
+
+// PushBlob uploads data unless the registry already has it and returns its descriptor.
+func (c *Client) PushBlob(ctx context.Context, data []byte, mediaType string) (Descriptor, error) {
+	desc := Descriptor{MediaType: mediaType, Digest: Digest(data), Size: int64(len(data))}
+
+	resp, err := c.do(ctx, http.MethodHead, c.url("blobs/"+desc.Digest), nil, nil)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return desc, nil
+	}
+
+	resp, err = c.do(ctx, http.MethodPost, c.url("blobs/uploads/"), nil, nil)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	resp.Body.Close()
+	if err := checkStatus(resp, http.StatusAccepted); err != nil {
+		return Descriptor{}, fmt.Errorf("failed to start blob upload: %w", err)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return Descriptor{}, fmt.Errorf("registry returned no upload location")
+	}
+	q := location.Query()
+	q.Set("digest", desc.Digest)
+	location.RawQuery = q.Encode()
+
+	resp, err = c.do(ctx, http.MethodPut, location.String(), data, map[string]string{"Content-Type": "application/octet-stream"})
+	if err != nil {
+		return Descriptor{}, err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, http.StatusCreated); err != nil {
+		return Descriptor{}, fmt.Errorf("failed to upload blob %s: %w", desc.Digest, err)
+	}
+	return desc, nil
+}
+
+// Tags lists the tags of the repository.
+func (c *Client) Tags(ctx context.Context) ([]string, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.url("tags/list"), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	var list struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse tag list: %w", err)
+	}
+	return list.Tags, nil
+}
+
+func (c *Client) url(path string) string {
+	scheme := "https"
+	if c.Ref.PlainHTTP {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s", scheme, c.Ref.Host, c.Ref.Repository, path)
+}
+
+// do sends a request, answering a Bearer challenge with a token from the
+// registry's auth service, or a Basic challenge with the host credential.
+func (c *Client) do(ctx context.Context, method, target string, body []byte, headers map[string]string) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		c.mu.Lock()
+		token := c.token
+		c.mu.Unlock()
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if cred, err := c.credential(); err != nil {
+			return nil, err
+		} else if cred != nil && req.URL.Host == c.Ref.Host {
+			req.SetBasicAuth(cred.Username, cred.Password)
+		}
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("registry request failed: %w", err)
+		}
+		return resp, nil
+	}
+
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, fmt.Errorf("registry %s: authentication required", c.Ref.Host)
+	}
+	if err := c.fetchToken(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+// fetchToken obtains a Bearer token as described by a WWW-Authenticate challenge.
+func (c *Client) fetchToken(ctx context.Context, challenge string) error {
+	params := parseChallenge(challenge[len("bearer "):])
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("registry %s: invalid auth challenge", c.Ref.Host)
+	}
+	q := realm.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + c.Ref.Repository + ":pull,push"
+	}
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	cred, err := c.credential()
+	if err != nil {
+		return err
+	}
+	if cred != nil {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, http.StatusOK); err != nil {
+		return fmt.Errorf("registry token request failed: %w", err)
+	}
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return fmt.Errorf("failed to parse registry token: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = tok.Token
+	if c.token == "" {
+		c.token = tok.AccessToken
+	}
+	if c.token == "" {
+		return fmt.Errorf("registry %s returned an empty token", c.Ref.Host)
+	}
+	return nil
+}
+
+func (c *Client) credential() (*auth.HTTPCredential, error) {
+	c.credOnce.Do(func() {
+		c.cred, c.credErr = auth.LookupCredential("https://" + c.Ref.Host)
+	})
+	return c.cred, c.credErr
+}
+
+// parseChallenge splits `realm="...",service="..."` into its parameters.
+func parseChallenge(s string) map[string]string {
+	params := make(map[string]string)
+	for len(s) > 0 {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[key] = value
+		s = strings.TrimLeft(rest, ", ")
+	}
+	return params
+}
+
+// StatusError is an unexpected response from the registry.
+type StatusError struct {
+	Status  string
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Message)
+}
+
+// Transient reports whether retrying the request may succeed.
+func (e *StatusError) Transient() bool {
+	return e.Code >= 500 || e.Code == http.StatusTooManyRequests
+}
+
+func checkStatus(resp *http.Response, want int) error {
+	if resp.StatusCode == want {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &StatusError{Status: resp.Status, Code: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+}
@@ -0,0 +1,83 @@
+package oci_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/oci"
+	"github.com/adryledo/arca-cli/internal/oci/ocitest"
+)
+
+func newTestClient(t *testing.T, reg *ocitest.Registry) *oci.Client {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("ARCA_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("NETRC", filepath.Join(dir, "netrc"))
+	t.Setenv("ARCA_GIT_TOKEN", "")
+
+	srv := ocitest.NewServer(reg)
+	t.Cleanup(srv.Close)
+	ref, err := oci.ParseReference("oci://" + strings.TrimPrefix(srv.URL, "http://") + "/org/assets")
+	if err != nil {
+		t.Fatalf("ParseReference failed: %v", err)
+	}
+	return oci.NewClient(ref)
+}
+
+func TestClient_RoundTrip(t *testing.T) {
+	// The token flow is exercised on every request
+	client := newTestClient(t, &ocitest.Registry{Token: "secret"})
+	ctx := context.Background()
+
+	if _, _, _, err := client.FetchIndex(ctx, oci.IndexTag); !errors.Is(err, oci.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for a fresh repository, got %v", err)
+	}
+
+	layer, err := client.PushAsset(ctx, "rules", "1.0.0", models.KindInstruction, "rules.md", []byte("rules"))
+	if err != nil {
+		t.Fatalf("PushAsset failed: %v", err)
+	}
+	if layer.Digest != oci.Digest([]byte("rules")) || layer.MediaType != oci.MediaTypeInstruction {
+		t.Errorf("Unexpected layer %+v", layer)
+	}
+	// Pushing the same content again reuses the blob
+	if again, err := client.PushAsset(ctx, "rules", "1.0.0", models.KindInstruction, "rules.md", []byte("rules")); err != nil || again.Digest != layer.Digest {
+		t.Errorf("Expected same digest on re-push, got %s (%v)", again.Digest, err)
+	}
+
+	indexDigest, err := client.PushIndex(ctx, []byte("schema: \"1.0\"\n"), []oci.Descriptor{layer})
+	if err != nil {
+		t.Fatalf("PushIndex failed: %v", err)
+	}
+
+	idx, manifest, digest, err := client.FetchIndex(ctx, oci.IndexTag)
+	if err != nil {
+		t.Fatalf("FetchIndex failed: %v", err)
+	}
+	if digest != indexDigest || string(manifest) != "schema: \"1.0\"\n" || len(idx.Layers) != 2 {
+		t.Errorf("Unexpected index %s %q %+v", digest, manifest, idx.Layers)
+	}
+	if _, _, _, err := client.FetchIndex(ctx, indexDigest); err != nil {
+		t.Errorf("Expected index to be addressable by digest, got %v", err)
+	}
+
+	data, err := client.GetBlob(ctx, layer.Digest)
+	if err != nil || string(data) != "rules" {
+		t.Errorf("Expected blob 'rules', got %q (%v)", data, err)
+	}
+
+	tags, err := client.Tags(ctx)
+	if err != nil {
+		t.Fatalf("Tags failed: %v", err)
+	}
+	for _, want := range []string{oci.IndexTag, "rules-1.0.0"} {
+		if !slices.Contains(tags, want) {
+			t.Errorf("Expected tag %s, got %v", want, tags)
+		}
+	}
+}
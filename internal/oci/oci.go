@@ -0,0 +1,130 @@
+// Package oci is a minimal client for OCI distribution registries, used to
+// publish and pull ARCA assets as OCI artifacts.
+//
+// A source repository holds one index artifact, tagged IndexTag, whose first
+// layer is the arca-manifest.yaml and whose remaining layers are every
+// published asset version. Each asset version is also pushed as its own
+// artifact tagged <asset-id>-<version>, and the manifest refers to versions
+// by their layer digest.
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const (
+	// IndexTag is the tag of the artifact carrying the ARCA manifest.
+	IndexTag = "arca-manifest"
+
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeEmptyJSON     = "application/vnd.oci.empty.v1+json"
+
+	ArtifactTypeIndex = "application/vnd.arca.index.v1"
+	ArtifactTypeAsset = "application/vnd.arca.asset.v1"
+
+	MediaTypeManifest    = "application/vnd.arca.manifest.v1+yaml"
+	MediaTypeInstruction = "application/vnd.arca.instruction.v1+markdown"
+	MediaTypeSkill       = "application/vnd.arca.skill.v1.tar+gzip"
+
+	AnnotationTitle   = "org.opencontainers.image.title"
+	AnnotationAssetID = "dev.arca.asset.id"
+	AnnotationVersion = "dev.arca.asset.version"
+)
+
+// emptyJSON is the content of the empty config descriptor.
+var emptyJSON = []byte("{}")
+
+// Descriptor points to content in a registry.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// NewManifest creates an artifact manifest with an empty config.
+func NewManifest(artifactType string, layers []Descriptor) *Manifest {
+	return &Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		ArtifactType:  artifactType,
+		Config:        Descriptor{MediaType: MediaTypeEmptyJSON, Digest: Digest(emptyJSON), Size: int64(len(emptyJSON))},
+		Layers:        layers,
+	}
+}
+
+// Reference identifies a repository in a registry.
+type Reference struct {
+	Host       string
+	Repository string
+	// PlainHTTP is set for loopback registries, which are spoken to without TLS.
+	PlainHTTP bool
+}
+
+// ParseReference parses "oci://host[:port]/repo/name" (the scheme is optional).
+func ParseReference(raw string) (Reference, error) {
+	rest := strings.TrimPrefix(raw, "oci://")
+	host, repo, ok := strings.Cut(rest, "/")
+	repo = strings.Trim(repo, "/")
+	if !ok || host == "" || repo == "" {
+		return Reference{}, fmt.Errorf("invalid oci reference %q, expected oci://host/repository", raw)
+	}
+	if repo != strings.ToLower(repo) {
+		return Reference{}, fmt.Errorf("invalid oci repository %q: must be lowercase", repo)
+	}
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	plain := hostname == "localhost"
+	if ip := net.ParseIP(hostname); ip != nil && ip.IsLoopback() {
+		plain = true
+	}
+	return Reference{Host: host, Repository: repo, PlainHTTP: plain}, nil
+}
+
+// String returns the reference in oci:// form.
+func (r Reference) String() string {
+	return "oci://" + r.Host + "/" + r.Repository
+}
+
+// Digest returns the sha256 digest of data in OCI form.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+var (
+	digestPattern  = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+	invalidTagChar = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+)
+
+// IsDigest reports whether s is a sha256 digest.
+func IsDigest(s string) bool {
+	return digestPattern.MatchString(s)
+}
+
+// VersionTag returns the tag an asset version is pushed under. Characters
+// that tags do not allow (such as the "+" of semver build metadata) become "_".
+func VersionTag(assetID, version string) string {
+	tag := invalidTagChar.ReplaceAllString(assetID+"-"+version, "_")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return tag
+}
@@ -0,0 +1,111 @@
+package oci
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Reference
+		wantErr bool
+	}{
+		{"oci://registry.example.com/org/assets", Reference{Host: "registry.example.com", Repository: "org/assets"}, false},
+		{"registry.example.com/assets/", Reference{Host: "registry.example.com", Repository: "assets"}, false},
+		{"oci://localhost:5000/assets", Reference{Host: "localhost:5000", Repository: "assets", PlainHTTP: true}, false},
+		{"oci://127.0.0.1:5000/assets", Reference{Host: "127.0.0.1:5000", Repository: "assets", PlainHTTP: true}, false},
+		{"oci://registry.example.com", Reference{}, true},
+		{"oci://registry.example.com/Org/Assets", Reference{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseReference(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVersionTag(t *testing.T) {
+	tests := map[string][2]string{
+		"rules-1.2.0":       {"rules", "1.2.0"},
+		"rules-1.2.0_build": {"rules", "1.2.0+build"},
+	}
+	for want, in := range tests {
+		if got := VersionTag(in[0], in[1]); got != want {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestTarGzDir_Deterministic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "ref"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"SKILL.md": "skill", "ref/a.md": "a"} {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := TarGzDir(dir)
+	if err != nil {
+		t.Fatalf("TarGzDir failed: %v", err)
+	}
+	// Touching the files must not change the digest
+	touched := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "SKILL.md"), touched, touched); err != nil {
+		t.Fatal(err)
+	}
+	second, err := TarGzDir(dir)
+	if err != nil {
+		t.Fatalf("TarGzDir failed: %v", err)
+	}
+	if Digest(first) != Digest(second) {
+		t.Errorf("Expected identical digests, got %s and %s", Digest(first), Digest(second))
+	}
+}
+
+func TestClient_GetBlobTooLarge(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ARCA_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("NETRC", filepath.Join(dir, "netrc"))
+	t.Setenv("ARCA_GIT_TOKEN", "")
+	limit := maxBlobSize
+	t.Cleanup(func() { maxBlobSize = limit })
+	maxBlobSize = 1024
+
+	small, large := bytes.Repeat([]byte("a"), 1024), bytes.Repeat([]byte("a"), 1025)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, Digest(large)) {
+			w.Write(large)
+			return
+		}
+		w.Write(small)
+	}))
+	t.Cleanup(srv.Close)
+	ref, err := ParseReference("oci://" + strings.TrimPrefix(srv.URL, "http://") + "/org/assets")
+	if err != nil {
+		t.Fatalf("ParseReference failed: %v", err)
+	}
+	client := NewClient(ref)
+
+	if data, err := client.GetBlob(t.Context(), Digest(small)); err != nil || len(data) != len(small) {
+		t.Errorf("Expected a blob at the limit to download, got %d bytes (%v)", len(data), err)
+	}
+	if _, err := client.GetBlob(t.Context(), Digest(large)); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Expected an oversized blob to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package ocitest provides an in-memory OCI distribution registry for tests.
+package ocitest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/oci"
+)
+
+// Registry is an in-memory registry implementing the parts of the
+// distribution API the ARCA client uses. When Token is set, every request
+// must carry it as a Bearer token obtained from the /token endpoint.
+type Registry struct {
+	Token string
+
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte            // digest -> manifest
+	tags      map[string]map[string]string // repository -> tag -> digest
+	uploads   map[string]bool
+}
+
+// NewServer starts a registry on a loopback address. Close the returned
+// server when done; its host is suitable for oci://<host>/<repo> references.
+func NewServer(r *Registry) *httptest.Server {
+	if r == nil {
+		r = &Registry{}
+	}
+	r.blobs = make(map[string][]byte)
+	r.manifests = make(map[string][]byte)
+	r.tags = make(map[string]map[string]string)
+	r.uploads = make(map[string]bool)
+	return httptest.NewServer(r)
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		json.NewEncoder(w).Encode(map[string]string{"token": r.Token})
+		return
+	}
+	if r.Token != "" && req.Header.Get("Authorization") != "Bearer "+r.Token {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="http://`+req.Host+`/token",service="ocitest"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == "" || path == req.URL.Path {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case strings.HasSuffix(path, "/tags/list"):
+		repo := strings.TrimSuffix(path, "/tags/list")
+		tags := []string{}
+		for tag := range r.tags[repo] {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		json.NewEncoder(w).Encode(map[string]any{"name": repo, "tags": tags})
+	case strings.Contains(path, "/blobs/uploads/"):
+		r.serveUpload(w, req, path)
+	case strings.Contains(path, "/manifests/"):
+		i := strings.LastIndex(path, "/manifests/")
+		r.serveManifest(w, req, path[:i], path[i+len("/manifests/"):])
+	case strings.Contains(path, "/blobs/"):
+		digest := path[strings.LastIndex(path, "/blobs/")+len("/blobs/"):]
+		data, ok := r.blobs[digest]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+		if req.Method != http.MethodHead {
+			w.Write(data)
+		}
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *Registry) serveUpload(w http.ResponseWriter, req *http.Request, path string) {
+	i := strings.LastIndex(path, "/blobs/uploads/")
+	repo, id := path[:i], path[i+len("/blobs/uploads/"):]
+	switch req.Method {
+	case http.MethodPost:
+		buf := make([]byte, 8)
+		rand.Read(buf)
+		id = hex.EncodeToString(buf)
+		r.uploads[id] = true
+		w.Header().Set("Location", "/v2/"+repo+"/blobs/uploads/"+id)
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		if !r.uploads[id] {
+			http.NotFound(w, req)
+			return
+		}
+		data, _ := io.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		if oci.Digest(data) != digest {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		delete(r.uploads, id)
+		r.blobs[digest] = data
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, repo, reference string) {
+	switch req.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(req.Body)
+		digest := oci.Digest(data)
+		r.manifests[digest] = data
+		if !oci.IsDigest(reference) {
+			if r.tags[repo] == nil {
+				r.tags[repo] = make(map[string]string)
+			}
+			r.tags[repo][reference] = digest
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet, http.MethodHead:
+		digest := reference
+		if !oci.IsDigest(reference) {
+			digest = r.tags[repo][reference]
+		}
+		data, ok := r.manifests[digest]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", oci.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest)
+		if req.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
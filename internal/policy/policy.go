@@ -0,0 +1,257 @@
+// Package policy enforces the organization policy of a project
+// (.arca-policy.yaml): which sources may be used and which asset versions
+// may be installed.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/source"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the policy file at the root of a project.
+const FileName = ".arca-policy.yaml"
+
+// maxExtends bounds chains of extended policies, which also stops cycles.
+const maxExtends = 8
+
+// Opener returns the source a shared policy is fetched from.
+type Opener func(cfg models.SourceConfig) (source.Source, error)
+
+// Set is a project policy followed by the policies it extends. Sources and
+// assets must satisfy every policy of the set, so a project can tighten a
+// shared policy but never loosen it.
+type Set []models.Policy
+
+// Violation is a source or asset version a policy refuses.
+type Violation struct {
+	Source  string `json:"source"`
+	Asset   string `json:"asset,omitempty"` // id@version
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Asset is an asset version checked against a policy.
+type Asset struct {
+	ID       string
+	Version  string
+	Source   string // source alias
+	SignedBy string // trusted key that signed the version, if any
+}
+
+// Load reads the policy of the project at root and the policies it extends,
+// fetched through open. It returns an empty Set when the project has no
+// policy file. A shared policy that cannot be fetched is an error, so that
+// an unreachable policy source never lifts its restrictions.
+func Load(ctx context.Context, root string, open Opener) (Set, error) {
+	data, err := os.ReadFile(filepath.Join(root, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	p, err := parse(data, FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	set := Set{*p}
+	for ref := p.Extends; ref != nil; ref = set[len(set)-1].Extends {
+		if len(set) > maxExtends {
+			return nil, fmt.Errorf("policy extends more than %d policies", maxExtends)
+		}
+		src, err := open(ref.Source)
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := src.FetchFile(ctx, ref.Path, ref.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch policy %s from %s: %w", ref.Path, sourceName(ref.Source), err)
+		}
+		parent, err := parse(data, ref.Path)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, *parent)
+	}
+	return set, nil
+}
+
+// parse decodes a policy file and validates its version constraints.
+func parse(data []byte, name string) (*models.Policy, error) {
+	var p models.Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	for _, d := range p.Deny {
+		if d.ID == "" {
+			return nil, fmt.Errorf("%s: deny entry without an id", name)
+		}
+		if d.Versions != "" {
+			if _, err := semver.NewConstraint(d.Versions); err != nil {
+				return nil, fmt.Errorf("%s: invalid versions %q for %s: %w", name, d.Versions, d.ID, err)
+			}
+		}
+	}
+	for id, v := range p.MinVersions {
+		if _, err := semver.NewVersion(v); err != nil {
+			return nil, fmt.Errorf("%s: invalid minimum version %q for %s: %w", name, v, id, err)
+		}
+	}
+	return &p, nil
+}
+
+// CheckSource reports whether a configured source is allowed. The source
+// URL and every mirror must match the allowedSources of each policy, both as
+// configured and after the rewrite rules, which give the URLs actually
+// fetched from.
+func (s Set) CheckSource(alias string, cfg models.SourceConfig, rewrites []models.URLRewrite) []Violation {
+	var violations []Violation
+	for _, p := range s {
+		if len(p.AllowedSources) == 0 {
+			continue
+		}
+		if cfg.Type == models.SourceLocal {
+			if !p.AllowLocalSources {
+				violations = append(violations, Violation{
+					Source:  alias,
+					Rule:    "allowLocalSources",
+					Message: fmt.Sprintf("local source %s is not allowed by policy", alias),
+				})
+			}
+			continue
+		}
+		for _, u := range append([]string{cfg.URL}, cfg.Mirrors...) {
+			if !MatchURL(p.AllowedSources, u) {
+				violations = append(violations, Violation{
+					Source:  alias,
+					Rule:    "allowedSources",
+					Message: fmt.Sprintf("source %s (%s) is not in the allowed sources of the policy", alias, u),
+				})
+				continue
+			}
+			if fetched := config.RewriteURL(u, rewrites); !MatchURL(p.AllowedSources, fetched) {
+				violations = append(violations, Violation{
+					Source:  alias,
+					Rule:    "allowedSources",
+					Message: fmt.Sprintf("source %s (%s, rewritten to %s) is not in the allowed sources of the policy", alias, u, fetched),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// CheckAsset reports whether an asset version is allowed.
+func (s Set) CheckAsset(a Asset) []Violation {
+	var violations []Violation
+	add := func(rule, format string, args ...any) {
+		violations = append(violations, Violation{
+			Source:  a.Source,
+			Asset:   a.ID + "@" + a.Version,
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	v, verr := semver.NewVersion(a.Version)
+	for _, p := range s {
+		for _, d := range p.Deny {
+			if d.ID != a.ID {
+				continue
+			}
+			// A version that is not semver cannot be shown to be outside
+			// the denied range, so it is denied
+			if d.Versions != "" && verr == nil {
+				c, _ := semver.NewConstraint(d.Versions)
+				if !c.Check(v) {
+					continue
+				}
+			}
+			msg := fmt.Sprintf("%s@%s is denied by policy", a.ID, a.Version)
+			if d.Reason != "" {
+				msg += ": " + d.Reason
+			}
+			add("deny", "%s", msg)
+		}
+		if minStr, ok := p.MinVersions[a.ID]; ok {
+			minV, _ := semver.NewVersion(minStr)
+			if verr != nil || v.LessThan(minV) {
+				add("minVersions", "%s@%s is below the minimum version %s required by policy", a.ID, a.Version, minStr)
+			}
+		}
+		if p.RequireSignatures && a.SignedBy == "" {
+			add("requireSignatures", "%s@%s is not signed by a key its source trusts, as policy requires", a.ID, a.Version)
+		}
+	}
+	return violations
+}
+
+// Err returns the violations as one error, or nil when there are none.
+func Err(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		msgs[i] = v.Message
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// MatchURL reports whether a source URL matches one of the patterns. URLs
+// and patterns are compared as host/path, without scheme, credentials or a
+// ".git" suffix. A pattern matches the URLs it names and everything below
+// them, and "*" matches within a path segment: "github.com/my-org" and
+// "github.com/my-org/*" both match github.com/my-org/assets, and
+// "*.corp.example.com" matches every repository on its subdomains.
+func MatchURL(patterns []string, rawURL string) bool {
+	segments := strings.Split(normalizeURL(rawURL), "/")
+	for _, pattern := range patterns {
+		pattern = normalizeURL(pattern)
+		n := strings.Count(pattern, "/") + 1
+		if n > len(segments) {
+			continue
+		}
+		if ok, _ := path.Match(pattern, strings.Join(segments[:n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeURL turns https://user@host/org/repo.git, ssh://git@host/org/repo,
+// git@host:org/repo.git and oci://host/org/repo into host/org/repo.
+func normalizeURL(rawURL string) string {
+	u := strings.TrimSpace(rawURL)
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	} else if at, colon := strings.Index(u, "@"), strings.Index(u, ":"); at >= 0 && colon > at {
+		u = u[:colon] + "/" + u[colon+1:] // scp-like git URL
+	}
+	if at := strings.Index(u, "@"); at >= 0 && at < strings.IndexAny(u+"/", "/") {
+		u = u[at+1:]
+	}
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	if host, rest, ok := strings.Cut(u, "/"); ok {
+		return strings.ToLower(host) + "/" + rest
+	}
+	return strings.ToLower(u)
+}
+
+func sourceName(cfg models.SourceConfig) string {
+	if cfg.URL != "" {
+		return cfg.URL
+	}
+	return cfg.Path
+}
@@ -0,0 +1,164 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/source"
+)
+
+func TestMatchURL(t *testing.T) {
+	patterns := []string{"github.com/my-org", "*.corp.example.com", "gitlab.com/team/*-assets"}
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://github.com/my-org/assets", true},
+		{"https://token@github.com/my-org/assets.git", true},
+		{"git@github.com:my-org/assets.git", true},
+		{"ssh://git@GitHub.com/my-org/assets", true},
+		{"https://github.com/my-org-evil/assets", false},
+		{"https://github.com/other/assets", false},
+		{"https://git.corp.example.com/any/repo", true},
+		{"oci://registry.corp.example.com/org/assets", true},
+		{"https://corp.example.com.evil.io/repo", false},
+		{"https://gitlab.com/team/ai-assets", true},
+		{"https://gitlab.com/team/ai-tools", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := MatchURL(patterns, tt.url); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckAsset(t *testing.T) {
+	set := Set{
+		{
+			Deny: []models.DeniedAsset{
+				{ID: "rules", Versions: ">=1.0.0 <1.0.3", Reason: "leaks tokens"},
+				{ID: "banned"},
+			},
+			MinVersions: map[string]string{"rules": "1.0.0"},
+		},
+		{RequireSignatures: true},
+	}
+
+	tests := []struct {
+		name      string
+		asset     Asset
+		wantRules []string
+	}{
+		{"allowed", Asset{ID: "rules", Version: "1.1.0", SignedBy: "SHA256:key"}, nil},
+		{"denied range", Asset{ID: "rules", Version: "1.0.2", SignedBy: "SHA256:key"}, []string{"deny"}},
+		{"denied asset", Asset{ID: "banned", Version: "9.0.0", SignedBy: "SHA256:key"}, []string{"deny"}},
+		{"below minimum", Asset{ID: "rules", Version: "0.9.0", SignedBy: "SHA256:key"}, []string{"minVersions"}},
+		{"unsigned", Asset{ID: "other", Version: "1.0.0"}, []string{"requireSignatures"}},
+		{"not semver in a denied range", Asset{ID: "rules", Version: "main", SignedBy: "SHA256:key"}, []string{"deny", "minVersions"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, v := range set.CheckAsset(tt.asset) {
+				rules = append(rules, v.Rule)
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.wantRules, ",") {
+				t.Errorf("Expected violations %v, got %v", tt.wantRules, rules)
+			}
+		})
+	}
+
+	if err := Err(set.CheckAsset(Asset{ID: "rules", Version: "1.0.1"})); err == nil || !strings.Contains(err.Error(), "leaks tokens") {
+		t.Errorf("Expected the deny reason in the error, got %v", err)
+	}
+}
+
+func TestCheckSource(t *testing.T) {
+	set := Set{{AllowedSources: []string{"github.com/my-org"}}}
+
+	tests := []struct {
+		name string
+		cfg  models.SourceConfig
+		want int
+	}{
+		{"allowed", models.SourceConfig{Type: models.SourceGit, URL: "https://github.com/my-org/assets"}, 0},
+		{"not allowed", models.SourceConfig{Type: models.SourceGit, URL: "https://github.com/other/assets"}, 1},
+		{"mirror not allowed", models.SourceConfig{Type: models.SourceGit, URL: "https://github.com/my-org/assets", Mirrors: []string{"https://example.com/assets"}}, 1},
+		{"local", models.SourceConfig{Type: models.SourceLocal, Path: "../assets"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := set.CheckSource("org", tt.cfg, nil); len(got) != tt.want {
+				t.Errorf("Expected %d violations, got %v", tt.want, got)
+			}
+		})
+	}
+
+	rewrites := []models.URLRewrite{{URL: "https://evil.example.com/", InsteadOf: "https://github.com/"}}
+	allowed := models.SourceConfig{Type: models.SourceGit, URL: "https://github.com/my-org/assets"}
+	if got := set.CheckSource("org", allowed, rewrites); len(got) != 1 || !strings.Contains(got[0].Message, "evil.example.com") {
+		t.Errorf("Expected the rewritten URL to be refused, got %v", got)
+	}
+	internal := []models.URLRewrite{{URL: "https://github.com/my-org/mirror-", InsteadOf: "https://github.com/my-org/"}}
+	if got := set.CheckSource("org", allowed, internal); len(got) != 0 {
+		t.Errorf("Expected a rewrite within the allowed sources to pass, got %v", got)
+	}
+
+	set[0].AllowLocalSources = true
+	if got := set.CheckSource("org", models.SourceConfig{Type: models.SourceLocal, Path: "../assets"}, nil); len(got) != 0 {
+		t.Errorf("Expected local sources to be allowed, got %v", got)
+	}
+	if got := (Set{{}}).CheckSource("org", models.SourceConfig{Type: models.SourceGit, URL: "https://example.com/x"}, nil); len(got) != 0 {
+		t.Errorf("Expected every source to be allowed without allowedSources, got %v", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	open := func(cfg models.SourceConfig) (source.Source, error) {
+		return source.New(cfg, source.Options{})
+	}
+
+	root := t.TempDir()
+	if set, err := Load(t.Context(), root, open); err != nil || set != nil {
+		t.Fatalf("Expected no policy, got %v (%v)", set, err)
+	}
+
+	shared := t.TempDir()
+	os.WriteFile(filepath.Join(shared, "org-policy.yaml"), []byte("schema: \"1.0\"\nallowedSources:\n  - github.com/my-org\n"), 0644)
+	os.WriteFile(filepath.Join(root, FileName), []byte(`schema: "1.0"
+extends:
+  source:
+    type: local
+    path: `+shared+`
+  path: org-policy.yaml
+minVersions:
+  rules: 1.2.0
+`), 0644)
+
+	set, err := Load(t.Context(), root, open)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(set) != 2 || set[1].AllowedSources[0] != "github.com/my-org" {
+		t.Fatalf("Expected the project and the extended policy, got %+v", set)
+	}
+	if got := set.CheckSource("x", models.SourceConfig{Type: models.SourceGit, URL: "https://example.com/x"}, nil); len(got) != 1 {
+		t.Errorf("Expected the extended policy to apply, got %v", got)
+	}
+
+	os.WriteFile(filepath.Join(root, FileName), []byte("minVersions:\n  rules: not-a-version\n"), 0644)
+	if _, err := Load(t.Context(), root, open); err == nil {
+		t.Error("Expected an error for an invalid minimum version")
+	}
+
+	os.WriteFile(filepath.Join(root, FileName), []byte("extends:\n  source:\n    type: local\n    path: "+shared+"\n  path: missing.yaml\n"), 0644)
+	if _, err := Load(t.Context(), root, open); err == nil {
+		t.Error("Expected an error when the extended policy cannot be fetched")
+	}
+}
@@ -1,15 +1,30 @@
 package projector
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/adryledo/arca-cli/internal/fsutil"
 )
 
 // Projector handles mapping cached assets into the workspace via symlinks.
+// Projections replaced during a run are kept aside until Commit, so that
+// Rollback can restore the workspace when the run is cancelled or fails.
 type Projector struct {
 	WorkspaceRoot string
+
+	journal []projection
+}
+
+// projection records a link created by Project and the previous content of
+// its target, if any.
+type projection struct {
+	target string
+	backup string
 }
 
 func New(workspaceRoot string) *Projector {
@@ -18,7 +33,10 @@ func New(workspaceRoot string) *Projector {
 
 // Project creates a symlink from cachedPath to targetPath.
 // targetPath is relative to WorkspaceRoot.
-func (p *Projector) Project(cachedPath string, targetPath string, isDir bool) (string, error) {
+func (p *Projector) Project(ctx context.Context, cachedPath string, targetPath string, isDir bool) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	absTarget := filepath.Join(p.WorkspaceRoot, targetPath)
 
 	// Ensure parent dir exists
@@ -26,12 +44,16 @@ func (p *Projector) Project(cachedPath string, targetPath string, isDir bool) (s
 		return "", fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Remove existing if it's there
+	// Move existing aside until the run is committed
+	entry := projection{target: absTarget}
 	if _, err := os.Lstat(absTarget); err == nil {
-		if err := os.RemoveAll(absTarget); err != nil {
-			return "", fmt.Errorf("failed to remove existing projection: %w", err)
+		entry.backup = filepath.Join(filepath.Dir(absTarget), fmt.Sprintf(".%s.arca-old-%d", filepath.Base(absTarget), len(p.journal)))
+		os.RemoveAll(entry.backup)
+		if err := os.Rename(absTarget, entry.backup); err != nil {
+			return "", fmt.Errorf("failed to move existing projection aside: %w", err)
 		}
 	}
+	p.journal = append(p.journal, entry)
 
 	// Create symlink
 	// On Windows, this may require SeCreateSymbolicLinkPrivilege (Developer Mode)
@@ -52,6 +74,38 @@ func (p *Projector) Project(cachedPath string, targetPath string, isDir bool) (s
 	return absTarget, nil
 }
 
+// Commit deletes the projections replaced since the last Commit or Rollback.
+func (p *Projector) Commit() error {
+	var errs []error
+	for _, entry := range p.journal {
+		if entry.backup != "" {
+			errs = append(errs, os.RemoveAll(entry.backup))
+		}
+	}
+	p.journal = nil
+	return errors.Join(errs...)
+}
+
+// Rollback removes the links created since the last Commit or Rollback and
+// puts back what they replaced, newest first.
+func (p *Projector) Rollback() error {
+	var errs []error
+	for i := len(p.journal) - 1; i >= 0; i-- {
+		entry := p.journal[i]
+		if _, err := os.Lstat(entry.target); err == nil {
+			if err := os.RemoveAll(entry.target); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if entry.backup != "" {
+			errs = append(errs, os.Rename(entry.backup, entry.target))
+		}
+	}
+	p.journal = nil
+	return errors.Join(errs...)
+}
+
 // EnsureGitignored adds the projected path to the workspace .gitignore.
 func (p *Projector) EnsureGitignored(absPath string) error {
 	gitignorePath := filepath.Join(p.WorkspaceRoot, ".gitignore")
@@ -74,25 +128,16 @@ func (p *Projector) EnsureGitignored(absPath string) error {
 		}
 	}
 
-	// Append under ARCA marker
-	f, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
+	// Append under ARCA marker, rewriting the file atomically
+	var b strings.Builder
+	b.Write(content)
 	marker := "\n# ARCA managed assets\n"
 	if !strings.Contains(string(content), strings.TrimSpace(marker)) {
-		if _, err := f.WriteString(marker); err != nil {
-			return err
-		}
+		b.WriteString(marker)
 	}
+	b.WriteString(relPath + "\n")
 
-	if _, err := f.WriteString(relPath + "\n"); err != nil {
-		return err
-	}
-
-	return nil
+	return fsutil.WriteFileAtomic(gitignorePath, []byte(b.String()), 0644)
 }
 
 // RemoveProjection deletes the projected symlink.
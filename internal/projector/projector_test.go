@@ -1,6 +1,8 @@
 package projector
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,7 +28,7 @@ func TestProjector_Project(t *testing.T) {
 	// For testing, mock-project fallback: if Symlink fails, we create the file manually just to test Remove/EnsureGitignored.
 
 	targetRelPath := ".arca/instructions/test.md"
-	absTarget, err := p.Project(cachedFile, targetRelPath, false)
+	absTarget, err := p.Project(t.Context(), cachedFile, targetRelPath, false)
 
 	if err != nil {
 		// Log the warning, simulate partial success by copying file so other tests can proceed
@@ -92,3 +94,61 @@ func TestEnsureGitignored_ExistingContent(t *testing.T) {
 		t.Errorf("Expected .gitignore to contain updated section. Got: %s", string(content))
 	}
 }
+
+func TestProjector_Rollback(t *testing.T) {
+	wsDir := t.TempDir()
+	cacheDir := t.TempDir()
+	cachedFile := filepath.Join(cacheDir, "new.md")
+	if err := os.WriteFile(cachedFile, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(wsDir, ".arca", "old.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(wsDir)
+	if _, err := p.Project(t.Context(), cachedFile, ".arca/old.md", false); err != nil {
+		t.Skipf("Symlinks unavailable: %v", err)
+	}
+	if _, err := p.Project(t.Context(), cachedFile, ".arca/added.md", false); err != nil {
+		t.Fatalf("Project failed: %v", err)
+	}
+	if err := p.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "old" {
+		t.Errorf("Expected the replaced file to be restored, got %q (%v)", data, err)
+	}
+	if _, err := os.Lstat(filepath.Join(wsDir, ".arca", "added.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected the new projection to be removed")
+	}
+	entries, _ := os.ReadDir(filepath.Join(wsDir, ".arca"))
+	if len(entries) != 1 {
+		t.Errorf("Expected no leftovers, got %v", entries)
+	}
+
+	// Committed projections stay and their backups are deleted
+	if _, err := p.Project(t.Context(), cachedFile, ".arca/old.md", false); err != nil {
+		t.Fatalf("Project failed: %v", err)
+	}
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "new" {
+		t.Errorf("Expected the new projection, got %q (%v)", data, err)
+	}
+	entries, _ = os.ReadDir(filepath.Join(wsDir, ".arca"))
+	if len(entries) != 1 {
+		t.Errorf("Expected the backup to be deleted, got %v", entries)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := p.Project(ctx, cachedFile, ".arca/late.md", false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled projection to fail, got %v", err)
+	}
+}
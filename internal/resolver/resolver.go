@@ -1,56 +1,69 @@
 package resolver
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"strings"
+	"net/http"
+	"regexp"
 
 	"github.com/Masterminds/semver/v3"
-	"github.com/adryledo/arca-cli/internal/auth"
 	"github.com/adryledo/arca-cli/internal/models"
-	"github.com/go-git/go-billy/v5/memfs"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/adryledo/arca-cli/internal/netutil"
+	"github.com/adryledo/arca-cli/internal/source"
 	"gopkg.in/yaml.v3"
 )
 
 type Resolver struct {
 	WorkspaceRoot string
+	// CacheDir is handed to source providers for their own caches.
+	CacheDir string
+	// Rewrites are the insteadOf rules applied to source URLs.
+	Rewrites []models.URLRewrite
+	// Manifests, when set, keeps manifests at commits for offline use.
+	Manifests ManifestCache
+	// Network is the timeout and retry policy of network sources.
+	Network netutil.Policy
+	// HTTPClient carries the proxy and TLS settings of HTTP sources.
+	HTTPClient *http.Client
+
+	sources map[string]source.Source
 }
 
-func New(workspaceRoot string) *Resolver {
-	return &Resolver{WorkspaceRoot: workspaceRoot}
+// ManifestCache stores raw manifests by source and ref.
+type ManifestCache interface {
+	Manifest(source, ref string) ([]byte, bool)
+	StoreManifest(source, ref string, data []byte) error
 }
 
-// LoadManifest fetches and parses the arca-manifest.yaml from a source at a specific ref.
-func (r *Resolver) LoadManifest(source models.SourceConfig, ref string) (*models.Manifest, error) {
-	var data []byte
-	var err error
+// immutableRef matches refs whose manifest never changes: git commits and
+// OCI digests.
+var immutableRef = regexp.MustCompile(`^([0-9a-f]{40}|sha256:[0-9a-f]{64})$`)
+
+func New(workspaceRoot string) *Resolver {
+	return &Resolver{WorkspaceRoot: workspaceRoot, sources: make(map[string]source.Source)}
+}
 
-	if ref == "" {
-		ref = "main"
+// Source returns the provider for a source configuration. Providers are
+// reused so that a repository is only fetched once per command.
+func (r *Resolver) Source(cfg models.SourceConfig) (source.Source, error) {
+	key := string(cfg.Type) + "|" + cfg.URL + "|" + cfg.Path
+	if src, ok := r.sources[key]; ok {
+		return src, nil
 	}
+	src, err := source.New(cfg, source.Options{WorkspaceRoot: r.WorkspaceRoot, CacheDir: r.CacheDir, Rewrites: r.Rewrites, Network: r.Network, HTTPClient: r.HTTPClient})
+	if err != nil {
+		return nil, err
+	}
+	r.sources[key] = src
+	return src, nil
+}
 
-	switch source.Type {
-	case models.SourceLocal:
-		manifestPath := filepath.Join(source.Path, "arca-manifest.yaml")
-		if !filepath.IsAbs(manifestPath) {
-			manifestPath = filepath.Join(r.WorkspaceRoot, manifestPath)
-		}
-		data, err = os.ReadFile(manifestPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read local manifest: %w", err)
-		}
-	case models.SourceGit:
-		data, err = r.fetchManifestFromGit(source.URL, ref)
-		if err != nil {
-			return nil, err
-		}
-	default:
-		return nil, fmt.Errorf("unsupported source type: %s", source.Type)
+// LoadManifest fetches and parses the arca-manifest.yaml from a source at a
+// specific ref. An empty ref reads the source's default revision.
+func (r *Resolver) LoadManifest(ctx context.Context, cfg models.SourceConfig, ref string) (*models.Manifest, error) {
+	data, err := r.FetchManifest(ctx, cfg, ref)
+	if err != nil {
+		return nil, err
 	}
 
 	var manifest models.Manifest
@@ -61,46 +74,44 @@ func (r *Resolver) LoadManifest(source models.SourceConfig, ref string) (*models
 	return &manifest, nil
 }
 
-func (r *Resolver) fetchManifestFromGit(url string, ref string) ([]byte, error) {
-	// Clone manifest at specific ref
-	opts := &git.CloneOptions{
-		URL:           url,
-		ReferenceName: plumbing.ReferenceName("refs/heads/" + ref),
-		Depth:         1,
-	}
-	if a := auth.GetGitAuth(); a != nil {
-		opts.Auth = a
-	}
-
-	repo, err := git.Clone(memory.NewStorage(), memfs.New(), opts)
-	if err != nil {
-		// Try resolving as commit if branch fails?
-		// For simplicity, let's try a generic clone if specific fails
-		opts2 := &git.CloneOptions{
-			URL:   url,
-			Depth: 1,
+// FetchManifest returns the raw manifest of a source at ref. Manifests at
+// commits are served from the manifest cache when present and stored there
+// after download; when the source cannot be reached, a cached manifest for
+// any other non-empty ref is used as well, unless ctx was cancelled.
+func (r *Resolver) FetchManifest(ctx context.Context, cfg models.SourceConfig, ref string) ([]byte, error) {
+	key := ManifestSourceKey(cfg)
+	if r.Manifests != nil && immutableRef.MatchString(ref) {
+		if data, ok := r.Manifests.Manifest(key, ref); ok {
+			return data, nil
 		}
-		if a := auth.GetGitAuth(); a != nil {
-			opts2.Auth = a
-		}
-		repo, err = git.Clone(memory.NewStorage(), memfs.New(), opts2)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to clone manifest repo: %w", err)
 	}
 
-	wt, err := repo.Worktree()
+	src, err := r.Source(cfg)
 	if err != nil {
 		return nil, err
 	}
-
-	f, err := wt.Filesystem.Open("arca-manifest.yaml")
+	data, err := src.FetchManifest(ctx, ref)
 	if err != nil {
-		return nil, fmt.Errorf("arca-manifest.yaml not found in repo: %w", err)
+		if r.Manifests != nil && ref != "" && ctx.Err() == nil {
+			if cached, ok := r.Manifests.Manifest(key, ref); ok {
+				return cached, nil
+			}
+		}
+		return nil, err
+	}
+	if r.Manifests != nil && immutableRef.MatchString(ref) {
+		_ = r.Manifests.StoreManifest(key, ref, data)
 	}
-	defer f.Close()
+	return data, nil
+}
 
-	return io.ReadAll(f)
+// ManifestSourceKey identifies a source in the manifest cache: its canonical
+// URL, or its path for local sources.
+func ManifestSourceKey(cfg models.SourceConfig) string {
+	if cfg.URL != "" {
+		return cfg.URL
+	}
+	return cfg.Path
 }
 
 // ResolveVersion finds the best version matching a constraint for an asset.
@@ -163,22 +174,19 @@ func (r *Resolver) ResolveVersion(manifest *models.Manifest, assetID string, con
 		return "", models.ManifestVersion{}, fmt.Errorf("version %s not found", resolvedVersion)
 	}
 
-	// Apply version strategy if ref is missing
-	if meta.Ref == "" && manifest.VersionStrategy != nil && manifest.VersionStrategy.Template != "" {
-		meta.Ref = filepath.ToSlash(filepath.Join("", manifest.VersionStrategy.Template)) // Dummy way to replace for now? No.
-		// Real implementation of template replacement
-		meta.Ref = strings.ReplaceAll(manifest.VersionStrategy.Template, "{{version}}", resolvedVersion)
-	}
+	meta.Ref = manifest.VersionRef(resolvedVersion, meta)
 
 	return resolvedVersion, meta, nil
 }
 
 // ResolvedAssetGroup represents a group of assets that have been resolved.
 type ResolvedAssetGroup struct {
-	ID      string
-	Version string
-	Meta    models.ManifestVersion
-	Kind    models.AssetKind
+	ID           string
+	Version      string
+	Meta         models.ManifestVersion
+	Kind         models.AssetKind
+	License      string
+	Dependencies map[string]string
 }
 
 // ResolveGraph recursively resolves an asset and its dependencies.
@@ -206,10 +214,12 @@ func (r *Resolver) ResolveGraph(manifest *models.Manifest, initialID, initialCon
 
 		asset := manifest.Assets[item.id]
 		resolved[item.id] = ResolvedAssetGroup{
-			ID:      item.id,
-			Version: v,
-			Meta:    meta,
-			Kind:    asset.Kind,
+			ID:           item.id,
+			Version:      v,
+			Meta:         meta,
+			Kind:         asset.Kind,
+			License:      asset.License,
+			Dependencies: asset.Dependencies,
 		}
 
 		for depID, depConstraint := range asset.Dependencies {
@@ -1,9 +1,13 @@
 package resolver
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/source"
 )
 
 func TestResolveVersion(t *testing.T) {
@@ -82,3 +86,122 @@ func TestResolveVersionFallbacks(t *testing.T) {
 		t.Errorf("Expected beta, got %s", v)
 	}
 }
+
+// fakeSource serves manifests from memory, keyed by ref.
+type fakeSource struct {
+	source.Local
+	manifests map[string]string
+	calls     int
+}
+
+func (f *fakeSource) FetchManifest(ctx context.Context, ref string) ([]byte, error) {
+	f.calls++
+	data, ok := f.manifests[ref]
+	if !ok {
+		return nil, fmt.Errorf("no manifest at %s", ref)
+	}
+	return []byte(data), nil
+}
+
+func TestLoadManifest(t *testing.T) {
+	fake := &fakeSource{manifests: map[string]string{
+		"":    "schema: \"1.0\"\nassets:\n  demo:\n    kind: instruction\n    versions:\n      1.0.0: {path: demo.md}\n",
+		"v1":  "schema: \"1.0\"\nassets: {}\n",
+		"bad": "assets: [",
+	}}
+	const fakeType models.SourceType = "fake-manifest"
+	source.Register(fakeType, func(cfg models.SourceConfig, opts source.Options) (source.Source, error) {
+		return fake, nil
+	})
+
+	r := New(t.TempDir())
+	cfg := models.SourceConfig{Type: fakeType, URL: "fake://repo"}
+
+	m, err := r.LoadManifest(t.Context(), cfg, "")
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if _, ok := m.Assets["demo"]; !ok {
+		t.Errorf("Expected asset demo, got %v", m.Assets)
+	}
+
+	m, err = r.LoadManifest(t.Context(), cfg, "v1")
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if len(m.Assets) != 0 {
+		t.Errorf("Expected no assets at v1, got %d", len(m.Assets))
+	}
+
+	if _, err := r.LoadManifest(t.Context(), cfg, "bad"); err == nil {
+		t.Error("Expected parse error")
+	}
+
+	src, err := r.Source(cfg)
+	if err != nil || src != fake {
+		t.Errorf("Expected the provider to be reused, got %v (%v)", src, err)
+	}
+	if fake.calls != 3 {
+		t.Errorf("Expected 3 manifest fetches, got %d", fake.calls)
+	}
+
+	if _, err := r.LoadManifest(t.Context(), models.SourceConfig{Type: "unknown"}, ""); err == nil {
+		t.Error("Expected error for unsupported source type")
+	}
+}
+
+// memoryManifests is an in-memory ManifestCache.
+type memoryManifests map[string][]byte
+
+func (m memoryManifests) Manifest(source, ref string) ([]byte, bool) {
+	data, ok := m[source+"@"+ref]
+	return data, ok
+}
+
+func (m memoryManifests) StoreManifest(source, ref string, data []byte) error {
+	m[source+"@"+ref] = data
+	return nil
+}
+
+func TestFetchManifest_Cache(t *testing.T) {
+	commit := strings.Repeat("a", 40)
+	fake := &fakeSource{manifests: map[string]string{commit: "schema: \"1.0\"\n", "main": "schema: \"1.0\"\n"}}
+	const fakeType models.SourceType = "fake-manifest-cache"
+	source.Register(fakeType, func(cfg models.SourceConfig, opts source.Options) (source.Source, error) {
+		return fake, nil
+	})
+
+	cache := memoryManifests{"fake://repo@etag-1": []byte("cached")}
+	r := New(t.TempDir())
+	r.Manifests = cache
+	cfg := models.SourceConfig{Type: fakeType, URL: "fake://repo"}
+
+	// Commits are stored after download and then served from the cache
+	for i := 0; i < 2; i++ {
+		if _, err := r.FetchManifest(t.Context(), cfg, commit); err != nil {
+			t.Fatalf("FetchManifest failed: %v", err)
+		}
+	}
+	if fake.calls != 1 {
+		t.Errorf("Expected 1 fetch for a commit, got %d", fake.calls)
+	}
+
+	// Moving refs are always fetched and never stored
+	r.FetchManifest(t.Context(), cfg, "main")
+	r.FetchManifest(t.Context(), cfg, "main")
+	if fake.calls != 3 {
+		t.Errorf("Expected moving refs to be fetched every time, got %d fetches", fake.calls)
+	}
+	if _, ok := cache["fake://repo@main"]; ok {
+		t.Error("Expected a moving ref not to be cached")
+	}
+
+	// An unreachable source falls back to the cache
+	data, err := r.FetchManifest(t.Context(), cfg, "etag-1")
+	if err != nil || string(data) != "cached" {
+		t.Errorf("Expected the cached manifest, got %q (%v)", data, err)
+	}
+	if _, err := r.FetchManifest(t.Context(), cfg, "etag-2"); err == nil {
+		t.Error("Expected an error without a cached manifest")
+	}
+}
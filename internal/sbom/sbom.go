@@ -0,0 +1,380 @@
+// Package sbom renders the assets of a project as a software bill of
+// materials in the CycloneDX and SPDX JSON formats.
+package sbom
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/license"
+)
+
+// Format names accepted by Render.
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// ToolName identifies ARCA as the generator of a document.
+const ToolName = "arca"
+
+// Component is a locked asset version.
+type Component struct {
+	ID         string
+	Version    string
+	Source     string // source alias
+	SourceType string // git, local, http or oci
+	URL        string // canonical source URL
+	Commit     string // git commit, or the digest of the source index
+
+	SHA256      string
+	Kind        string
+	Description string
+	License     string   // declared license, an SPDX expression when valid; empty when unknown
+	DependsOn   []string // refs of the components this one depends on
+	Direct      bool     // declared in the project config rather than pulled in as a dependency
+}
+
+// Ref identifies a component within a document.
+func (c Component) Ref() string {
+	return c.Source + "/" + c.ID + "@" + c.Version
+}
+
+// PURL returns a generic package URL for the component.
+func (c Component) PURL() string {
+	purl := "pkg:generic/" + url.PathEscape(c.ID) + "@" + url.PathEscape(c.Version)
+	if loc := c.vcsURL(); loc != "" {
+		purl += "?vcs_url=" + url.QueryEscape(loc)
+	}
+	return purl
+}
+
+// vcsURL returns the git location of the component, pinned to its commit.
+// Only git sources have one; http and oci sources are distributions.
+func (c Component) vcsURL() string {
+	if c.SourceType != "git" || c.URL == "" || c.Commit == "" {
+		return ""
+	}
+	return "git+" + c.URL + "@" + c.Commit
+}
+
+// Inventory is the project and the components it uses.
+type Inventory struct {
+	Name       string
+	Created    time.Time
+	Components []Component
+}
+
+// Render encodes inv in the named format.
+func Render(inv Inventory, format string) ([]byte, error) {
+	switch format {
+	case FormatCycloneDX:
+		return CycloneDX(inv)
+	case FormatSPDX:
+		return SPDX(inv)
+	}
+	return nil, fmt.Errorf("unsupported sbom format %q (use %s or %s)", format, FormatCycloneDX, FormatSPDX)
+}
+
+// sortedComponents returns the components ordered by ref.
+func sortedComponents(inv Inventory) []Component {
+	comps := append([]Component{}, inv.Components...)
+	sort.Slice(comps, func(i, j int) bool { return comps[i].Ref() < comps[j].Ref() })
+	return comps
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// --- CycloneDX ---
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref,omitempty"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	Description        string           `json:"description,omitempty"`
+	PURL               string           `json:"purl,omitempty"`
+	Hashes             []cdxHash        `json:"hashes,omitempty"`
+	Licenses           []cdxLicense     `json:"licenses,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cdxLicense is either a license expression or, for a license that is not
+// a valid expression, a named license.
+type cdxLicense struct {
+	Expression string           `json:"expression,omitempty"`
+	License    *cdxNamedLicense `json:"license,omitempty"`
+}
+
+type cdxNamedLicense struct {
+	Name string `json:"name"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX encodes inv as a CycloneDX 1.5 JSON document.
+func CycloneDX(inv Inventory) ([]byte, error) {
+	comps := sortedComponents(inv)
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: inv.Created.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: ToolName}}},
+			Component: cdxComponent{Type: "application", BOMRef: inv.Name, Name: inv.Name},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+
+	root := cdxDependency{Ref: inv.Name, DependsOn: []string{}}
+	for _, c := range comps {
+		comp := cdxComponent{
+			Type:        "file",
+			BOMRef:      c.Ref(),
+			Name:        c.ID,
+			Version:     c.Version,
+			Description: c.Description,
+			PURL:        c.PURL(),
+			Properties:  []cdxProperty{{Name: "arca:source", Value: c.Source}},
+		}
+		if c.SHA256 != "" {
+			comp.Hashes = []cdxHash{{Alg: "SHA-256", Content: c.SHA256}}
+		}
+		if c.License != "" {
+			if license.Validate(c.License) == nil {
+				comp.Licenses = []cdxLicense{{Expression: c.License}}
+			} else {
+				comp.Licenses = []cdxLicense{{License: &cdxNamedLicense{Name: c.License}}}
+			}
+		}
+		if c.URL != "" {
+			refType := "distribution"
+			if c.SourceType == "git" {
+				refType = "vcs"
+			}
+			comp.ExternalReferences = []cdxExternalRef{{Type: refType, URL: c.URL}}
+		}
+		if c.Commit != "" {
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "arca:commit", Value: c.Commit})
+		}
+		if c.Kind != "" {
+			comp.Properties = append(comp.Properties, cdxProperty{Name: "arca:kind", Value: c.Kind})
+		}
+		doc.Components = append(doc.Components, comp)
+
+		deps := append([]string{}, c.DependsOn...)
+		sort.Strings(deps)
+		doc.Dependencies = append(doc.Dependencies, cdxDependency{Ref: c.Ref(), DependsOn: deps})
+		if c.Direct {
+			root.DependsOn = append(root.DependsOn, c.Ref())
+		}
+	}
+	doc.Dependencies = append([]cdxDependency{root}, doc.Dependencies...)
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// --- SPDX ---
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+	ExtractedLicenses []spdxLicenseInfo  `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxLicenseInfo struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Description      string            `json:"description,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const spdxNoAssertion = "NOASSERTION"
+
+var spdxInvalidChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxID turns a ref into an SPDX element identifier, which may only contain
+// letters, digits, dots and dashes. Replacing the other characters can map
+// distinct refs to the same name ("a/b-c@1" and "a-b/c@1"), so a short hash
+// of the ref keeps identifiers distinct.
+func spdxID(ref string) string {
+	return spdxName("SPDXRef-", ref)
+}
+
+// licenseRef names a declared license that is not a valid SPDX expression,
+// like "Apache 2.0", so that it can be listed among the extracted licenses.
+func licenseRef(declared string) string {
+	return spdxName("LicenseRef-", declared)
+}
+
+func spdxName(prefix, s string) string {
+	sum := sha256.Sum256([]byte(s))
+	name := strings.Trim(spdxInvalidChars.ReplaceAllString(s, "-"), "-")
+	return prefix + name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// SPDX encodes inv as an SPDX 2.3 JSON document.
+func SPDX(inv Inventory) ([]byte, error) {
+	comps := sortedComponents(inv)
+	rootID := spdxID("Project-" + inv.Name)
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              inv.Name,
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + url.PathEscape(inv.Name) + "-" + newUUID(),
+		CreationInfo: spdxCreationInfo{
+			Created:  inv.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + ToolName},
+		},
+		Packages: []spdxPackage{{
+			SPDXID:           rootID,
+			Name:             inv.Name,
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+		}},
+		Relationships: []spdxRelationship{{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: rootID}},
+	}
+
+	for _, c := range comps {
+		pkg := spdxPackage{
+			SPDXID:           spdxID(c.Ref()),
+			Name:             c.ID,
+			VersionInfo:      c.Version,
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			Description:      c.Description,
+			ExternalRefs:     []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.PURL()}},
+		}
+		if loc := c.vcsURL(); loc != "" {
+			pkg.DownloadLocation = loc
+		}
+		if c.SHA256 != "" {
+			pkg.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: c.SHA256}}
+		}
+		if c.License != "" {
+			pkg.LicenseDeclared = c.License
+			if license.Validate(c.License) != nil {
+				pkg.LicenseDeclared = licenseRef(c.License)
+				if !slices.ContainsFunc(doc.ExtractedLicenses, func(l spdxLicenseInfo) bool { return l.LicenseID == pkg.LicenseDeclared }) {
+					doc.ExtractedLicenses = append(doc.ExtractedLicenses, spdxLicenseInfo{LicenseID: pkg.LicenseDeclared, ExtractedText: c.License, Name: c.License})
+				}
+			}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+
+		if c.Direct {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: rootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: pkg.SPDXID})
+		}
+		deps := append([]string{}, c.DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: pkg.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: spdxID(dep)})
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
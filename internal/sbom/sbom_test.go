@@ -0,0 +1,184 @@
+package sbom
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testInventory() Inventory {
+	return Inventory{
+		Name:    "my-project",
+		Created: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Components: []Component{
+			{
+				ID: "python-refactor", Version: "1.2.0", Source: "team", SourceType: "git", URL: "https://github.com/my-org/assets",
+				Commit: "abc123", SHA256: "deadbeef", Kind: "skill", License: "Apache-2.0",
+				DependsOn: []string{"team/style-guide@0.3.0"}, Direct: true,
+			},
+			{ID: "style-guide", Version: "0.3.0", Source: "team", SourceType: "git", URL: "https://github.com/my-org/assets", Commit: "abc123", SHA256: "cafebabe", Kind: "instruction"},
+			{ID: "tester", Version: "2.0.0", Source: "vendor", SourceType: "oci", URL: "oci://registry.example.com/assets", Commit: "sha256:0a1b", SHA256: "feedface", Kind: "agent", Direct: true},
+		},
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	data, err := CycloneDX(testInventory())
+	if err != nil {
+		t.Fatalf("CycloneDX failed: %v", err)
+	}
+	var doc cdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || !strings.HasPrefix(doc.SerialNumber, "urn:uuid:") {
+		t.Errorf("Unexpected header: %s %s %s", doc.BOMFormat, doc.SpecVersion, doc.SerialNumber)
+	}
+	if len(doc.Components) != 3 {
+		t.Fatalf("Expected 3 components, got %d", len(doc.Components))
+	}
+	c := doc.Components[0]
+	if c.BOMRef != "team/python-refactor@1.2.0" {
+		t.Errorf("Expected components sorted by ref, got %s first", c.BOMRef)
+	}
+	if len(c.Licenses) != 1 || c.Licenses[0].Expression != "Apache-2.0" {
+		t.Errorf("Expected the Apache-2.0 license, got %v", c.Licenses)
+	}
+	if len(c.Hashes) != 1 || c.Hashes[0].Alg != "SHA-256" || c.Hashes[0].Content != "deadbeef" {
+		t.Errorf("Expected the SHA-256 digest, got %v", c.Hashes)
+	}
+	if c.PURL != "pkg:generic/python-refactor@1.2.0?vcs_url=git%2Bhttps%3A%2F%2Fgithub.com%2Fmy-org%2Fassets%40abc123" {
+		t.Errorf("Unexpected purl %s", c.PURL)
+	}
+	if len(c.ExternalReferences) != 1 || c.ExternalReferences[0].Type != "vcs" {
+		t.Errorf("Expected a vcs reference for a git source, got %v", c.ExternalReferences)
+	}
+	if len(doc.Components[1].Licenses) != 0 {
+		t.Errorf("Expected no license for an unlicensed asset, got %v", doc.Components[1].Licenses)
+	}
+	oci := doc.Components[2]
+	if oci.PURL != "pkg:generic/tester@2.0.0" {
+		t.Errorf("Expected no vcs_url for an oci source, got %s", oci.PURL)
+	}
+	if len(oci.ExternalReferences) != 1 || oci.ExternalReferences[0].Type != "distribution" || oci.ExternalReferences[0].URL != "oci://registry.example.com/assets" {
+		t.Errorf("Expected a distribution reference for an oci source, got %v", oci.ExternalReferences)
+	}
+
+	deps := make(map[string][]string)
+	for _, d := range doc.Dependencies {
+		deps[d.Ref] = d.DependsOn
+	}
+	if got := deps["my-project"]; len(got) != 2 || got[0] != "team/python-refactor@1.2.0" || got[1] != "vendor/tester@2.0.0" {
+		t.Errorf("Expected the project to depend on the direct assets only, got %v", got)
+	}
+	if got := deps["team/python-refactor@1.2.0"]; len(got) != 1 || got[0] != "team/style-guide@0.3.0" {
+		t.Errorf("Expected python-refactor to depend on style-guide, got %v", got)
+	}
+	if got, ok := deps["team/style-guide@0.3.0"]; !ok || len(got) != 0 {
+		t.Errorf("Expected an empty dependency entry for style-guide, got %v", got)
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	data, err := SPDX(testInventory())
+	if err != nil {
+		t.Fatalf("SPDX failed: %v", err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2026-10-18T12:00:00Z" {
+		t.Errorf("Unexpected header: %s %s", doc.SPDXVersion, doc.CreationInfo.Created)
+	}
+	if len(doc.Packages) != 4 {
+		t.Fatalf("Expected the project and 3 packages, got %d", len(doc.Packages))
+	}
+	pkg := doc.Packages[1]
+	if !strings.HasPrefix(pkg.SPDXID, "SPDXRef-team-python-refactor-1.2.0-") {
+		t.Errorf("Unexpected SPDX id %s", pkg.SPDXID)
+	}
+	if pkg.DownloadLocation != "git+https://github.com/my-org/assets@abc123" {
+		t.Errorf("Unexpected download location %s", pkg.DownloadLocation)
+	}
+	if pkg.LicenseDeclared != "Apache-2.0" || doc.Packages[2].LicenseDeclared != "NOASSERTION" {
+		t.Errorf("Unexpected declared licenses %s, %s", pkg.LicenseDeclared, doc.Packages[2].LicenseDeclared)
+	}
+	if loc := doc.Packages[3].DownloadLocation; loc != "NOASSERTION" {
+		t.Errorf("Expected no download location for an oci source, got %s", loc)
+	}
+
+	project, refactor, style, tester := spdxID("Project-my-project"), spdxID("team/python-refactor@1.2.0"), spdxID("team/style-guide@0.3.0"), spdxID("vendor/tester@2.0.0")
+	want := []string{
+		"SPDXRef-DOCUMENT DESCRIBES " + project,
+		project + " DEPENDS_ON " + refactor,
+		refactor + " DEPENDS_ON " + style,
+		project + " DEPENDS_ON " + tester,
+	}
+	if len(doc.Relationships) != len(want) {
+		t.Fatalf("Expected %d relationships, got %v", len(want), doc.Relationships)
+	}
+	for i, r := range doc.Relationships {
+		if got := r.SPDXElementID + " " + r.RelationshipType + " " + r.RelatedSPDXElement; got != want[i] {
+			t.Errorf("Expected %q, got %q", want[i], got)
+		}
+	}
+}
+
+func TestInvalidLicense(t *testing.T) {
+	inv := testInventory()
+	inv.Components[2].License = "Apache 2.0"
+
+	data, err := CycloneDX(inv)
+	if err != nil {
+		t.Fatalf("CycloneDX failed: %v", err)
+	}
+	var cdx cdxDocument
+	if err := json.Unmarshal(data, &cdx); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if l := cdx.Components[2].Licenses; len(l) != 1 || l[0].Expression != "" || l[0].License == nil || l[0].License.Name != "Apache 2.0" {
+		t.Errorf("Expected a named license instead of an invalid expression, got %+v", l)
+	}
+
+	data, err = SPDX(inv)
+	if err != nil {
+		t.Fatalf("SPDX failed: %v", err)
+	}
+	var spdx spdxDocument
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	ref := spdx.Packages[3].LicenseDeclared
+	if !strings.HasPrefix(ref, "LicenseRef-Apache-2.0-") {
+		t.Errorf("Expected a LicenseRef- identifier, got %s", ref)
+	}
+	if len(spdx.ExtractedLicenses) != 1 || spdx.ExtractedLicenses[0].LicenseID != ref || spdx.ExtractedLicenses[0].ExtractedText != "Apache 2.0" {
+		t.Errorf("Expected the license text to be extracted under %s, got %+v", ref, spdx.ExtractedLicenses)
+	}
+}
+
+func TestSPDXID(t *testing.T) {
+	refs := []string{"a/b-c@1.0.0", "a-b/c@1.0.0", "a/b/c@1.0.0", "a/b@c-1.0.0"}
+	seen := make(map[string]string)
+	for _, ref := range refs {
+		id := spdxID(ref)
+		if !regexp.MustCompile(`^SPDXRef-[A-Za-z0-9.-]+$`).MatchString(id) {
+			t.Errorf("Invalid SPDX id %q for %s", id, ref)
+		}
+		if other, ok := seen[id]; ok {
+			t.Errorf("Expected distinct ids for %s and %s, got %s", other, ref, id)
+		}
+		seen[id] = ref
+	}
+}
+
+func TestRender_UnknownFormat(t *testing.T) {
+	if _, err := Render(testInventory(), "swid"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,72 @@
+package scan
+
+// builtinRules catch what a compromised upstream typically hides in
+// instructions and skills. Patterns are matched line by line.
+var builtinRules = []Rule{
+	{
+		ID:          "hidden-unicode",
+		Severity:    SeverityHigh,
+		Description: "invisible or bidirectional control characters that hide text from reviewers",
+		Pattern:     `[\x{200B}-\x{200F}\x{202A}-\x{202E}\x{2060}-\x{2064}\x{2066}-\x{2069}\x{FEFF}\x{E0000}-\x{E007F}]`,
+	},
+	{
+		ID:          "nul-byte",
+		Severity:    SeverityHigh,
+		Description: "NUL bytes in a text file, which can hide content from reviewers and tools",
+		Pattern:     `\x00`,
+	},
+	{
+		ID:          "prompt-injection",
+		Severity:    SeverityHigh,
+		Description: "text that tries to override the agent's instructions",
+		Pattern:     `(?i)\b(ignore|disregard|forget)\s+(all\s+|any\s+)?(the\s+)?(previous|prior|above|earlier|system)\s+(instructions|prompts?|rules|messages)`,
+	},
+	{
+		ID:          "prompt-injection-concealment",
+		Severity:    SeverityHigh,
+		Description: "text that asks the agent to hide its actions from the user",
+		Pattern:     `(?i)\b(do\s+not|don't|never)\s+(tell|inform|mention\s+(this\s+)?to|reveal\s+(this\s+)?to|show\s+(this\s+)?to)\s+the\s+user`,
+	},
+	{
+		ID:          "private-key",
+		Severity:    SeverityCritical,
+		Description: "embedded private key",
+		Pattern:     `-----BEGIN ((RSA|EC|DSA|OPENSSH|ENCRYPTED|PGP) )?PRIVATE KEY( BLOCK)?-----`,
+	},
+	{
+		ID:          "aws-access-key",
+		Severity:    SeverityCritical,
+		Description: "embedded AWS access key id",
+		Pattern:     `\b(AKIA|ASIA)[0-9A-Z]{16}\b`,
+	},
+	{
+		ID:          "github-token",
+		Severity:    SeverityCritical,
+		Description: "embedded GitHub token",
+		Pattern:     `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`,
+	},
+	{
+		ID:          "slack-token",
+		Severity:    SeverityCritical,
+		Description: "embedded Slack token",
+		Pattern:     `\bxox[abposr]-[A-Za-z0-9-]{10,}`,
+	},
+	{
+		ID:          "generic-secret",
+		Severity:    SeverityMedium,
+		Description: "hard-coded credential assignment",
+		Pattern:     `(?i)\b(api[_-]?key|secret|token|password|passwd)\s*[:=]\s*["'][^"'\s]{12,}["']`,
+	},
+	{
+		ID:          "base64-blob",
+		Severity:    SeverityMedium,
+		Description: "long base64 blob that may carry a hidden payload",
+		Pattern:     `[A-Za-z0-9+/]{200,}={0,2}`,
+	},
+	{
+		ID:          "remote-script",
+		Severity:    SeverityMedium,
+		Description: "downloads a script and pipes it into a shell",
+		Pattern:     `(?i)\b(curl|wget)\b[^|\n]*\|\s*(sudo\s+)?(ba|z)?sh\b`,
+	},
+}
@@ -0,0 +1,278 @@
+// Package scan looks for content a compromised upstream may slip into
+// assets: prompt-injection payloads, hidden Unicode, base64 blobs and
+// embedded secrets. Instructions and skills are effectively code for
+// agents, so findings above a threshold block projection.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity ranks findings.
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+
+	// SeverityOff never blocks.
+	SeverityOff Severity = "off"
+	// DefaultFailOn is the lowest blocking severity when none is configured.
+	DefaultFailOn = SeverityHigh
+)
+
+var severityRank = map[Severity]int{SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3, SeverityCritical: 4}
+
+// ParseSeverity validates a severity name; "off" is accepted as a threshold.
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(s))
+	if _, ok := severityRank[sev]; ok || sev == SeverityOff {
+		return sev, nil
+	}
+	return "", fmt.Errorf("invalid severity %q: use low, medium, high, critical or off", s)
+}
+
+// AtLeast reports whether s is as severe as threshold. Nothing reaches "off".
+func (s Severity) AtLeast(threshold Severity) bool {
+	t, ok := severityRank[threshold]
+	return ok && severityRank[s] >= t
+}
+
+// Rule is a pattern matched against every line of an asset.
+type Rule struct {
+	ID          string   `yaml:"id"`
+	Severity    Severity `yaml:"severity"`
+	Description string   `yaml:"description"`
+	Pattern     string   `yaml:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// RuleFile is the format of local rule files.
+type RuleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Finding is a rule match in a file.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+	Match    string   `json:"match"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s:%d %s: %s (%s)", f.Severity, f.File, f.Line, f.Rule, f.Message, f.Match)
+}
+
+// Scanner matches assets against a set of rules.
+type Scanner struct {
+	Rules  []Rule
+	FailOn Severity
+
+	// skipUTF8 turns off the invalid-utf8 finding
+	skipUTF8 bool
+}
+
+// invalidUTF8Rule reports files that are not valid UTF-8. It is not a
+// pattern rule, but can be disabled like one.
+const invalidUTF8Rule = "invalid-utf8"
+
+// New returns a scanner with the built-in rules and the rules of the files
+// cfg lists, relative to root, minus the disabled ones. A nil cfg selects
+// the built-in rules and the default threshold.
+func New(cfg *models.ScanConfig, root string) (*Scanner, error) {
+	if cfg == nil {
+		cfg = &models.ScanConfig{}
+	}
+	s := &Scanner{FailOn: DefaultFailOn}
+	if cfg.FailOn != "" {
+		sev, err := ParseSeverity(cfg.FailOn)
+		if err != nil {
+			return nil, err
+		}
+		s.FailOn = sev
+	}
+
+	s.skipUTF8 = slices.Contains(cfg.Disable, invalidUTF8Rule)
+
+	rules := slices.Clone(builtinRules)
+	for _, file := range cfg.Rules {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scan rules: %w", err)
+		}
+		var rf RuleFile
+		if err := yaml.Unmarshal(data, &rf); err != nil {
+			return nil, fmt.Errorf("failed to parse scan rules %s: %w", file, err)
+		}
+		rules = append(rules, rf.Rules...)
+	}
+
+	for _, r := range rules {
+		if slices.Contains(cfg.Disable, r.ID) {
+			continue
+		}
+		if r.ID == "" || r.Pattern == "" {
+			return nil, fmt.Errorf("scan rule needs an id and a pattern")
+		}
+		if _, ok := severityRank[r.Severity]; !ok {
+			return nil, fmt.Errorf("scan rule %s: invalid severity %q", r.ID, r.Severity)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("scan rule %s: invalid pattern: %w", r.ID, err)
+		}
+		r.re = re
+		s.Rules = append(s.Rules, r)
+	}
+	return s, nil
+}
+
+// ScanPath scans a file, or every file below a directory. Files are named
+// relative to path in findings, or by their base name for a single file.
+func (s *Scanner) ScanPath(path string, isDir bool) ([]Finding, error) {
+	if !isDir {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// Single-file assets are instructions, which are always text
+		return s.scan(filepath.Base(path), data, true), nil
+	}
+
+	var findings []Finding
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(path, p)
+		findings = append(findings, s.Scan(filepath.ToSlash(rel), data)...)
+		return nil
+	})
+	return findings, err
+}
+
+// textExtensions are the file types always treated as text.
+var textExtensions = []string{".md", ".mdc", ".markdown", ".txt", ".yaml", ".yml", ".json", ".toml", ".xml", ".html", ".sh", ".ps1", ".py", ".js", ".ts"}
+
+// Scan matches every rule against the lines of a file. A byte order mark at
+// the start of the file is ignored. Every file is scanned, binary or not,
+// but NUL bytes are only reported in text files; see isText.
+func (s *Scanner) Scan(name string, data []byte) []Finding {
+	return s.scan(name, data, isText(name, data))
+}
+
+// isText reports whether a file is text: it has a text extension or is
+// valid UTF-8. A NUL byte alone does not make a file binary, or a single
+// NUL would hide a payload from the nul-byte rule.
+func isText(name string, data []byte) bool {
+	return slices.Contains(textExtensions, strings.ToLower(filepath.Ext(name))) || utf8.Valid(data)
+}
+
+// scan matches the rules against a file. Rules run on each line as is and,
+// when the line holds NUL bytes or invalid UTF-8, once more with those
+// removed, so that a stray byte inside a phrase does not break the match.
+// A file that is not valid UTF-8 is reported as such, since one invalid
+// byte is enough to make tools treat a file as binary and skip it.
+func (s *Scanner) scan(name string, data []byte, text bool) []Finding {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+
+	var findings []Finding
+	if !s.skipUTF8 && !utf8.Valid(data) {
+		findings = append(findings, invalidUTF8(name, data))
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), len(data)+1)
+	for line := 1; sc.Scan(); line++ {
+		raw := sc.Text()
+		lossy := strings.ToValidUTF8(strings.ReplaceAll(raw, "\x00", ""), "")
+		for _, r := range s.Rules {
+			if r.ID == "nul-byte" && !text {
+				continue
+			}
+			match := r.re.FindString(raw)
+			if match == "" && lossy != raw {
+				match = r.re.FindString(lossy)
+			}
+			if match == "" {
+				continue
+			}
+			findings = append(findings, Finding{
+				Rule:     r.ID,
+				Severity: r.Severity,
+				File:     name,
+				Line:     line,
+				Message:  r.Description,
+				Match:    excerpt(match),
+			})
+		}
+	}
+	return findings
+}
+
+// invalidUTF8 reports the first invalid UTF-8 sequence in data.
+func invalidUTF8(name string, data []byte) Finding {
+	offset := 0
+	for offset < len(data) {
+		r, size := utf8.DecodeRune(data[offset:])
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+		offset += size
+	}
+	return Finding{
+		Rule:     invalidUTF8Rule,
+		Severity: SeverityMedium,
+		File:     name,
+		Line:     bytes.Count(data[:offset], []byte("\n")) + 1,
+		Message:  "a file that is not valid UTF-8, which tools may skip as binary",
+		Match:    excerpt(string(data[offset : offset+1])),
+	}
+}
+
+// Blocking returns the findings at or above the scanner's threshold.
+func (s *Scanner) Blocking(findings []Finding) []Finding {
+	var blocking []Finding
+	for _, f := range findings {
+		if f.Severity.AtLeast(s.FailOn) {
+			blocking = append(blocking, f)
+		}
+	}
+	return blocking
+}
+
+// excerpt makes a match printable: invisible characters are escaped and long
+// matches, like base64 blobs, are shortened.
+func excerpt(match string) string {
+	suffix := ""
+	if r := []rune(match); len(r) > 40 {
+		match, suffix = string(r[:40]), "..."
+	}
+	return strings.Trim(fmt.Sprintf("%+q", match), `"`) + suffix
+}
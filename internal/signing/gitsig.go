@@ -0,0 +1,181 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/adryledo/arca-cli/internal/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrUnsigned reports a git commit or tag without a signature.
+var ErrUnsigned = errors.New("not signed")
+
+// gitNamespace is the namespace git uses for SSH signatures.
+const gitNamespace = "git"
+
+// Keyring holds the keys allowed to sign the commits and tags of a source.
+type Keyring struct {
+	SSH []ssh.PublicKey
+	PGP openpgp.EntityList
+}
+
+// Empty reports whether the keyring has no keys.
+func (k Keyring) Empty() bool {
+	return len(k.SSH) == 0 && len(k.PGP) == 0
+}
+
+// LoadKeyring returns the keys a trust policy allows to sign git refs: its
+// SSH keys and its GPG keys. A GPG key is an armored public key or the path
+// of a file holding one, relative to root.
+func LoadKeyring(policy *models.TrustPolicy, root string) (Keyring, error) {
+	var k Keyring
+	if policy == nil {
+		return k, nil
+	}
+	keys, err := ParseKeys(policy.Keys)
+	if err != nil {
+		return k, err
+	}
+	k.SSH = keys
+	for _, entry := range policy.GPGKeys {
+		armored := []byte(entry)
+		if !strings.Contains(entry, "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+			path := entry
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(root, path)
+			}
+			if armored, err = os.ReadFile(path); err != nil {
+				return k, fmt.Errorf("failed to read gpg key: %w", err)
+			}
+		}
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armored))
+		if err != nil {
+			return k, fmt.Errorf("invalid gpg key %q: %w", abbreviate(entry), err)
+		}
+		k.PGP = append(k.PGP, entities...)
+	}
+	return k, nil
+}
+
+// VerifyGitSignature checks the signature git stores in a commit or tag
+// (OpenPGP or SSH) over payload, the object encoded without its signature,
+// and returns the signer: the SHA256 fingerprint of an SSH key or the
+// fingerprint of an OpenPGP key.
+func VerifyGitSignature(keys Keyring, signature string, payload []byte) (string, error) {
+	switch {
+	case signature == "":
+		return "", ErrUnsigned
+	case strings.Contains(signature, "-----BEGIN SSH SIGNATURE-----"):
+		return verifySSHSig(keys.SSH, signature, payload)
+	case strings.Contains(signature, "-----BEGIN PGP SIGNATURE-----"):
+		if len(keys.PGP) == 0 {
+			return "", fmt.Errorf("signed with a gpg key but no gpg keys are trusted")
+		}
+		entity, err := openpgp.CheckArmoredDetachedSignature(keys.PGP, bytes.NewReader(payload), strings.NewReader(signature), nil)
+		if err != nil {
+			return "", fmt.Errorf("invalid gpg signature: %w", err)
+		}
+		return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint), nil
+	default:
+		return "", fmt.Errorf("unsupported signature format")
+	}
+}
+
+// sshSig is the blob of an SSH signature, after its "SSHSIG" preamble; see
+// PROTOCOL.sshsig in OpenSSH.
+type sshSig struct {
+	Version   uint32
+	PublicKey []byte
+	Namespace string
+	Reserved  string
+	HashAlg   string
+	Signature []byte
+}
+
+// sshSignedData is what an SSH signature signs, after the "SSHSIG" preamble.
+type sshSignedData struct {
+	Namespace string
+	Reserved  string
+	HashAlg   string
+	Hash      []byte
+}
+
+const sshSigMagic = "SSHSIG"
+
+func verifySSHSig(trusted []ssh.PublicKey, armored string, payload []byte) (string, error) {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return "", fmt.Errorf("malformed ssh signature")
+	}
+	blob, ok := bytes.CutPrefix(block.Bytes, []byte(sshSigMagic))
+	if !ok {
+		return "", fmt.Errorf("malformed ssh signature")
+	}
+	var sig sshSig
+	if err := ssh.Unmarshal(blob, &sig); err != nil {
+		return "", fmt.Errorf("malformed ssh signature: %w", err)
+	}
+	if sig.Version != 1 {
+		return "", fmt.Errorf("unsupported ssh signature version %d", sig.Version)
+	}
+	if sig.Namespace != gitNamespace {
+		return "", fmt.Errorf("ssh signature is for namespace %q, not %q", sig.Namespace, gitNamespace)
+	}
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("malformed ssh signature: %w", err)
+	}
+	fingerprint := ssh.FingerprintSHA256(pub)
+	if !containsKey(trusted, pub) {
+		return "", fmt.Errorf("signed by untrusted key %s", fingerprint)
+	}
+
+	var h hash.Hash
+	switch sig.HashAlg {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported ssh signature hash %q", sig.HashAlg)
+	}
+	h.Write(payload)
+	signed := append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{
+		Namespace: sig.Namespace,
+		Reserved:  sig.Reserved,
+		HashAlg:   sig.HashAlg,
+		Hash:      h.Sum(nil),
+	})...)
+
+	var s ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &s); err != nil {
+		return "", fmt.Errorf("malformed ssh signature: %w", err)
+	}
+	if s.Format == ssh.KeyAlgoRSA {
+		return "", fmt.Errorf("SHA-1 RSA signatures are not accepted")
+	}
+	if err := pub.Verify(signed, &s); err != nil {
+		return "", fmt.Errorf("invalid ssh signature by %s: %w", fingerprint, err)
+	}
+	return fingerprint, nil
+}
+
+func containsKey(keys []ssh.PublicKey, key ssh.PublicKey) bool {
+	marshaled := key.Marshal()
+	for _, k := range keys {
+		if bytes.Equal(k.Marshal(), marshaled) {
+			return true
+		}
+	}
+	return false
+}
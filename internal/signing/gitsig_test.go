@@ -0,0 +1,144 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/adryledo/arca-cli/internal/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSign produces an armored SSH signature like `ssh-keygen -Y sign -n namespace`.
+func sshSign(t *testing.T, signer ssh.Signer, namespace string, payload []byte) string {
+	t.Helper()
+	h := sha512.Sum512(payload)
+	signed := append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{Namespace: namespace, HashAlg: "sha512", Hash: h[:]})...)
+	sig, err := signer.Sign(rand.Reader, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := append([]byte(sshSigMagic), ssh.Marshal(sshSig{
+		Version:   1,
+		PublicKey: signer.PublicKey().Marshal(),
+		Namespace: namespace,
+		HashAlg:   "sha512",
+		Signature: ssh.Marshal(sig),
+	})...)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob}))
+}
+
+func newEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Release", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func pgpSign(t *testing.T, entity *openpgp.Entity, payload []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, entity, bytes.NewReader(payload), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func armoredPublicKey(t *testing.T, entity *openpgp.Entity) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return buf.String()
+}
+
+func TestVerifyGitSignature(t *testing.T) {
+	payload := []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbc4904d\n\nrelease\n")
+	sshKey := newSigner(t)
+	otherSSH := newSigner(t)
+	pgpKey := newEntity(t)
+	otherPGP := newEntity(t)
+	keys := Keyring{SSH: []ssh.PublicKey{sshKey.PublicKey()}, PGP: openpgp.EntityList{pgpKey}}
+
+	tests := []struct {
+		name       string
+		signature  string
+		payload    []byte
+		wantSigner string
+		wantErr    bool
+	}{
+		{"trusted ssh key", sshSign(t, sshKey, "git", payload), payload, ssh.FingerprintSHA256(sshKey.PublicKey()), false},
+		{"untrusted ssh key", sshSign(t, otherSSH, "git", payload), payload, "", true},
+		{"tampered ssh payload", sshSign(t, sshKey, "git", payload), []byte("tampered"), "", true},
+		{"wrong namespace", sshSign(t, sshKey, "file", payload), payload, "", true},
+		{"trusted gpg key", pgpSign(t, pgpKey, payload), payload, fmt.Sprintf("%X", pgpKey.PrimaryKey.Fingerprint), false},
+		{"untrusted gpg key", pgpSign(t, otherPGP, payload), payload, "", true},
+		{"tampered gpg payload", pgpSign(t, pgpKey, payload), []byte("tampered"), "", true},
+		{"unsupported format", "garbage", payload, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := VerifyGitSignature(keys, tt.signature, tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got signer %q", signer)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyGitSignature failed: %v", err)
+			}
+			if signer != tt.wantSigner {
+				t.Errorf("Expected signer %s, got %s", tt.wantSigner, signer)
+			}
+		})
+	}
+
+	if _, err := VerifyGitSignature(keys, "", payload); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Expected ErrUnsigned, got %v", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	root := t.TempDir()
+	entity := newEntity(t)
+	armored := armoredPublicKey(t, entity)
+	if err := os.WriteFile(filepath.Join(root, "release.asc"), []byte(armored), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sshKey := newSigner(t)
+
+	keys, err := LoadKeyring(&models.TrustPolicy{
+		Keys:    []string{PublicKeyLine(sshKey.PublicKey(), "release")},
+		GPGKeys: []string{"release.asc", armored},
+	}, root)
+	if err != nil {
+		t.Fatalf("LoadKeyring failed: %v", err)
+	}
+	if len(keys.SSH) != 1 || len(keys.PGP) != 2 {
+		t.Errorf("Expected 1 ssh and 2 gpg keys, got %d and %d", len(keys.SSH), len(keys.PGP))
+	}
+
+	if _, err := LoadKeyring(&models.TrustPolicy{GPGKeys: []string{"missing.asc"}}, root); err == nil {
+		t.Error("Expected an error for a missing gpg key file")
+	}
+	if keys, err := LoadKeyring(nil, root); err != nil || !keys.Empty() {
+		t.Errorf("Expected an empty keyring without a policy, got %v (%v)", keys, err)
+	}
+}
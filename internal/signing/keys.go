@@ -0,0 +1,144 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// KeyEnv points at the private key `arca publish --sign` uses by default.
+	KeyEnv = "ARCA_SIGNING_KEY"
+	// KeyPassphraseEnv holds the passphrase of an encrypted signing key.
+	KeyPassphraseEnv = "ARCA_SIGNING_KEY_PASSPHRASE"
+	// DefaultKeyName is the key generated and used when no name is given.
+	DefaultKeyName = "default"
+)
+
+// KeyInfo describes a key pair in the keys directory.
+type KeyInfo struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Type        string `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+	PublicKey   string `json:"publicKey"` // authorized_keys line for trust policies
+}
+
+// Generate creates an ed25519 key pair named name in dir: the private key in
+// OpenSSH format and name.pub next to it. Existing keys are never replaced.
+func Generate(dir, name string) (*KeyInfo, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid key name %q", name)
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("key %s already exists", path)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(priv, name)
+	if err != nil {
+		return nil, err
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := pem.Encode(f, block); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	info := newKeyInfo(name, path, sshPub)
+	if err := os.WriteFile(path+".pub", []byte(info.PublicKey+"\n"), 0644); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return info, nil
+}
+
+// ListKeys returns the key pairs in dir, found by their .pub files.
+func ListKeys(dir string) ([]KeyInfo, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	keys := []KeyInfo{}
+	for _, pubPath := range matches {
+		data, err := os.ReadFile(pubPath)
+		if err != nil {
+			return nil, err
+		}
+		pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", pubPath, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(pubPath), ".pub")
+		keys = append(keys, *newKeyInfo(name, strings.TrimSuffix(pubPath, ".pub"), pub))
+	}
+	return keys, nil
+}
+
+// LoadSigner reads a private key in OpenSSH or PEM format. Encrypted keys
+// are opened with the passphrase in ARCA_SIGNING_KEY_PASSPHRASE.
+func LoadSigner(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		passphrase := os.Getenv(KeyPassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("signing key %s is encrypted; set %s", path, KeyPassphraseEnv)
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// PublicKeyLine formats a public key as an authorized_keys line.
+func PublicKeyLine(pub ssh.PublicKey, comment string) string {
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+	if comment != "" {
+		line += " " + comment
+	}
+	return line
+}
+
+func newKeyInfo(name, path string, pub ssh.PublicKey) *KeyInfo {
+	return &KeyInfo{
+		Name:        name,
+		Path:        path,
+		Type:        pub.Type(),
+		Fingerprint: ssh.FingerprintSHA256(pub),
+		PublicKey:   PublicKeyLine(pub, name),
+	}
+}
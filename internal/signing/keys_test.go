@@ -0,0 +1,62 @@
+package signing
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+
+	info, err := Generate(dir, "release")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if info.Type != "ssh-ed25519" {
+		t.Errorf("Expected an ed25519 key, got %s", info.Type)
+	}
+	if st, err := os.Stat(info.Path); err != nil {
+		t.Fatalf("Expected the private key to be written: %v", err)
+	} else if runtime.GOOS != "windows" && st.Mode().Perm() != 0600 {
+		t.Errorf("Expected the private key to be 0600, got %v", st.Mode().Perm())
+	}
+	if _, err := Generate(dir, "release"); err == nil {
+		t.Error("Expected an existing key not to be replaced")
+	}
+	for _, name := range []string{"", "../x", ".hidden"} {
+		if _, err := Generate(dir, name); err == nil {
+			t.Errorf("Expected key name %q to be rejected", name)
+		}
+	}
+
+	keys, err := ListKeys(dir)
+	if err != nil {
+		t.Fatalf("ListKeys failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != *info {
+		t.Errorf("Expected %+v, got %+v", *info, keys)
+	}
+
+	// A generated key signs versions the public key line verifies
+	signer, err := LoadSigner(info.Path)
+	if err != nil {
+		t.Fatalf("LoadSigner failed: %v", err)
+	}
+	entry := Entry{Asset: "rules", Version: "1.0.0", Kind: models.KindInstruction, SHA256: "abc"}
+	sig, err := Sign(signer, Payload(entry))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	policy := &models.TrustPolicy{Keys: []string{info.PublicKey}, RequireSignatures: true}
+	if signedBy, err := Verify(policy, entry, []models.Signature{sig}); err != nil || signedBy != info.Fingerprint {
+		t.Errorf("Expected a signature by %s, got %q (%v)", info.Fingerprint, signedBy, err)
+	}
+
+	if _, err := LoadSigner(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing key")
+	}
+}
@@ -0,0 +1,175 @@
+// Package signing signs manifest versions and checks them against the keys
+// a consumer trusts. Keys are SSH keys: ed25519 keys made by `arca keys
+// generate` or existing SSH keys, and public keys are written in
+// authorized_keys format.
+package signing
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// payloadHeader versions the signed payload format.
+const payloadHeader = "arca-signature-v2"
+
+// Entry is the part of a manifest entry that a signature covers: everything
+// that decides what a consumer installs. The asset's license and
+// dependencies are shared by all its versions, so changing them invalidates
+// the signatures of earlier versions until they are signed again.
+//
+// The location of the content (the version's ref and path) is deliberately
+// not covered: arca publish pins the previous version to a commit and an OCI
+// push pins the version to a layer digest after signing, and the content
+// found there is bound by the sha256 anyway. The description and runtime
+// hints are not covered either.
+type Entry struct {
+	Asset        string
+	Version      string
+	Kind         models.AssetKind
+	License      string
+	Dependencies map[string]string // asset id -> version constraint
+	SHA256       string
+}
+
+// NewEntry returns the signed part of version of an asset in a manifest.
+func NewEntry(assetID, version string, asset models.ManifestAsset) Entry {
+	return Entry{
+		Asset:        assetID,
+		Version:      version,
+		Kind:         asset.Kind,
+		License:      asset.License,
+		Dependencies: asset.Dependencies,
+		SHA256:       asset.Versions[version].SHA256,
+	}
+}
+
+// Payload returns the bytes signed for a manifest entry. Values are quoted
+// and dependencies sorted, so every entry has exactly one encoding.
+func Payload(e Entry) []byte {
+	payload := fmt.Appendf(nil, "%s\nasset %q\nversion %q\nkind %q\nlicense %q\nsha256 %q\n",
+		payloadHeader, e.Asset, e.Version, e.Kind, e.License, e.SHA256)
+	deps := slices.Sorted(maps.Keys(e.Dependencies))
+	for _, id := range deps {
+		payload = fmt.Appendf(payload, "dependency %q %q\n", id, e.Dependencies[id])
+	}
+	return payload
+}
+
+// Sign signs payload with signer. RSA keys sign with SHA-512 rather than
+// the SHA-1 default of the SSH protocol.
+func Sign(signer ssh.Signer, payload []byte) (models.Signature, error) {
+	var sig *ssh.Signature
+	var err error
+	if as, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, payload, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = signer.Sign(rand.Reader, payload)
+	}
+	if err != nil {
+		return models.Signature{}, fmt.Errorf("failed to sign: %w", err)
+	}
+	return models.Signature{
+		Key: ssh.FingerprintSHA256(signer.PublicKey()),
+		Sig: base64.StdEncoding.EncodeToString(ssh.Marshal(sig)),
+	}, nil
+}
+
+// AddSignature adds sig to sigs, replacing an earlier signature by the same key.
+func AddSignature(sigs []models.Signature, sig models.Signature) []models.Signature {
+	out := []models.Signature{}
+	for _, s := range sigs {
+		if s.Key != sig.Key {
+			out = append(out, s)
+		}
+	}
+	return append(out, sig)
+}
+
+// ParseKeys parses authorized_keys lines, as listed in a source's trust policy.
+func ParseKeys(lines []string) ([]ssh.PublicKey, error) {
+	keys := make([]ssh.PublicKey, 0, len(lines))
+	for _, line := range lines {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted key %q: %w", abbreviate(line), err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Verify checks the signatures of a manifest entry against a trust policy
+// and returns the fingerprint of the trusted key that signed it. A
+// signature that claims a trusted key but does not verify is always an
+// error. Versions without a trusted signature are only refused when the
+// policy requires signatures; then the empty fingerprint is returned.
+func Verify(policy *models.TrustPolicy, e Entry, sigs []models.Signature) (string, error) {
+	if policy == nil || (len(policy.Keys) == 0 && !policy.RequireSignatures) {
+		return "", nil
+	}
+	if len(policy.Keys) == 0 {
+		return "", fmt.Errorf("source requires signatures but trusts no keys")
+	}
+	keys, err := ParseKeys(policy.Keys)
+	if err != nil {
+		return "", err
+	}
+	trusted := make(map[string]ssh.PublicKey, len(keys))
+	for _, k := range keys {
+		trusted[ssh.FingerprintSHA256(k)] = k
+	}
+
+	assetID, version := e.Asset, e.Version
+	payload := Payload(e)
+	for _, s := range sigs {
+		key, ok := trusted[s.Key]
+		if !ok {
+			continue
+		}
+		if err := verifySignature(key, payload, s.Sig); err != nil {
+			return "", fmt.Errorf("%s@%s has an invalid signature by %s: %w", assetID, version, s.Key, err)
+		}
+		if e.SHA256 == "" {
+			return "", fmt.Errorf("%s@%s is signed without a sha256", assetID, version)
+		}
+		return s.Key, nil
+	}
+	if policy.RequireSignatures {
+		if len(sigs) == 0 {
+			return "", fmt.Errorf("%s@%s is not signed and the source requires signatures", assetID, version)
+		}
+		return "", fmt.Errorf("%s@%s is not signed by a trusted key", assetID, version)
+	}
+	return "", nil
+}
+
+func verifySignature(key ssh.PublicKey, payload []byte, encoded string) error {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+	var sig ssh.Signature
+	if err := ssh.Unmarshal(raw, &sig); err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+	if sig.Format == ssh.KeyAlgoRSA {
+		return fmt.Errorf("SHA-1 RSA signatures are not accepted")
+	}
+	return key.Verify(payload, &sig)
+}
+
+// abbreviate shortens a key line for error messages.
+func abbreviate(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > 40 {
+		return line[:40] + "..."
+	}
+	return line
+}
@@ -0,0 +1,160 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"golang.org/x/crypto/ssh"
+)
+
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func testEntry(sha string) Entry {
+	return Entry{
+		Asset:        "rules",
+		Version:      "1.0.0",
+		Kind:         models.KindInstruction,
+		License:      "MIT",
+		Dependencies: map[string]string{"base": "^1.0.0", "style": "~0.3.0"},
+		SHA256:       sha,
+	}
+}
+
+func sign(t *testing.T, signer ssh.Signer, e Entry) []models.Signature {
+	t.Helper()
+	sig, err := Sign(signer, Payload(e))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	return []models.Signature{sig}
+}
+
+func TestVerify(t *testing.T) {
+	publisher := newSigner(t)
+	other := newSigner(t)
+	entry := testEntry(strings.Repeat("a", 64))
+	trusted := []string{PublicKeyLine(publisher.PublicKey(), "publisher")}
+	signed := sign(t, publisher, entry)
+
+	tamper := func(f func(e *Entry)) Entry {
+		e := testEntry(entry.SHA256)
+		f(&e)
+		return e
+	}
+
+	tests := []struct {
+		name       string
+		policy     *models.TrustPolicy
+		entry      Entry
+		sigs       []models.Signature
+		wantSigner bool
+		wantErr    string
+	}{
+		{name: "no policy", policy: nil, entry: Entry{}},
+		{name: "trusted signature", policy: &models.TrustPolicy{Keys: trusted}, entry: entry, sigs: signed, wantSigner: true},
+		{name: "tampered digest", policy: &models.TrustPolicy{Keys: trusted}, entry: tamper(func(e *Entry) { e.SHA256 = strings.Repeat("b", 64) }), sigs: signed, wantErr: "invalid signature"},
+		{name: "other version", policy: &models.TrustPolicy{Keys: trusted}, entry: tamper(func(e *Entry) { e.Version = "2.0.0" }), sigs: signed, wantErr: "invalid signature"},
+		{name: "tampered license", policy: &models.TrustPolicy{Keys: trusted}, entry: tamper(func(e *Entry) { e.License = "GPL-3.0-only" }), sigs: signed, wantErr: "invalid signature"},
+		{name: "added dependency", policy: &models.TrustPolicy{Keys: trusted}, entry: tamper(func(e *Entry) { e.Dependencies["evil"] = "*" }), sigs: signed, wantErr: "invalid signature"},
+		{name: "changed constraint", policy: &models.TrustPolicy{Keys: trusted}, entry: tamper(func(e *Entry) { e.Dependencies["base"] = ">=0.1.0" }), sigs: signed, wantErr: "invalid signature"},
+		{name: "removed dependencies", policy: &models.TrustPolicy{Keys: trusted}, entry: tamper(func(e *Entry) { e.Dependencies = nil }), sigs: signed, wantErr: "invalid signature"},
+		{name: "unsigned, optional", policy: &models.TrustPolicy{Keys: trusted}, entry: entry},
+		{name: "unsigned, required", policy: &models.TrustPolicy{Keys: trusted, RequireSignatures: true}, entry: entry, wantErr: "not signed"},
+		{name: "untrusted key, optional", policy: &models.TrustPolicy{Keys: trusted}, entry: entry, sigs: sign(t, other, entry)},
+		{name: "untrusted key, required", policy: &models.TrustPolicy{Keys: trusted, RequireSignatures: true}, entry: entry, sigs: sign(t, other, entry), wantErr: "not signed by a trusted key"},
+		{name: "required without keys", policy: &models.TrustPolicy{RequireSignatures: true}, entry: entry, sigs: signed, wantErr: "trusts no keys"},
+		{name: "invalid key", policy: &models.TrustPolicy{Keys: []string{"ssh-ed25519 nope"}}, entry: entry, sigs: signed, wantErr: "invalid trusted key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := Verify(tt.policy, tt.entry, tt.sigs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if want := ssh.FingerprintSHA256(publisher.PublicKey()); tt.wantSigner && signer != want {
+				t.Errorf("Expected signer %s, got %q", want, signer)
+			}
+			if !tt.wantSigner && signer != "" {
+				t.Errorf("Expected no signer, got %s", signer)
+			}
+		})
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	asset := models.ManifestAsset{
+		Kind:         models.KindInstruction,
+		License:      "MIT",
+		Dependencies: map[string]string{"base": "^1.0.0", "style": "~0.3.0"},
+		Versions: map[string]models.ManifestVersion{
+			"1.0.0": {Path: "rules.md", SHA256: strings.Repeat("a", 64)},
+		},
+	}
+	pinned := asset
+	pinned.Description = "Updated description"
+	pinned.Versions = map[string]models.ManifestVersion{
+		"1.0.0": {Path: "docs/rules.md", Ref: "0123abc", SHA256: strings.Repeat("a", 64)},
+	}
+
+	// The location and description are not covered, so pinning a ref or
+	// moving the file keeps the signature valid
+	want := Payload(testEntry(strings.Repeat("a", 64)))
+	for _, a := range []models.ManifestAsset{asset, pinned} {
+		if got := Payload(NewEntry("rules", "1.0.0", a)); string(got) != string(want) {
+			t.Errorf("Expected payload\n%s\ngot\n%s", want, got)
+		}
+	}
+}
+
+func TestPayload_Unambiguous(t *testing.T) {
+	a := Payload(Entry{Asset: "rules", Version: "1.0.0", License: "MIT\nsha256 x"})
+	b := Payload(Entry{Asset: "rules", Version: "1.0.0", License: "MIT", SHA256: "x"})
+	if string(a) == string(b) {
+		t.Error("Expected distinct entries to have distinct payloads")
+	}
+}
+
+func TestSign_RSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := testEntry(strings.Repeat("c", 64))
+	policy := &models.TrustPolicy{Keys: []string{PublicKeyLine(signer.PublicKey(), "")}, RequireSignatures: true}
+	if _, err := Verify(policy, entry, sign(t, signer, entry)); err != nil {
+		t.Errorf("Expected an rsa-sha2-512 signature to verify, got %v", err)
+	}
+}
+
+func TestAddSignature(t *testing.T) {
+	sigs := []models.Signature{{Key: "SHA256:a", Sig: "old"}, {Key: "SHA256:b", Sig: "b"}}
+	sigs = AddSignature(sigs, models.Signature{Key: "SHA256:a", Sig: "new"})
+	if len(sigs) != 2 || sigs[1].Sig != "new" || sigs[0].Key != "SHA256:b" {
+		t.Errorf("Expected the signature by the same key to be replaced, got %v", sigs)
+	}
+}
@@ -0,0 +1,176 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Decompressed size limits for archives, so that a gzip or zip bomb from a
+// source cannot exhaust memory. Variables so that tests can lower them.
+var (
+	maxArchiveEntrySize int64 = 32 << 20  // per file
+	maxArchiveSize      int64 = 128 << 20 // all files together
+)
+
+// archiveFile is a regular file read from an archive.
+type archiveFile struct {
+	Name string
+	Data []byte
+	Exec bool
+}
+
+// isArchive reports whether name is an archive format extractArchive understands.
+func isArchive(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip")
+}
+
+// extractArchive unpacks a .tar.gz/.tgz or .zip archive into destDir. When
+// every entry sits under one top-level directory, that directory is stripped
+// so that `demo/SKILL.md` and `SKILL.md` archives unpack the same way. Only
+// regular files are extracted; entries escaping destDir are rejected.
+func extractArchive(name string, data []byte, destDir string) error {
+	var files []archiveFile
+	var err error
+	if strings.HasSuffix(strings.ToLower(name), ".zip") {
+		files, err = readZip(data)
+	} else {
+		files, err = readTarGz(data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read archive %s: %w", name, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("archive %s contains no files", name)
+	}
+
+	prefix := commonTopDir(files)
+	for _, f := range files {
+		rel := strings.TrimPrefix(f.Name, prefix)
+		dest := filepath.Join(destDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		perm := os.FileMode(0644)
+		if f.Exec {
+			perm = 0755
+		}
+		if err := os.WriteFile(dest, f.Data, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readTarGz(data []byte) ([]archiveFile, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var files []archiveFile
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := safeArchivePath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		content, err := readEntry(tr, name, &total)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{Name: name, Data: content, Exec: hdr.Mode&0111 != 0})
+	}
+}
+
+func readZip(data []byte) ([]archiveFile, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var files []archiveFile
+	var total int64
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+		name, err := safeArchivePath(zf.Name)
+		if err != nil {
+			return nil, err
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := readEntry(rc, name, &total)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{Name: name, Data: content, Exec: zf.Mode()&0111 != 0})
+	}
+	return files, nil
+}
+
+// readEntry reads an archive entry, enforcing the per-entry and total
+// decompressed size limits. total is the size of the entries read so far.
+func readEntry(r io.Reader, name string, total *int64) ([]byte, error) {
+	limit := min(maxArchiveEntrySize, maxArchiveSize-*total)
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		if limit < maxArchiveEntrySize {
+			return nil, fmt.Errorf("archive exceeds %d bytes when decompressed", maxArchiveSize)
+		}
+		return nil, fmt.Errorf("archive entry %s exceeds %d bytes when decompressed", name, maxArchiveEntrySize)
+	}
+	*total += int64(len(content))
+	return content, nil
+}
+
+// safeArchivePath cleans an entry name and rejects absolute paths and
+// entries that would escape the extraction directory.
+func safeArchivePath(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || filepath.VolumeName(clean) != "" {
+		return "", fmt.Errorf("unsafe path in archive: %s", name)
+	}
+	return strings.TrimPrefix(clean, "./"), nil
+}
+
+// commonTopDir returns "dir/" when every file lives under the same top-level
+// directory, or "" otherwise.
+func commonTopDir(files []archiveFile) string {
+	top, _, ok := strings.Cut(files[0].Name, "/")
+	if !ok {
+		return ""
+	}
+	for _, f := range files[1:] {
+		if !strings.HasPrefix(f.Name, top+"/") {
+			return ""
+		}
+	}
+	return top + "/"
+}
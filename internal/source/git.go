@@ -0,0 +1,399 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/netutil"
+	"github.com/adryledo/arca-cli/internal/signing"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gitclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func init() {
+	Register(models.SourceGit, NewGit)
+}
+
+// UseHTTPClient makes every git operation over http(s) in this process,
+// including `arca mirror`, go through c. go-git keeps its transports in a
+// global registry, so this cannot be set per source; call it once at
+// startup.
+func UseHTTPClient(c *http.Client) {
+	transport := githttp.NewClient(c)
+	gitclient.InstallProtocol("https", transport)
+	gitclient.InstallProtocol("http", transport)
+}
+
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Git reads assets from a git repository. Repositories are cloned bare into
+// memory storage, so no local git installation is needed, and each clone is
+// reused for every request made at the same ref. When the source names a
+// provider (github, gitlab, azure), single files and refs go through the
+// host's REST API instead, falling back to a clone if the API fails.
+type Git struct {
+	URL string
+
+	mu     sync.Mutex
+	clones map[string]*git.Repository
+	api    hostAPI
+}
+
+// NewGit creates a git source for cfg.URL.
+func NewGit(cfg models.SourceConfig, opts Options) (Source, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("git source has no url")
+	}
+	return &Git{
+		URL:    cfg.URL,
+		clones: make(map[string]*git.Repository),
+		api:    newHostAPI(cfg.Provider, cfg.URL, opts),
+	}, nil
+}
+
+func (g *Git) FetchManifest(ctx context.Context, ref string) ([]byte, error) {
+	data, _, err := g.FetchFile(ctx, ManifestFileName, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	return data, nil
+}
+
+func (g *Git) ListRefs(ctx context.Context) ([]string, error) {
+	gitAuth, err := auth.ForURL(g.URL)
+	if err != nil {
+		return nil, err
+	}
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{g.URL}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: gitAuth})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", markTransient(err))
+	}
+	var names []string
+	for _, r := range refs {
+		if r.Name().IsBranch() || r.Name().IsTag() {
+			names = append(names, r.Name().Short())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (g *Git) FetchFile(ctx context.Context, filePath, ref string) ([]byte, string, error) {
+	if api := g.hostAPI(); api != nil {
+		commit, err := g.resolveViaAPI(ctx, api, ref)
+		if err == nil {
+			data, err := api.FetchFile(ctx, filePath, commit)
+			if err == nil {
+				return data, commit, nil
+			}
+			g.apiFailed(err)
+		}
+	}
+
+	commit, err := g.commit(ctx, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	f, err := commit.File(path.Clean(filepath.ToSlash(filePath)))
+	if err != nil {
+		return nil, "", fmt.Errorf("file %s not found in repo: %w", filePath, err)
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, commit.Hash.String(), nil
+}
+
+func (g *Git) FetchDirectory(ctx context.Context, dirPath, ref, destDir string) (string, error) {
+	commit, err := g.commit(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	root, err := commit.Tree()
+	if err != nil {
+		return "", err
+	}
+	tree, err := root.Tree(path.Clean(filepath.ToSlash(dirPath)))
+	if err != nil {
+		return "", fmt.Errorf("directory %s not found in repo: %w", dirPath, err)
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", err
+	}
+	// Tree entries are not validated by git, so a crafted tree can name
+	// files such as "../x" that would land outside destDir
+	err = tree.Files().ForEach(func(f *object.File) error {
+		name, err := safeArchivePath(f.Name)
+		if err != nil || name == "." {
+			return fmt.Errorf("unsafe path in repo tree: %s", f.Name)
+		}
+		return writeBlob(f, filepath.Join(destDir, filepath.FromSlash(name)))
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write repo directory: %w", err)
+	}
+	return commit.Hash.String(), nil
+}
+
+func (g *Git) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	if api := g.hostAPI(); api != nil {
+		if commit, err := g.resolveViaAPI(ctx, api, ref); err == nil {
+			return commit, nil
+		}
+	}
+
+	commit, err := g.commit(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+// VerifyRef checks the signature of the annotated tag ref names, if any, and
+// otherwise of the commit ref points to. It always reads the clone, since
+// provider APIs do not expose the signed objects.
+func (g *Git) VerifyRef(ctx context.Context, ref string, keys signing.Keyring) (string, string, error) {
+	commit, err := g.commit(ctx, ref)
+	if err != nil {
+		return "", "", err
+	}
+	hash := commit.Hash.String()
+
+	var errs []error
+	if tag, err := g.annotatedTag(ctx, ref); err != nil {
+		return "", "", err
+	} else if tag != nil {
+		signer, err := verifyObject(keys, tag.PGPSignature, tag.EncodeWithoutSignature)
+		if err == nil {
+			return hash, signer, nil
+		}
+		errs = append(errs, fmt.Errorf("tag %s: %w", ref, err))
+	}
+	signer, err := verifyObject(keys, commit.PGPSignature, commit.EncodeWithoutSignature)
+	if err == nil {
+		return hash, signer, nil
+	}
+	errs = append(errs, fmt.Errorf("commit %s: %w", hash, err))
+	return "", "", fmt.Errorf("%s at %q is not signed by a trusted key: %w", g.URL, ref, errors.Join(errs...))
+}
+
+// annotatedTag returns the tag object ref names, or nil when ref is not an
+// annotated tag.
+func (g *Git) annotatedTag(ctx context.Context, ref string) (*object.Tag, error) {
+	if ref == "" || commitPattern.MatchString(ref) {
+		return nil, nil
+	}
+	repo, err := g.clone(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	r, err := repo.Reference(plumbing.NewTagReferenceName(ref), false)
+	if err != nil {
+		return nil, nil
+	}
+	tag, err := repo.TagObject(r.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, nil // lightweight tag
+	}
+	return tag, err
+}
+
+// verifyObject checks the signature of a commit or tag encoded by encode.
+func verifyObject(keys signing.Keyring, signature string, encode func(plumbing.EncodedObject) error) (string, error) {
+	if signature == "" {
+		return "", signing.ErrUnsigned
+	}
+	obj := &plumbing.MemoryObject{}
+	if err := encode(obj); err != nil {
+		return "", err
+	}
+	r, err := obj.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return signing.VerifyGitSignature(keys, signature, payload)
+}
+
+// hostAPI returns the provider API while it is usable.
+func (g *Git) hostAPI() hostAPI {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.api
+}
+
+// resolveViaAPI resolves ref with the provider API; full commit SHAs need no request.
+func (g *Git) resolveViaAPI(ctx context.Context, api hostAPI, ref string) (string, error) {
+	if commitPattern.MatchString(ref) {
+		return ref, nil
+	}
+	commit, err := api.ResolveCommit(ctx, ref)
+	if err != nil {
+		g.apiFailed(err)
+		return "", err
+	}
+	if !commitPattern.MatchString(commit) {
+		err := fmt.Errorf("provider api returned invalid commit %q", commit)
+		g.apiFailed(err)
+		return "", err
+	}
+	return commit, nil
+}
+
+// apiFailed stops using the provider API for the rest of the command when it
+// is rate limited or refuses the credentials, so that every later request
+// goes straight to the clone instead of failing first.
+func (g *Git) apiFailed(err error) {
+	if errors.Is(err, errRateLimited) || isAuthError(err) {
+		g.mu.Lock()
+		g.api = nil
+		g.mu.Unlock()
+	}
+}
+
+// commit returns the commit ref points to, cloning the repository on first use.
+func (g *Git) commit(ctx context.Context, ref string) (*object.Commit, error) {
+	repo, err := g.clone(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	var hash plumbing.Hash
+	if commitPattern.MatchString(ref) {
+		hash = plumbing.NewHash(ref)
+	} else {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, err
+		}
+		hash = head.Hash()
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return commit, nil
+}
+
+// clone fetches the repository at ref. Branches and tags are fetched shallow;
+// a full commit SHA needs the whole history since servers rarely serve
+// arbitrary commits. An empty ref follows the remote default branch.
+func (g *Git) clone(ctx context.Context, ref string) (*git.Repository, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if repo, ok := g.clones[ref]; ok {
+		return repo, nil
+	}
+
+	gitAuth, err := auth.ForURL(g.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*git.CloneOptions
+	switch {
+	case ref == "":
+		candidates = append(candidates, &git.CloneOptions{URL: g.URL, Depth: 1, Auth: gitAuth})
+	case commitPattern.MatchString(ref):
+		candidates = append(candidates, &git.CloneOptions{URL: g.URL, Auth: gitAuth, Tags: git.NoTags})
+	default:
+		for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+			candidates = append(candidates, &git.CloneOptions{URL: g.URL, Depth: 1, Auth: gitAuth, ReferenceName: name, SingleBranch: true})
+		}
+	}
+
+	var errs []error
+	for _, opts := range candidates {
+		repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, opts)
+		if err == nil {
+			g.clones[ref] = repo
+			return repo, nil
+		}
+		errs = append(errs, markTransient(err))
+		if !errors.Is(err, plumbing.ErrReferenceNotFound) && !isNoMatchingRef(err) {
+			break
+		}
+	}
+	return nil, fmt.Errorf("failed to clone %s at %q: %w", g.URL, ref, errors.Join(errs...))
+}
+
+// markTransient flags clone and list failures worth retrying. go-git wraps
+// server and connection errors in plumbing.UnexpectedError, which hides them
+// from errors.As.
+func markTransient(err error) error {
+	var unexpected *plumbing.UnexpectedError
+	if !errors.As(err, &unexpected) {
+		return err
+	}
+	transient := netutil.IsTransient(unexpected.Err)
+	var httpErr *githttp.Err
+	if errors.As(unexpected.Err, &httpErr) {
+		code := httpErr.StatusCode()
+		transient = code >= 500 || code == 429
+	}
+	if !transient {
+		return err
+	}
+	return &transientError{err}
+}
+
+// transientError marks an error as worth retrying.
+type transientError struct{ err error }
+
+func (e *transientError) Error() string   { return e.err.Error() }
+func (e *transientError) Unwrap() error   { return e.err }
+func (e *transientError) Transient() bool { return true }
+
+func isNoMatchingRef(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+	return errors.As(err, &noMatch) || strings.Contains(err.Error(), "couldn't find remote ref")
+}
+
+func writeBlob(f *object.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if f.Mode == filemode.Executable {
+		perm = 0755
+	}
+	return os.WriteFile(dest, data, perm)
+}
@@ -0,0 +1,308 @@
+package source
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/signing"
+)
+
+func setupTestGitRepo(t *testing.T) string {
+	t.Helper()
+
+	repoDir := t.TempDir()
+
+	// Initialize git
+	cmdInit := exec.Command("git", "init")
+	cmdInit.Dir = repoDir
+	if err := cmdInit.Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	// Create test file
+	testFile := filepath.Join(repoDir, "test.md")
+	if err := os.WriteFile(testFile, []byte("hello world"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	// Create test directory containing a file
+	testDir := filepath.Join(repoDir, "test-skill")
+	if err := os.MkdirAll(testDir, 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	testDirFile := filepath.Join(testDir, "SKILL.md")
+	if err := os.WriteFile(testDirFile, []byte("skill contents"), 0644); err != nil {
+		t.Fatalf("failed to write skill file : %v", err)
+	}
+
+	// Commit
+	cmdAdd := exec.Command("git", "add", ".")
+	cmdAdd.Dir = repoDir
+	if err := cmdAdd.Run(); err != nil {
+		t.Fatalf("failed to 'git add': %v", err)
+	}
+
+	// Provide author name/email so commit succeeds in clean CI environments
+	cmdConfigName := exec.Command("git", "config", "user.name", "Test User")
+	cmdConfigName.Dir = repoDir
+	_ = cmdConfigName.Run()
+	cmdConfigEmail := exec.Command("git", "config", "user.email", "test@example.com")
+	cmdConfigEmail.Dir = repoDir
+	_ = cmdConfigEmail.Run()
+
+	cmdCommit := exec.Command("git", "commit", "-m", "Initial commit")
+	cmdCommit.Dir = repoDir
+	if err := cmdCommit.Run(); err != nil {
+		t.Fatalf("failed to 'git commit': %v", err)
+	}
+
+	return repoDir
+}
+
+func newTestGit(t *testing.T, repoDir string) Source {
+	t.Helper()
+	// file:// protocol requires abs path
+	src, err := New(models.SourceConfig{Type: models.SourceGit, URL: "file://" + filepath.ToSlash(repoDir)}, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return src
+}
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v failed: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGit_FetchFile(t *testing.T) {
+	repoDir := setupTestGitRepo(t)
+	src := newTestGit(t, repoDir)
+
+	// An empty ref follows the default branch, whatever it is called.
+	content, sha, err := src.FetchFile(t.Context(), "test.md", "")
+	if err != nil {
+		t.Fatalf("FetchFile failed: %v", err)
+	}
+
+	if string(content) != "hello world" {
+		t.Errorf("Expected 'hello world', got '%s'", content)
+	}
+	if sha != gitRun(t, repoDir, "rev-parse", "HEAD") {
+		t.Errorf("Expected HEAD commit SHA, got '%s'", sha)
+	}
+
+	if _, _, err := src.FetchFile(t.Context(), "missing.md", ""); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestGit_FetchDirectory(t *testing.T) {
+	repoDir := setupTestGitRepo(t)
+	src := newTestGit(t, repoDir)
+
+	destDir := t.TempDir()
+	sha, err := src.FetchDirectory(t.Context(), "test-skill", "", destDir)
+	if err != nil {
+		t.Fatalf("FetchDirectory failed: %v", err)
+	}
+
+	if sha == "" {
+		t.Errorf("Expected commit SHA, got empty string")
+	}
+
+	// verify file exists
+	content, err := os.ReadFile(filepath.Join(destDir, "SKILL.md"))
+	if err != nil {
+		t.Fatalf("Failed to read fetched file: %v", err)
+	}
+	if string(content) != "skill contents" {
+		t.Errorf("Expected 'skill contents', got '%s'", string(content))
+	}
+}
+
+func TestGit_FetchDirectory_UnsafeTree(t *testing.T) {
+	// git add refuses such names, so build the tree with plumbing commands:
+	// evil-skill/ holds SKILL.md and a ".." directory containing x
+	repoDir := t.TempDir()
+	gitRun(t, repoDir, "init")
+	mktree := func(entries string) string {
+		cmd := exec.Command("git", "mktree")
+		cmd.Dir = repoDir
+		cmd.Stdin = strings.NewReader(entries)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git mktree failed: %v", err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	blob := hashObject(t, repoDir, "pwned")
+	parent := mktree("100644 blob " + blob + "\tx\n")
+	skill := mktree("040000 tree " + parent + "\t..\n100644 blob " + blob + "\tSKILL.md\n")
+	root := mktree("040000 tree " + skill + "\tevil-skill\n")
+	commit := gitRun(t, repoDir, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit-tree", root, "-m", "evil")
+	gitRun(t, repoDir, "update-ref", "HEAD", commit)
+
+	src := newTestGit(t, repoDir)
+	base := t.TempDir()
+	destDir := filepath.Join(base, "dest")
+	_, err := src.FetchDirectory(t.Context(), "evil-skill", "", destDir)
+	if err == nil || !strings.Contains(err.Error(), "unsafe path") {
+		t.Fatalf("Expected an unsafe path error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "x")); err == nil {
+		t.Error("Expected nothing to be written outside the destination")
+	}
+}
+
+func hashObject(t *testing.T, dir, content string) string {
+	t.Helper()
+	cmd := exec.Command("git", "hash-object", "-w", "--stdin")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(content)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git hash-object failed: %v", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGit_Refs(t *testing.T) {
+	repoDir := setupTestGitRepo(t)
+	first := gitRun(t, repoDir, "rev-parse", "HEAD")
+	gitRun(t, repoDir, "tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	gitRun(t, repoDir, "branch", "stable")
+
+	// Move the default branch on so every ref points somewhere different.
+	if err := os.WriteFile(filepath.Join(repoDir, "test.md"), []byte("hello again"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, repoDir, "commit", "-am", "Second commit")
+	head := gitRun(t, repoDir, "rev-parse", "HEAD")
+
+	src := newTestGit(t, repoDir)
+
+	refs, err := src.ListRefs(t.Context())
+	if err != nil {
+		t.Fatalf("ListRefs failed: %v", err)
+	}
+	if !slices.Contains(refs, "v1.0.0") || !slices.Contains(refs, "stable") {
+		t.Errorf("Expected refs to include v1.0.0 and stable, got %v", refs)
+	}
+
+	tests := []struct {
+		ref     string
+		commit  string
+		content string
+	}{
+		{"", head, "hello again"},
+		{"stable", first, "hello world"},
+		{"v1.0.0", first, "hello world"},
+		{first, first, "hello world"},
+	}
+	for _, tt := range tests {
+		t.Run("ref="+tt.ref, func(t *testing.T) {
+			commit, err := src.ResolveCommit(t.Context(), tt.ref)
+			if err != nil {
+				t.Fatalf("ResolveCommit failed: %v", err)
+			}
+			if commit != tt.commit {
+				t.Errorf("Expected %s, got %s", tt.commit, commit)
+			}
+			content, _, err := src.FetchFile(t.Context(), "test.md", tt.ref)
+			if err != nil {
+				t.Fatalf("FetchFile failed: %v", err)
+			}
+			if string(content) != tt.content {
+				t.Errorf("Expected '%s', got '%s'", tt.content, content)
+			}
+		})
+	}
+
+	if _, err := src.ResolveCommit(t.Context(), "no-such-ref"); err == nil {
+		t.Error("Expected error for unknown ref")
+	}
+}
+
+func TestGit_VerifyRef(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	repoDir := setupTestGitRepo(t)
+	keyDir := t.TempDir()
+	signer, err := signing.Generate(keyDir, "release")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	other, err := signing.Generate(keyDir, "other")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	sign := []string{"-c", "gpg.format=ssh", "-c", "user.signingkey=" + signer.Path}
+
+	unsigned := gitRun(t, repoDir, "rev-parse", "HEAD")
+	gitRun(t, repoDir, "tag", "v1.0.0")
+	gitRun(t, repoDir, append(sign, "tag", "-s", "-m", "release", "v1.1.0")...)
+	os.WriteFile(filepath.Join(repoDir, "test.md"), []byte("hello again"), 0644)
+	gitRun(t, repoDir, append(sign, "commit", "-S", "-am", "Signed commit")...)
+	signedCommit := gitRun(t, repoDir, "rev-parse", "HEAD")
+
+	keys, err := signing.LoadKeyring(&models.TrustPolicy{Keys: []string{signer.PublicKey}}, "")
+	if err != nil {
+		t.Fatalf("LoadKeyring failed: %v", err)
+	}
+	otherKeys, err := signing.LoadKeyring(&models.TrustPolicy{Keys: []string{other.PublicKey}}, "")
+	if err != nil {
+		t.Fatalf("LoadKeyring failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		keys    signing.Keyring
+		commit  string
+		wantErr bool
+	}{
+		{"signed commit", "", keys, signedCommit, false},
+		{"signed commit by sha", signedCommit, keys, signedCommit, false},
+		{"signed tag on unsigned commit", "v1.1.0", keys, unsigned, false},
+		{"lightweight tag on unsigned commit", "v1.0.0", keys, "", true},
+		{"untrusted key", "", otherKeys, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newTestGit(t, repoDir)
+			commit, signedBy, err := VerifyRef(t.Context(), src, tt.ref, tt.keys)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %s signed by %s", commit, signedBy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyRef failed: %v", err)
+			}
+			if commit != tt.commit || signedBy != signer.Fingerprint {
+				t.Errorf("Expected %s signed by %s, got %s signed by %s", tt.commit, signer.Fingerprint, commit, signedBy)
+			}
+		})
+	}
+
+	local, err := New(models.SourceConfig{Type: models.SourceLocal, Path: repoDir}, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, _, err := VerifyRef(t.Context(), local, "", keys); err == nil {
+		t.Error("Expected local sources to have no signed refs")
+	}
+}
@@ -0,0 +1,145 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+)
+
+// hostAPI reads single files and resolves refs through a git host's REST
+// API, which is far cheaper than cloning a repository for one file.
+type hostAPI interface {
+	// ResolveCommit returns the commit a branch, tag or commit ref points
+	// to. An empty ref selects the default branch.
+	ResolveCommit(ctx context.Context, ref string) (string, error)
+	// FetchFile returns the content of a file at a commit.
+	FetchFile(ctx context.Context, path, commit string) ([]byte, error)
+}
+
+// errRateLimited marks API errors caused by the host's rate limit.
+var errRateLimited = errors.New("api rate limit exceeded")
+
+// newHostAPI returns the API client for a provider, or nil when the provider
+// is unknown or the URL is not an HTTPS URL the provider understands. SSH
+// sources authenticate with keys the API cannot use, so they always clone.
+func newHostAPI(provider, rawURL string, opts Options) hostAPI {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil
+	}
+	client := &apiClient{http: opts.httpClient(), credURL: rawURL}
+	if opts.CacheDir != "" {
+		client.cache = newResponseCache(filepath.Join(opts.CacheDir, "api"))
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+
+	switch strings.ToLower(provider) {
+	case "github":
+		base := "https://" + u.Host + "/api/v3"
+		if u.Host == "github.com" {
+			base = "https://api.github.com"
+		}
+		if strings.Count(repoPath, "/") != 1 {
+			return nil
+		}
+		return newGitHubAPI(client, base, repoPath)
+	case "gitlab":
+		if !strings.Contains(repoPath, "/") {
+			return nil
+		}
+		return newGitLabAPI(client, "https://"+u.Host+"/api/v4", repoPath)
+	case "azure":
+		prefix, repo, ok := strings.Cut(repoPath, "/_git/")
+		if !ok || prefix == "" || repo == "" || strings.Contains(repo, "/") {
+			return nil
+		}
+		return newAzureAPI(client, "https://"+u.Host+"/"+prefix+"/_apis/git/repositories/"+url.PathEscape(repo))
+	}
+	return nil
+}
+
+// apiClient is the HTTP plumbing shared by the host APIs.
+type apiClient struct {
+	http  *http.Client
+	cache *responseCache
+	// credURL is the source URL the credential is looked up for.
+	credURL string
+	// setAuth applies a credential in the provider's preferred form.
+	setAuth func(req *http.Request, cred *auth.HTTPCredential)
+
+	credOnce sync.Once
+	cred     *auth.HTTPCredential
+	credErr  error
+}
+
+// get fetches an API URL. Immutable responses (content addressed by commit)
+// are served from the cache without a request; others are revalidated,
+// which hosts such as GitHub do not count against the rate limit.
+func (c *apiClient) get(ctx context.Context, target string, headers map[string]string, immutable bool) ([]byte, error) {
+	if immutable {
+		if meta, body := c.cache.load(target); meta != nil {
+			return body, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	c.credOnce.Do(func() {
+		c.cred, c.credErr = auth.LookupCredential(c.credURL)
+	})
+	if c.credErr != nil {
+		return nil, c.credErr
+	}
+	if c.cred != nil && c.setAuth != nil {
+		c.setAuth(req, c.cred)
+	}
+
+	body, meta, err := conditionalGet(c.http, req, c.cache)
+	if err != nil {
+		var se *statusError
+		if errors.As(err, &se) && isRateLimited(se) {
+			return nil, fmt.Errorf("%w: %v", errRateLimited, err)
+		}
+		return nil, err
+	}
+	if immutable && meta.ETag == "" && meta.LastModified == "" {
+		c.cache.store(*meta, body)
+	}
+	return body, nil
+}
+
+// isRateLimited recognises the rate-limit responses of GitHub (403 with no
+// remaining requests), GitLab and Azure DevOps (429, Retry-After).
+func isRateLimited(se *statusError) bool {
+	if se.Code == http.StatusTooManyRequests {
+		return true
+	}
+	if se.Code == http.StatusForbidden {
+		return se.Header.Get("X-RateLimit-Remaining") == "0" || se.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// isAuthError reports whether an API error means the API cannot be used
+// with the available credentials.
+func isAuthError(err error) bool {
+	var se *statusError
+	return errors.As(err, &se) && (se.Code == http.StatusUnauthorized || se.Code == http.StatusForbidden)
+}
+
+// escapeSegment escapes a value used as a single path segment, including slashes.
+func escapeSegment(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "/", "%2F")
+}
@@ -0,0 +1,87 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+)
+
+const azureAPIVersion = "7.1"
+
+// azureAPI uses the Azure DevOps Git REST API.
+type azureAPI struct {
+	client *apiClient
+	repo   string // .../_apis/git/repositories/<repo>
+}
+
+func newAzureAPI(client *apiClient, repoURL string) *azureAPI {
+	client.setAuth = func(req *http.Request, cred *auth.HTTPCredential) {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+	return &azureAPI{client: client, repo: repoURL}
+}
+
+func (a *azureAPI) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	if ref == "" {
+		var repo struct {
+			DefaultBranch string `json:"defaultBranch"`
+		}
+		if err := a.getJSON(ctx, a.repo+"?api-version="+azureAPIVersion, &repo); err != nil {
+			return "", err
+		}
+		if repo.DefaultBranch == "" {
+			return "", fmt.Errorf("azure devops repository has no default branch")
+		}
+		ref = strings.TrimPrefix(repo.DefaultBranch, "refs/heads/")
+	}
+
+	// Azure DevOps needs to be told whether a version is a branch or a tag.
+	for _, versionType := range []string{"branch", "tag"} {
+		q := url.Values{}
+		q.Set("searchCriteria.itemVersion.version", ref)
+		q.Set("searchCriteria.itemVersion.versionType", versionType)
+		q.Set("searchCriteria.$top", "1")
+		q.Set("api-version", azureAPIVersion)
+		var commits struct {
+			Value []struct {
+				CommitID string `json:"commitId"`
+			} `json:"value"`
+		}
+		err := a.getJSON(ctx, a.repo+"/commits?"+q.Encode(), &commits)
+		var se *statusError
+		if err != nil && !(errors.As(err, &se) && (se.Code == http.StatusNotFound || se.Code == http.StatusBadRequest)) {
+			return "", err
+		}
+		if err == nil && len(commits.Value) > 0 {
+			return commits.Value[0].CommitID, nil
+		}
+	}
+	return "", fmt.Errorf("ref %s not found in azure devops repository", ref)
+}
+
+func (a *azureAPI) FetchFile(ctx context.Context, path, commit string) ([]byte, error) {
+	q := url.Values{}
+	q.Set("path", "/"+strings.TrimPrefix(path, "/"))
+	q.Set("versionDescriptor.version", commit)
+	q.Set("versionDescriptor.versionType", "commit")
+	q.Set("download", "true")
+	q.Set("api-version", azureAPIVersion)
+	return a.client.get(ctx, a.repo+"/items?"+q.Encode(), map[string]string{"Accept": "application/octet-stream"}, true)
+}
+
+func (a *azureAPI) getJSON(ctx context.Context, target string, v any) error {
+	body, err := a.client.get(ctx, target, map[string]string{"Accept": "application/json"}, false)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse azure devops response: %w", err)
+	}
+	return nil
+}
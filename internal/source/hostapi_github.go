@@ -0,0 +1,45 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+)
+
+// githubAPI uses the GitHub REST API (github.com or GitHub Enterprise Server).
+type githubAPI struct {
+	client *apiClient
+	base   string
+	repo   string // owner/name
+}
+
+func newGitHubAPI(client *apiClient, base, repo string) *githubAPI {
+	client.setAuth = func(req *http.Request, cred *auth.HTTPCredential) {
+		req.Header.Set("Authorization", "Bearer "+cred.Password)
+	}
+	return &githubAPI{client: client, base: strings.TrimSuffix(base, "/"), repo: repo}
+}
+
+func (g *githubAPI) headers(accept string) map[string]string {
+	return map[string]string{"Accept": accept, "X-GitHub-Api-Version": "2022-11-28"}
+}
+
+func (g *githubAPI) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	target := g.base + "/repos/" + g.repo + "/commits/" + escapeSegment(ref)
+	body, err := g.client.get(ctx, target, g.headers("application/vnd.github.sha"), false)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func (g *githubAPI) FetchFile(ctx context.Context, path, commit string) ([]byte, error) {
+	target := g.base + "/repos/" + g.repo + "/contents/" + (&url.URL{Path: path}).EscapedPath() + "?ref=" + url.QueryEscape(commit)
+	return g.client.get(ctx, target, g.headers("application/vnd.github.raw"), true)
+}
@@ -0,0 +1,63 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+)
+
+// gitlabAPI uses the GitLab REST API (gitlab.com or self-managed).
+type gitlabAPI struct {
+	client  *apiClient
+	project string // URL-encoded project path
+}
+
+func newGitLabAPI(client *apiClient, base, projectPath string) *gitlabAPI {
+	client.setAuth = func(req *http.Request, cred *auth.HTTPCredential) {
+		req.Header.Set("Authorization", "Bearer "+cred.Password)
+	}
+	return &gitlabAPI{client: client, project: strings.TrimSuffix(base, "/") + "/projects/" + escapeSegment(projectPath)}
+}
+
+func (g *gitlabAPI) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	if ref == "" {
+		var project struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := g.getJSON(ctx, g.project, &project); err != nil {
+			return "", err
+		}
+		if project.DefaultBranch == "" {
+			return "", fmt.Errorf("gitlab project has no default branch")
+		}
+		ref = project.DefaultBranch
+	}
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if err := g.getJSON(ctx, g.project+"/repository/commits/"+escapeSegment(ref), &commit); err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+func (g *gitlabAPI) FetchFile(ctx context.Context, path, commit string) ([]byte, error) {
+	target := g.project + "/repository/files/" + escapeSegment(path) + "/raw?ref=" + url.QueryEscape(commit)
+	return g.client.get(ctx, target, nil, true)
+}
+
+func (g *gitlabAPI) getJSON(ctx context.Context, target string, v any) error {
+	body, err := g.client.get(ctx, target, map[string]string{"Accept": "application/json"}, false)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse gitlab response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/go-git/go-git/v5"
+)
+
+const fixtureCommit = "3f786850e387550fdab836ed7e6dc881de23001b"
+
+// interaction is a recorded API exchange from testdata/hostapi.
+type interaction struct {
+	Method  string            `json:"method"`
+	URI     string            `json:"uri"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// replayServer serves recorded interactions and counts the requests per URI.
+// Requests revalidating a recorded ETag get 304 Not Modified.
+type replayServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+func newReplayServer(t *testing.T, fixture string) *replayServer {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "hostapi", fixture))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var recorded []interaction
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	rs := &replayServer{hits: make(map[string]int)}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		rs.hits[r.URL.RequestURI()]++
+		rs.mu.Unlock()
+		for _, in := range recorded {
+			if in.Method != r.Method || in.URI != r.URL.RequestURI() {
+				continue
+			}
+			for k, v := range in.Headers {
+				w.Header().Set(k, v)
+			}
+			if etag := in.Headers["ETag"]; etag != "" && r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(in.Status)
+			var text string
+			if json.Unmarshal(in.Body, &text) == nil {
+				w.Write([]byte(text))
+			} else {
+				w.Write(in.Body)
+			}
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.RequestURI())
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func (rs *replayServer) count(uri string) int {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.hits[uri]
+}
+
+func newTestAPIClient(t *testing.T, cacheDir string) *apiClient {
+	t.Helper()
+	isolateHTTPCredentials(t)
+	return &apiClient{http: http.DefaultClient, cache: newResponseCache(cacheDir), credURL: "https://example.com/org/assets"}
+}
+
+func newTestGitWithAPI(url string, api hostAPI) *Git {
+	return &Git{URL: url, clones: make(map[string]*git.Repository), api: api}
+}
+
+func TestNewHostAPI(t *testing.T) {
+	tests := []struct {
+		provider string
+		url      string
+		want     string
+	}{
+		{"github", "https://github.com/org/assets.git", "https://api.github.com|org/assets"},
+		{"github", "https://ghe.example.com/org/assets", "https://ghe.example.com/api/v3|org/assets"},
+		{"gitlab", "https://gitlab.com/group/sub/assets.git", "https://gitlab.com/api/v4/projects/group%2Fsub%2Fassets"},
+		{"azure", "https://org@dev.azure.com/org/project/_git/assets", "https://dev.azure.com/org/project/_apis/git/repositories/assets"},
+		{"azure", "https://org.visualstudio.com/project/_git/assets", "https://org.visualstudio.com/project/_apis/git/repositories/assets"},
+		{"github", "git@github.com:org/assets.git", ""},
+		{"github", "https://github.com/org", ""},
+		{"bitbucket", "https://bitbucket.org/org/assets", ""},
+		{"", "https://github.com/org/assets", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider+" "+tt.url, func(t *testing.T) {
+			var got string
+			switch api := newHostAPI(tt.provider, tt.url, Options{}).(type) {
+			case *githubAPI:
+				got = api.base + "|" + api.repo
+			case *gitlabAPI:
+				got = api.project
+			case *azureAPI:
+				got = api.repo
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGitHubAPI(t *testing.T) {
+	srv := newReplayServer(t, "github.json")
+	cacheDir := t.TempDir()
+
+	// A clone of this URL would fail, so everything must come from the API.
+	newSource := func() *Git {
+		return newTestGitWithAPI("file:///nonexistent", newGitHubAPI(newTestAPIClient(t, cacheDir), srv.URL, "org/assets"))
+	}
+	src := newSource()
+
+	manifest, err := src.FetchManifest(t.Context(), "")
+	if err != nil || string(manifest) != "schema: \"1.0\"\nassets: {}\n" {
+		t.Fatalf("Expected manifest from the API, got %q (%v)", manifest, err)
+	}
+	data, commit, err := src.FetchFile(t.Context(), "instructions/rules.md", "v1.0.0")
+	if err != nil || string(data) != "# Rules\n" || commit != fixtureCommit {
+		t.Fatalf("Expected rules at %s, got %q@%s (%v)", fixtureCommit, data, commit, err)
+	}
+	if commit, err := src.ResolveCommit(t.Context(), "v1.0.0"); err != nil || commit != fixtureCommit {
+		t.Errorf("Expected %s, got %s (%v)", fixtureCommit, commit, err)
+	}
+
+	// A second run revalidates refs and serves content at a commit from the cache
+	contentURI := "/repos/org/assets/contents/instructions/rules.md?ref=" + fixtureCommit
+	before := srv.count(contentURI)
+	if data, _, err := newSource().FetchFile(t.Context(), "instructions/rules.md", "v1.0.0"); err != nil || string(data) != "# Rules\n" {
+		t.Fatalf("Expected cached rules, got %q (%v)", data, err)
+	}
+	if srv.count(contentURI) != before {
+		t.Errorf("Expected immutable content to be served from the cache")
+	}
+}
+
+func TestGitHubAPI_RateLimitFallsBackToClone(t *testing.T) {
+	srv := newReplayServer(t, "github.json")
+	repoDir := setupTestGitRepo(t)
+	src := newTestGitWithAPI("file://"+filepath.ToSlash(repoDir), newGitHubAPI(newTestAPIClient(t, ""), srv.URL, "org/limited"))
+
+	data, _, err := src.FetchFile(t.Context(), "test.md", "")
+	if err != nil || string(data) != "hello world" {
+		t.Fatalf("Expected fallback to clone, got %q (%v)", data, err)
+	}
+	if src.hostAPI() != nil {
+		t.Error("Expected the API to be disabled after hitting the rate limit")
+	}
+	if _, _, err := src.FetchFile(t.Context(), "test.md", ""); err != nil {
+		t.Fatalf("FetchFile failed: %v", err)
+	}
+	if n := srv.count("/repos/org/limited/commits/HEAD"); n != 1 {
+		t.Errorf("Expected 1 API request, got %d", n)
+	}
+}
+
+func TestGitLabAPI(t *testing.T) {
+	srv := newReplayServer(t, "gitlab.json")
+	api := newGitLabAPI(newTestAPIClient(t, ""), srv.URL, "group/sub/assets")
+
+	commit, err := api.ResolveCommit(t.Context(), "")
+	if err != nil || commit != fixtureCommit {
+		t.Fatalf("Expected default branch at %s, got %s (%v)", fixtureCommit, commit, err)
+	}
+	data, err := api.FetchFile(t.Context(), "instructions/rules.md", commit)
+	if err != nil || string(data) != "# Rules\n" {
+		t.Errorf("Expected rules, got %q (%v)", data, err)
+	}
+	if _, err := api.ResolveCommit(t.Context(), "busy"); !errors.Is(err, errRateLimited) {
+		t.Errorf("Expected rate limit error, got %v", err)
+	}
+}
+
+func TestAzureAPI(t *testing.T) {
+	srv := newReplayServer(t, "azure.json")
+	api := newAzureAPI(newTestAPIClient(t, ""), srv.URL+"/org/project/_apis/git/repositories/assets")
+
+	// v1.0.0 is not a branch, so the tag lookup must resolve it
+	commit, err := api.ResolveCommit(t.Context(), "v1.0.0")
+	if err != nil || commit != fixtureCommit {
+		t.Fatalf("Expected tag at %s, got %s (%v)", fixtureCommit, commit, err)
+	}
+	data, err := api.FetchFile(t.Context(), "instructions/rules.md", commit)
+	if err != nil || string(data) != "# Rules\n" {
+		t.Errorf("Expected rules, got %q (%v)", data, err)
+	}
+}
+
+func TestNewGit_ProviderAPI(t *testing.T) {
+	src, err := New(models.SourceConfig{Type: models.SourceGit, Provider: "github", URL: "https://github.com/org/assets"}, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := src.(*Git).api.(*githubAPI); !ok {
+		t.Errorf("Expected github API for provider github, got %T", src.(*Git).api)
+	}
+}
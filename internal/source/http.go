@@ -0,0 +1,128 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/auth"
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+func init() {
+	Register(models.SourceHTTP, NewHTTP)
+}
+
+// HTTP reads assets from a static file server. The manifest lives at
+// <url>/arca-manifest.yaml and version paths are resolved against the base
+// URL (absolute URLs are used as is). Skill directories are published as
+// .tar.gz, .tgz or .zip archives. Refs are ignored; the ETag (or
+// Last-Modified date) of a response is reported as its commit.
+type HTTP struct {
+	BaseURL *url.URL
+	Client  *http.Client
+
+	cache *responseCache
+
+	credOnce sync.Once
+	cred     *auth.HTTPCredential
+	credErr  error
+}
+
+// NewHTTP creates an HTTP source for cfg.URL.
+func NewHTTP(cfg models.SourceConfig, opts Options) (Source, error) {
+	base, err := url.Parse(cfg.URL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("http source needs an http(s) url, got %q", cfg.URL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	h := &HTTP{BaseURL: base, Client: opts.httpClient()}
+	if opts.CacheDir != "" {
+		h.cache = newResponseCache(filepath.Join(opts.CacheDir, "http"))
+	}
+	return h, nil
+}
+
+func (h *HTTP) FetchManifest(ctx context.Context, ref string) ([]byte, error) {
+	data, _, err := h.get(ctx, ManifestFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	return data, nil
+}
+
+func (h *HTTP) ListRefs(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (h *HTTP) FetchFile(ctx context.Context, path, ref string) ([]byte, string, error) {
+	return h.get(ctx, path)
+}
+
+func (h *HTTP) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	if !isArchive(path) {
+		return "", fmt.Errorf("http sources serve directories as .tar.gz or .zip archives, got %s", path)
+	}
+	data, version, err := h.get(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if err := extractArchive(path, data, destDir); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+func (h *HTTP) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	_, version, err := h.get(ctx, ManifestFileName)
+	return version, err
+}
+
+// get downloads a path relative to the base URL. A cached copy is revalidated
+// with If-None-Match/If-Modified-Since and reused on 304 Not Modified.
+func (h *HTTP) get(ctx context.Context, path string) ([]byte, string, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.BaseURL.ResolveReference(ref).String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := h.authorize(req); err != nil {
+		return nil, "", err
+	}
+	body, meta, err := conditionalGet(h.Client, req, h.cache)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, responseVersion(meta.ETag, meta.LastModified), nil
+}
+
+// authorize adds the credential configured for the host, if any.
+func (h *HTTP) authorize(req *http.Request) error {
+	h.credOnce.Do(func() {
+		h.cred, h.credErr = auth.LookupCredential(h.BaseURL.String())
+	})
+	if h.credErr != nil {
+		return h.credErr
+	}
+	if h.cred != nil && req.URL.Host == h.BaseURL.Host {
+		req.SetBasicAuth(h.cred.Username, h.cred.Password)
+	}
+	return nil
+}
+
+// responseVersion turns response validators into a readable version string.
+func responseVersion(etag, lastModified string) string {
+	if etag != "" {
+		return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+	}
+	return lastModified
+}
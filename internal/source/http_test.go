@@ -0,0 +1,215 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+// isolateHTTPCredentials keeps the developer's credentials out of the tests.
+func isolateHTTPCredentials(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("ARCA_CONFIG", filepath.Join(dir, "config.yaml"))
+	t.Setenv("NETRC", filepath.Join(dir, "netrc"))
+	t.Setenv("HOME", dir)
+	t.Setenv("ARCA_GIT_TOKEN", "")
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestHTTP(t *testing.T) {
+	isolateHTTPCredentials(t)
+
+	files := map[string][]byte{
+		"/assets/arca-manifest.yaml":       []byte("schema: \"1.0\"\n"),
+		"/assets/prompts/review.md":        []byte("review prompt"),
+		"/assets/skills/demo-1.0.0.tar.gz": tarGz(t, map[string]string{"demo/SKILL.md": "skill", "demo/ref/a.md": "nested"}),
+		"/assets/skills/demo-1.0.0.zip":    zipArchive(t, map[string]string{"SKILL.md": "zipped skill"}),
+		"/assets/skills/evil.tar.gz":       tarGz(t, map[string]string{"../escape.md": "nope"}),
+	}
+	var full, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		etag := `"` + bodyHash(data)[:12] + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	cacheDir := t.TempDir()
+	newSource := func() Source {
+		src, err := New(models.SourceConfig{Type: models.SourceHTTP, URL: srv.URL + "/assets"}, Options{CacheDir: cacheDir})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		return src
+	}
+	src := newSource()
+
+	manifest, err := src.FetchManifest(t.Context(), "")
+	if err != nil || string(manifest) != "schema: \"1.0\"\n" {
+		t.Fatalf("Expected manifest, got %q (%v)", manifest, err)
+	}
+
+	data, version, err := src.FetchFile(t.Context(), "prompts/review.md", "")
+	if err != nil {
+		t.Fatalf("FetchFile failed: %v", err)
+	}
+	if string(data) != "review prompt" {
+		t.Errorf("Expected 'review prompt', got '%s'", data)
+	}
+	if version == "" || version[0] == '"' {
+		t.Errorf("Expected unquoted ETag as version, got %q", version)
+	}
+
+	t.Run("tar.gz strips top-level directory", func(t *testing.T) {
+		dest := t.TempDir()
+		if _, err := src.FetchDirectory(t.Context(), "skills/demo-1.0.0.tar.gz", "", dest); err != nil {
+			t.Fatalf("FetchDirectory failed: %v", err)
+		}
+		for name, want := range map[string]string{"SKILL.md": "skill", "ref/a.md": "nested"} {
+			got, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+			if err != nil || string(got) != want {
+				t.Errorf("Expected %s to be '%s', got '%s' (%v)", name, want, got, err)
+			}
+		}
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		dest := t.TempDir()
+		if _, err := src.FetchDirectory(t.Context(), srv.URL+"/assets/skills/demo-1.0.0.zip", "", dest); err != nil {
+			t.Fatalf("FetchDirectory failed: %v", err)
+		}
+		got, err := os.ReadFile(filepath.Join(dest, "SKILL.md"))
+		if err != nil || string(got) != "zipped skill" {
+			t.Errorf("Expected 'zipped skill', got '%s' (%v)", got, err)
+		}
+	})
+
+	t.Run("rejects unsafe archives and plain directories", func(t *testing.T) {
+		dest := t.TempDir()
+		if _, err := src.FetchDirectory(t.Context(), "skills/evil.tar.gz", "", filepath.Join(dest, "out")); err == nil {
+			t.Error("Expected error for path traversal")
+		}
+		if _, err := os.Stat(filepath.Join(dest, "escape.md")); err == nil {
+			t.Error("Expected traversal entry not to be written")
+		}
+		if _, err := src.FetchDirectory(t.Context(), "skills/demo", "", dest); err == nil {
+			t.Error("Expected error for non-archive directory path")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, _, err := src.FetchFile(t.Context(), "missing.md", ""); err == nil {
+			t.Error("Expected error for 404")
+		}
+	})
+
+	t.Run("revalidates cached responses", func(t *testing.T) {
+		before := full.Load()
+		data, _, err := newSource().FetchFile(t.Context(), "prompts/review.md", "")
+		if err != nil || string(data) != "review prompt" {
+			t.Fatalf("Expected cached content, got '%s' (%v)", data, err)
+		}
+		if full.Load() != before {
+			t.Errorf("Expected no full download, got %d", full.Load()-before)
+		}
+		if notModified.Load() == 0 {
+			t.Error("Expected a 304 Not Modified response")
+		}
+	})
+}
+
+func TestNewHTTP_RequiresHTTPURL(t *testing.T) {
+	if _, err := New(models.SourceConfig{Type: models.SourceHTTP, URL: "git@github.com:org/repo.git"}, Options{}); err == nil {
+		t.Error("Expected error for non-http url")
+	}
+}
+
+func TestExtractArchive_SizeLimits(t *testing.T) {
+	entry, total := maxArchiveEntrySize, maxArchiveSize
+	t.Cleanup(func() { maxArchiveEntrySize, maxArchiveSize = entry, total })
+	maxArchiveEntrySize, maxArchiveSize = 1024, 1536
+
+	big := string(bytes.Repeat([]byte("a"), 1025))
+	half := string(bytes.Repeat([]byte("a"), 800))
+	tests := []struct {
+		name    string
+		archive string
+		data    []byte
+		wantErr bool
+	}{
+		{"within limits", "ok.tar.gz", tarGz(t, map[string]string{"SKILL.md": half}), false},
+		{"entry too large", "bomb.tar.gz", tarGz(t, map[string]string{"SKILL.md": big}), true},
+		{"zip entry too large", "bomb.zip", zipArchive(t, map[string]string{"SKILL.md": big}), true},
+		{"total too large", "bomb.tar.gz", tarGz(t, map[string]string{"a.md": half, "b.md": half}), true},
+		{"zip total too large", "bomb.zip", zipArchive(t, map[string]string{"a.md": half, "b.md": half}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := extractArchive(tt.archive, tt.data, t.TempDir())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/adryledo/arca-cli/internal/fsutil"
+	"github.com/adryledo/arca-cli/internal/hasher"
+)
+
+// httpCacheMeta describes a cached response body.
+type httpCacheMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	SHA256       string `json:"sha256"`
+}
+
+// responseCache keeps HTTP response bodies on disk, keyed by URL, so that
+// they can be revalidated with conditional requests. A nil cache stores nothing.
+type responseCache struct {
+	dir string
+}
+
+// newResponseCache returns a cache in dir, or nil when dir is empty.
+func newResponseCache(dir string) *responseCache {
+	if dir == "" {
+		return nil
+	}
+	return &responseCache{dir: dir}
+}
+
+// paths returns the metadata and body paths for a cached URL.
+func (c *responseCache) paths(target string) (string, string) {
+	key := hasher.HashString(target)
+	return filepath.Join(c.dir, key+".json"), filepath.Join(c.dir, key)
+}
+
+// load returns the cached response for a URL when it is present and its
+// body still matches the recorded hash.
+func (c *responseCache) load(target string) (*httpCacheMeta, []byte) {
+	if c == nil {
+		return nil, nil
+	}
+	metaPath, bodyPath := c.paths(target)
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+	var meta httpCacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil || meta.URL != target {
+		return nil, nil
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil || bodyHash(body) != meta.SHA256 {
+		return nil, nil
+	}
+	return &meta, body
+}
+
+// store records a response. Failures only cost a download next time, so
+// they are ignored.
+func (c *responseCache) store(meta httpCacheMeta, body []byte) {
+	if c == nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	meta.SHA256 = bodyHash(body)
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return
+	}
+	metaPath, bodyPath := c.paths(meta.URL)
+	if err := fsutil.WriteFileAtomic(bodyPath, body, 0644); err != nil {
+		return
+	}
+	_ = fsutil.WriteFileAtomic(metaPath, data, 0644)
+}
+
+// statusError is returned for unexpected HTTP status codes. It keeps the
+// response headers so that callers can inspect rate-limit information.
+type statusError struct {
+	URL    string
+	Status string
+	Code   int
+	Header http.Header
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: %s", e.URL, e.Status)
+}
+
+// Transient reports whether the server may answer differently on a retry.
+func (e *statusError) Transient() bool {
+	return e.Code >= 500 || e.Code == http.StatusTooManyRequests
+}
+
+// conditionalGet sends a GET request, revalidating a cached copy with
+// If-None-Match/If-Modified-Since and reusing it on 304 Not Modified.
+// Responses carrying a validator are cached.
+func conditionalGet(client *http.Client, req *http.Request, cache *responseCache) ([]byte, *httpCacheMeta, error) {
+	target := req.URL.String()
+	cachedMeta, cachedBody := cache.load(target)
+	if cachedMeta != nil {
+		if cachedMeta.ETag != "" {
+			req.Header.Set("If-None-Match", cachedMeta.ETag)
+		}
+		if cachedMeta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cachedMeta.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cachedMeta != nil:
+		return cachedBody, cachedMeta, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, &statusError{URL: target, Status: resp.Status, Code: resp.StatusCode, Header: resp.Header}
+	}
+
+	// The transport transparently decompresses gzip responses, so the body
+	// is bounded like a decompressed archive
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	if int64(len(body)) > maxArchiveSize {
+		return nil, nil, fmt.Errorf("response from %s exceeds %d bytes", target, maxArchiveSize)
+	}
+	meta := &httpCacheMeta{
+		URL:          target,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if meta.ETag != "" || meta.LastModified != "" {
+		cache.store(*meta, body)
+	}
+	return body, meta, nil
+}
+
+// bodyHash hashes a response byte for byte; unlike hasher it does not
+// normalize line endings.
+func bodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/fsutil"
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+func init() {
+	Register(models.SourceLocal, NewLocal)
+}
+
+// Local reads assets straight from a folder on disk. Refs are ignored and
+// content is always reported at LocalCommit, unless the folder was written by
+// `arca mirror`: then refs resolve to the mirrored upstream commits.
+type Local struct {
+	Root string
+
+	snapshotOnce sync.Once
+	snapshot     *SnapshotIndex
+	snapshotErr  error
+}
+
+// NewLocal creates a local source; relative paths are resolved against the workspace root.
+func NewLocal(cfg models.SourceConfig, opts Options) (Source, error) {
+	root := cfg.Path
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(opts.WorkspaceRoot, root)
+	}
+	return &Local{Root: root}, nil
+}
+
+func (l *Local) FetchManifest(ctx context.Context, ref string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(l.Root, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local manifest: %w", err)
+	}
+	return data, nil
+}
+
+func (l *Local) ListRefs(ctx context.Context) ([]string, error) {
+	snap, err := l.loadSnapshot()
+	if err != nil || snap == nil {
+		return nil, err
+	}
+	return snap.RefNames(), nil
+}
+
+func (l *Local) FetchFile(ctx context.Context, path, ref string) ([]byte, string, error) {
+	root, commit, err := l.rootFor(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, commit, nil
+}
+
+func (l *Local) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	root, commit, err := l.rootFor(ref)
+	if err != nil {
+		return "", err
+	}
+	if err := fsutil.CopyDir(filepath.Join(root, filepath.FromSlash(path)), destDir); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+func (l *Local) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	_, commit, err := l.rootFor(ref)
+	return commit, err
+}
+
+// rootFor returns the folder holding the content of ref and its commit.
+func (l *Local) rootFor(ref string) (string, string, error) {
+	snap, err := l.loadSnapshot()
+	if err != nil {
+		return "", "", err
+	}
+	if snap == nil {
+		return l.Root, LocalCommit, nil
+	}
+	commit, err := snap.Resolve(l.Root, ref)
+	if err != nil {
+		return "", "", err
+	}
+	return SnapshotRoot(l.Root, snap.Head, commit), commit, nil
+}
+
+func (l *Local) loadSnapshot() (*SnapshotIndex, error) {
+	l.snapshotOnce.Do(func() {
+		l.snapshot, l.snapshotErr = LoadSnapshotIndex(l.Root)
+	})
+	return l.snapshot, l.snapshotErr
+}
@@ -0,0 +1,135 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/config"
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/signing"
+)
+
+// candidateURLs lists the URLs a source is fetched from, in the order they
+// are tried: its mirrors, then its own URL, each with the rewrite rules
+// applied. Duplicates are dropped.
+func candidateURLs(cfg models.SourceConfig, rules []models.URLRewrite) []string {
+	if cfg.URL == "" {
+		return nil
+	}
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range append(append([]string{}, cfg.Mirrors...), cfg.URL) {
+		u = config.RewriteURL(u, rules)
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// Mirrored tries a list of equivalent sources in turn. Once a source has
+// answered, it is tried first for later requests so that an unreachable
+// mirror only costs one failure per command.
+type Mirrored struct {
+	URLs    []string
+	Sources []Source
+
+	mu        sync.Mutex
+	preferred int
+}
+
+// order returns the indexes of the sources, preferred first.
+func (m *Mirrored) order() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	order := []int{m.preferred}
+	for i := range m.Sources {
+		if i != m.preferred {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// try calls fn on each source until one succeeds. Once ctx is done, the
+// remaining sources are not tried.
+func (m *Mirrored) try(ctx context.Context, fn func(src Source) error) error {
+	var errs []error
+	for _, i := range m.order() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(m.Sources[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.URLs[i], err))
+			continue
+		}
+		m.mu.Lock()
+		m.preferred = i
+		m.mu.Unlock()
+		return nil
+	}
+	return fmt.Errorf("all mirrors failed: %w", errors.Join(errs...))
+}
+
+func (m *Mirrored) FetchManifest(ctx context.Context, ref string) ([]byte, error) {
+	var data []byte
+	err := m.try(ctx, func(src Source) (err error) {
+		data, err = src.FetchManifest(ctx, ref)
+		return err
+	})
+	return data, err
+}
+
+func (m *Mirrored) ListRefs(ctx context.Context) ([]string, error) {
+	var refs []string
+	err := m.try(ctx, func(src Source) (err error) {
+		refs, err = src.ListRefs(ctx)
+		return err
+	})
+	return refs, err
+}
+
+func (m *Mirrored) FetchFile(ctx context.Context, path, ref string) ([]byte, string, error) {
+	var data []byte
+	var commit string
+	err := m.try(ctx, func(src Source) (err error) {
+		data, commit, err = src.FetchFile(ctx, path, ref)
+		return err
+	})
+	return data, commit, err
+}
+
+func (m *Mirrored) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	var commit string
+	err := m.try(ctx, func(src Source) (err error) {
+		commit, err = src.FetchDirectory(ctx, path, ref, destDir)
+		if err != nil {
+			// Do not let a partial download leak into the next attempt
+			os.RemoveAll(destDir)
+		}
+		return err
+	})
+	return commit, err
+}
+
+func (m *Mirrored) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	var commit string
+	err := m.try(ctx, func(src Source) (err error) {
+		commit, err = src.ResolveCommit(ctx, ref)
+		return err
+	})
+	return commit, err
+}
+
+func (m *Mirrored) VerifyRef(ctx context.Context, ref string, keys signing.Keyring) (string, string, error) {
+	var commit, signer string
+	err := m.try(ctx, func(src Source) (err error) {
+		commit, signer, err = VerifyRef(ctx, src, ref, keys)
+		return err
+	})
+	return commit, signer, err
+}
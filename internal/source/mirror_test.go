@@ -0,0 +1,170 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+// urlSource answers only when its URL is reachable and records every call.
+type urlSource struct {
+	Local
+	url       string
+	provider  string
+	reachable map[string]bool
+	calls     *[]string
+}
+
+func (u *urlSource) FetchFile(ctx context.Context, path, ref string) ([]byte, string, error) {
+	*u.calls = append(*u.calls, u.url)
+	if !u.reachable[u.url] {
+		return nil, "", fmt.Errorf("unreachable")
+	}
+	return []byte(u.url), "c0ffee", nil
+}
+
+func (u *urlSource) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	*u.calls = append(*u.calls, u.url)
+	// Leave a partial download behind when failing
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(destDir, "from-"+strings.Split(u.url, "/")[2]), nil, 0644); err != nil {
+		return "", err
+	}
+	if !u.reachable[u.url] {
+		return "", fmt.Errorf("unreachable")
+	}
+	return "c0ffee", nil
+}
+
+func TestMirrored(t *testing.T) {
+	const mirrorType models.SourceType = "fake-mirror"
+	var calls []string
+	var providers = map[string]string{}
+	reachable := map[string]bool{}
+	Register(mirrorType, func(cfg models.SourceConfig, opts Options) (Source, error) {
+		providers[cfg.URL] = cfg.Provider
+		return &urlSource{url: cfg.URL, reachable: reachable, calls: &calls}, nil
+	})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, mirrorType)
+		registryMu.Unlock()
+	})
+
+	cfg := models.SourceConfig{
+		Type:     mirrorType,
+		Provider: "github",
+		URL:      "https://github.com/org/assets",
+		Mirrors:  []string{"https://m1.example.com/org/assets", "https://m2.example.com/org/assets"},
+	}
+	rewrites := []models.URLRewrite{{URL: "https://m2.corp.example.com/", InsteadOf: "https://m2.example.com/"}}
+
+	src, err := New(cfg, Options{Rewrites: rewrites})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	m, ok := src.(*Mirrored)
+	if !ok {
+		t.Fatalf("Expected *Mirrored, got %T", src)
+	}
+	want := []string{"https://m1.example.com/org/assets", "https://m2.corp.example.com/org/assets", "https://github.com/org/assets"}
+	if !reflect.DeepEqual(m.URLs, want) {
+		t.Errorf("Expected %v, got %v", want, m.URLs)
+	}
+	if providers["https://github.com/org/assets"] != "github" || providers["https://m1.example.com/org/assets"] != "" {
+		t.Errorf("Expected the provider to be kept for the canonical URL only, got %v", providers)
+	}
+
+	// Only the rewritten second mirror answers
+	reachable["https://m2.corp.example.com/org/assets"] = true
+	data, _, err := src.FetchFile(t.Context(), "a.md", "")
+	if err != nil || string(data) != "https://m2.corp.example.com/org/assets" {
+		t.Fatalf("Expected content from second mirror, got %q (%v)", data, err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected 2 attempts, got %v", calls)
+	}
+
+	// The working mirror is tried first from now on
+	calls = nil
+	dest := filepath.Join(t.TempDir(), "skill")
+	if _, err := src.FetchDirectory(t.Context(), "skill", "", dest); err != nil {
+		t.Fatalf("FetchDirectory failed: %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "https://m2.corp.example.com/org/assets" {
+		t.Errorf("Expected the preferred mirror only, got %v", calls)
+	}
+
+	// Failed attempts do not leave files behind
+	reachable["https://m2.corp.example.com/org/assets"] = false
+	reachable["https://github.com/org/assets"] = true
+	dest = filepath.Join(t.TempDir(), "skill")
+	if _, err := src.FetchDirectory(t.Context(), "skill", "", dest); err != nil {
+		t.Fatalf("FetchDirectory failed: %v", err)
+	}
+	entries, _ := os.ReadDir(dest)
+	if len(entries) != 1 || entries[0].Name() != "from-github.com" {
+		t.Errorf("Expected only the successful attempt's files, got %v", entries)
+	}
+
+	reachable["https://github.com/org/assets"] = false
+	if _, _, err := src.FetchFile(t.Context(), "a.md", ""); err == nil {
+		t.Error("Expected error when every mirror fails")
+	}
+}
+
+func TestNew_RewriteWithoutMirrors(t *testing.T) {
+	repoDir := setupTestGitRepo(t)
+	canonical := "https://github.com/org/assets"
+	src, err := New(models.SourceConfig{Type: models.SourceGit, Provider: "github", URL: canonical}, Options{
+		Rewrites: []models.URLRewrite{{URL: "file://" + filepath.ToSlash(repoDir), InsteadOf: canonical}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	g, ok := src.(*Git)
+	if !ok {
+		t.Fatalf("Expected *Git, got %T", src)
+	}
+	if g.api != nil {
+		t.Error("Expected no provider API for a rewritten URL")
+	}
+	data, _, err := src.FetchFile(t.Context(), "test.md", "")
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("Expected content from rewritten URL, got %q (%v)", data, err)
+	}
+}
+
+func TestNew_RewriteToDirectoryMirror(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.md"), []byte("mirrored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	idx := &SnapshotIndex{Source: "https://github.com/org/assets", Head: "abc"}
+	if err := idx.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	canonical := "https://github.com/org/assets"
+	src, err := New(models.SourceConfig{Type: models.SourceGit, URL: canonical}, Options{
+		Rewrites: []models.URLRewrite{{URL: dir, InsteadOf: canonical}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := src.(*Local); !ok {
+		t.Fatalf("Expected *Local, got %T", src)
+	}
+	data, commit, err := src.FetchFile(t.Context(), "test.md", "")
+	if err != nil || string(data) != "mirrored" || commit != "abc" {
+		t.Errorf("Expected mirrored content at abc, got %q at %s (%v)", data, commit, err)
+	}
+}
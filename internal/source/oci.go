@@ -0,0 +1,107 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/oci"
+)
+
+func init() {
+	Register(models.SourceOCI, NewOCI)
+}
+
+// OCI reads assets published as OCI artifacts by `arca publish --oci`. The
+// manifest comes from the index artifact and version refs are layer digests,
+// which are also reported as commits so that the lockfile pins content by
+// digest.
+type OCI struct {
+	Client *oci.Client
+}
+
+// NewOCI creates an OCI source for an oci://host/repository URL.
+func NewOCI(cfg models.SourceConfig, opts Options) (Source, error) {
+	ref, err := oci.ParseReference(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	client := oci.NewClient(ref)
+	client.HTTP = opts.httpClient()
+	return &OCI{Client: client}, nil
+}
+
+// FetchManifest reads the manifest from the index artifact. A digest ref
+// selects an earlier index; other refs (such as the layer digests recorded
+// in the lockfile) fall back to the current one.
+func (o *OCI) FetchManifest(ctx context.Context, ref string) ([]byte, error) {
+	if oci.IsDigest(ref) {
+		_, data, _, err := o.Client.FetchIndex(ctx, ref)
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, oci.ErrNotFound) {
+			return nil, err
+		}
+	}
+	_, data, _, err := o.Client.FetchIndex(ctx, oci.IndexTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	return data, nil
+}
+
+func (o *OCI) ListRefs(ctx context.Context) ([]string, error) {
+	return o.Client.Tags(ctx)
+}
+
+func (o *OCI) FetchFile(ctx context.Context, path, ref string) ([]byte, string, error) {
+	return o.layer(ctx, ref)
+}
+
+func (o *OCI) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	data, digest, err := o.layer(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if err := extractArchive(path+".tar.gz", data, destDir); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+func (o *OCI) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	if oci.IsDigest(ref) {
+		return ref, nil
+	}
+	if ref == "" {
+		ref = oci.IndexTag
+	}
+	_, digest, err := o.Client.GetManifest(ctx, ref)
+	return digest, err
+}
+
+// layer downloads the content a version ref points to: a layer digest, or
+// the single layer of an asset artifact tag.
+func (o *OCI) layer(ctx context.Context, ref string) ([]byte, string, error) {
+	digest := ref
+	if !oci.IsDigest(ref) {
+		if ref == "" {
+			return nil, "", fmt.Errorf("oci sources need a digest or tag ref; republish the version with 'arca publish --oci'")
+		}
+		m, _, err := o.Client.GetManifest(ctx, ref)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(m.Layers) != 1 {
+			return nil, "", fmt.Errorf("%s@%s is not an ARCA asset artifact", o.Client.Ref, ref)
+		}
+		digest = m.Layers[0].Digest
+	}
+	data, err := o.Client.GetBlob(ctx, digest)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, digest, nil
+}
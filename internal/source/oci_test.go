@@ -0,0 +1,88 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/oci"
+	"github.com/adryledo/arca-cli/internal/oci/ocitest"
+)
+
+func TestOCI(t *testing.T) {
+	isolateHTTPCredentials(t)
+	srv := ocitest.NewServer(nil)
+	defer srv.Close()
+	url := "oci://" + strings.TrimPrefix(srv.URL, "http://") + "/org/assets"
+
+	// Publish an instruction, a skill and the index the way `arca publish --oci` does
+	ref, err := oci.ParseReference(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := oci.NewClient(ref)
+	rules, err := client.PushAsset(t.Context(), "rules", "1.0.0", models.KindInstruction, "rules.md", []byte("rules"))
+	if err != nil {
+		t.Fatalf("PushAsset failed: %v", err)
+	}
+	skillDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("skill"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	packed, err := oci.TarGzDir(skillDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skill, err := client.PushAsset(t.Context(), "demo", "2.0.0", models.KindSkill, "demo.tar.gz", packed)
+	if err != nil {
+		t.Fatalf("PushAsset failed: %v", err)
+	}
+	manifest := "schema: \"1.0\"\nassets:\n  rules:\n    kind: instruction\n    versions:\n      1.0.0: {path: rules.md, ref: \"" + rules.Digest + "\"}\n"
+	indexDigest, err := client.PushIndex(t.Context(), []byte(manifest), []oci.Descriptor{rules, skill})
+	if err != nil {
+		t.Fatalf("PushIndex failed: %v", err)
+	}
+
+	src, err := New(models.SourceConfig{Type: models.SourceOCI, URL: url}, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, r := range []string{"", indexDigest, rules.Digest} {
+		data, err := src.FetchManifest(t.Context(), r)
+		if err != nil || string(data) != manifest {
+			t.Errorf("FetchManifest(%q): expected index manifest, got %q (%v)", r, data, err)
+		}
+	}
+
+	data, commit, err := src.FetchFile(t.Context(), "rules.md", rules.Digest)
+	if err != nil || string(data) != "rules" || commit != rules.Digest {
+		t.Errorf("Expected rules@%s, got %q@%s (%v)", rules.Digest, data, commit, err)
+	}
+	// Asset artifact tags resolve to their layer
+	if data, commit, err := src.FetchFile(t.Context(), "rules.md", "rules-1.0.0"); err != nil || string(data) != "rules" || commit != rules.Digest {
+		t.Errorf("Expected tag to resolve to rules layer, got %q@%s (%v)", data, commit, err)
+	}
+	if _, _, err := src.FetchFile(t.Context(), "rules.md", ""); err == nil {
+		t.Error("Expected error for empty ref")
+	}
+
+	dest := t.TempDir()
+	if commit, err := src.FetchDirectory(t.Context(), "skills/demo", skill.Digest, dest); err != nil || commit != skill.Digest {
+		t.Fatalf("FetchDirectory failed: %s (%v)", commit, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dest, "SKILL.md")); err != nil || string(got) != "skill" {
+		t.Errorf("Expected 'skill', got %q (%v)", got, err)
+	}
+
+	if commit, err := src.ResolveCommit(t.Context(), ""); err != nil || commit != indexDigest {
+		t.Errorf("Expected index digest %s, got %s (%v)", indexDigest, commit, err)
+	}
+	refs, err := src.ListRefs(t.Context())
+	if err != nil || !slices.Contains(refs, "demo-2.0.0") {
+		t.Errorf("Expected demo-2.0.0 in refs, got %v (%v)", refs, err)
+	}
+}
@@ -0,0 +1,76 @@
+package source
+
+import (
+	"context"
+	"os"
+
+	"github.com/adryledo/arca-cli/internal/netutil"
+	"github.com/adryledo/arca-cli/internal/signing"
+)
+
+// Retrying bounds every request of a network source with a timeout and
+// retries transient failures (timeouts, dropped connections, 5xx answers)
+// with exponential backoff.
+type Retrying struct {
+	Source Source
+	Policy netutil.Policy
+}
+
+func (r *Retrying) FetchManifest(ctx context.Context, ref string) ([]byte, error) {
+	var data []byte
+	err := netutil.Do(ctx, r.Policy, func(ctx context.Context) (err error) {
+		data, err = r.Source.FetchManifest(ctx, ref)
+		return err
+	})
+	return data, err
+}
+
+func (r *Retrying) ListRefs(ctx context.Context) ([]string, error) {
+	var refs []string
+	err := netutil.Do(ctx, r.Policy, func(ctx context.Context) (err error) {
+		refs, err = r.Source.ListRefs(ctx)
+		return err
+	})
+	return refs, err
+}
+
+func (r *Retrying) FetchFile(ctx context.Context, path, ref string) ([]byte, string, error) {
+	var data []byte
+	var commit string
+	err := netutil.Do(ctx, r.Policy, func(ctx context.Context) (err error) {
+		data, commit, err = r.Source.FetchFile(ctx, path, ref)
+		return err
+	})
+	return data, commit, err
+}
+
+func (r *Retrying) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	var commit string
+	err := netutil.Do(ctx, r.Policy, func(ctx context.Context) (err error) {
+		commit, err = r.Source.FetchDirectory(ctx, path, ref, destDir)
+		if err != nil {
+			// Start the next attempt from an empty directory
+			os.RemoveAll(destDir)
+		}
+		return err
+	})
+	return commit, err
+}
+
+func (r *Retrying) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	var commit string
+	err := netutil.Do(ctx, r.Policy, func(ctx context.Context) (err error) {
+		commit, err = r.Source.ResolveCommit(ctx, ref)
+		return err
+	})
+	return commit, err
+}
+
+func (r *Retrying) VerifyRef(ctx context.Context, ref string, keys signing.Keyring) (string, string, error) {
+	var commit, signer string
+	err := netutil.Do(ctx, r.Policy, func(ctx context.Context) (err error) {
+		commit, signer, err = VerifyRef(ctx, r.Source, ref, keys)
+		return err
+	})
+	return commit, signer, err
+}
@@ -0,0 +1,83 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/netutil"
+)
+
+// flakySource fails with a server error until it has been called failures times.
+type flakySource struct {
+	Local
+	failures int
+	calls    int
+}
+
+func (f *flakySource) FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error) {
+	f.calls++
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(destDir, fmt.Sprintf("attempt-%d", f.calls)), nil, 0644); err != nil {
+		return "", err
+	}
+	if f.calls <= f.failures {
+		return "", &statusError{URL: "https://example.com", Status: "503 Service Unavailable", Code: 503}
+	}
+	return "c0ffee", nil
+}
+
+func TestRetrying(t *testing.T) {
+	policy := netutil.Policy{Retries: 2, BaseDelay: time.Millisecond}
+
+	flaky := &flakySource{failures: 2}
+	src := &Retrying{Source: flaky, Policy: policy}
+	dest := filepath.Join(t.TempDir(), "out")
+	commit, err := src.FetchDirectory(t.Context(), "skill", "", dest)
+	if err != nil || commit != "c0ffee" {
+		t.Fatalf("Expected success after retries, got %q (%v)", commit, err)
+	}
+	entries, _ := os.ReadDir(dest)
+	if len(entries) != 1 || entries[0].Name() != "attempt-3" {
+		t.Errorf("Expected only the last attempt in the destination, got %v", entries)
+	}
+
+	flaky = &flakySource{failures: 5}
+	src = &Retrying{Source: flaky, Policy: policy}
+	if _, err := src.FetchDirectory(t.Context(), "skill", "", filepath.Join(t.TempDir(), "out")); err == nil {
+		t.Error("Expected an error once the retries are used up")
+	}
+	if flaky.calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", flaky.calls)
+	}
+
+	const flakyType models.SourceType = "fake-flaky"
+	Register(flakyType, func(cfg models.SourceConfig, opts Options) (Source, error) {
+		return &flakySource{}, nil
+	})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, flakyType)
+		registryMu.Unlock()
+	})
+	wrapped, err := New(models.SourceConfig{Type: flakyType, URL: "https://example.com/assets"}, Options{Network: policy})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := wrapped.(*Retrying); !ok {
+		t.Errorf("Expected *Retrying, got %T", wrapped)
+	}
+	local, err := New(models.SourceConfig{Type: models.SourceLocal, Path: t.TempDir()}, Options{Network: policy})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := local.(*Local); !ok {
+		t.Errorf("Expected local sources not to be wrapped, got %T", local)
+	}
+}
@@ -0,0 +1,94 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SnapshotDir is the folder `arca mirror` keeps next to the manifest of a
+// mirrored directory. Content of the default revision lives at its usual
+// paths; versions pinned to other commits live under refs/<commit>/.
+const SnapshotDir = ".arca-mirror"
+
+// snapshotIndexFile records where a mirrored directory came from.
+const snapshotIndexFile = "index.json"
+
+// SnapshotIndex describes a mirrored directory so that a local source can
+// keep serving the upstream commits and refs.
+type SnapshotIndex struct {
+	// Source is the URL the directory was mirrored from.
+	Source string `json:"source"`
+	// Head is the commit of the default revision.
+	Head string `json:"head"`
+	// Refs maps branch and tag names to commits.
+	Refs map[string]string `json:"refs"`
+}
+
+// LoadSnapshotIndex reads the snapshot index of a mirrored directory. It
+// returns nil without error when root is not a mirror.
+func LoadSnapshotIndex(root string) (*SnapshotIndex, error) {
+	data, err := os.ReadFile(filepath.Join(root, SnapshotDir, snapshotIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var idx SnapshotIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse mirror index: %w", err)
+	}
+	return &idx, nil
+}
+
+// Save writes the snapshot index into root.
+func (s *SnapshotIndex) Save(root string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(root, SnapshotDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, snapshotIndexFile), data, 0644)
+}
+
+// Resolve returns the commit ref points to in the mirror. Commits that were
+// mirrored are returned as they are.
+func (s *SnapshotIndex) Resolve(root, ref string) (string, error) {
+	if ref == "" || ref == s.Head {
+		return s.Head, nil
+	}
+	if commit, ok := s.Refs[ref]; ok {
+		return commit, nil
+	}
+	if _, err := os.Stat(SnapshotRoot(root, s.Head, ref)); err == nil {
+		return ref, nil
+	}
+	return "", fmt.Errorf("ref %s is not part of the mirror", ref)
+}
+
+// RefNames returns the mirrored branch and tag names.
+func (s *SnapshotIndex) RefNames() []string {
+	names := make([]string, 0, len(s.Refs))
+	for name := range s.Refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SnapshotRoot returns the folder holding the content of commit in a
+// mirrored directory whose default revision is head.
+func SnapshotRoot(root, head, commit string) string {
+	if commit == head {
+		return root
+	}
+	// Digests such as sha256:<hex> are not valid file names everywhere
+	return filepath.Join(root, SnapshotDir, "refs", strings.ReplaceAll(commit, ":", "-"))
+}
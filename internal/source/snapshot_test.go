@@ -0,0 +1,57 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotIndex_Resolve(t *testing.T) {
+	root := t.TempDir()
+	idx := &SnapshotIndex{Head: "head", Refs: map[string]string{"v1": "old", "main": "head"}}
+	if err := idx.Save(root); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := os.MkdirAll(SnapshotRoot(root, "head", "sha256:abc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSnapshotIndex(root)
+	if err != nil || loaded == nil {
+		t.Fatalf("LoadSnapshotIndex failed: %v", err)
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"", "head", false},
+		{"main", "head", false},
+		{"v1", "old", false},
+		{"sha256:abc", "sha256:abc", false},
+		{"unknown", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := loaded.Resolve(root, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if got := SnapshotRoot(root, "head", "head"); got != root {
+		t.Errorf("Expected the default revision at the root, got %s", got)
+	}
+	if got := SnapshotRoot(root, "head", "sha256:abc"); filepath.Base(got) != "sha256-abc" {
+		t.Errorf("Expected a file-name safe snapshot folder, got %s", got)
+	}
+
+	if idx, err := LoadSnapshotIndex(t.TempDir()); idx != nil || err != nil {
+		t.Errorf("Expected no index for a plain folder, got %v, %v", idx, err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package source provides access to asset sources (git repositories, local
+// folders, ...) behind a single interface so that every command fetches
+// manifests and content the same way.
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/adryledo/arca-cli/internal/models"
+	"github.com/adryledo/arca-cli/internal/netutil"
+	"github.com/adryledo/arca-cli/internal/signing"
+)
+
+// ManifestFileName is the manifest every source exposes at its root.
+const ManifestFileName = "arca-manifest.yaml"
+
+// LocalCommit is reported as the commit of content read from mutable sources.
+const LocalCommit = "local"
+
+// Source fetches manifests and asset content from one configured source.
+// An empty ref selects the source's default revision (e.g. the default branch).
+// Every method stops and returns ctx's error once ctx is done.
+type Source interface {
+	// FetchManifest returns the raw arca-manifest.yaml at ref.
+	FetchManifest(ctx context.Context, ref string) ([]byte, error)
+	// ListRefs returns the branch and tag names the source offers.
+	ListRefs(ctx context.Context) ([]string, error)
+	// FetchFile returns the content of a file at ref and the commit it came from.
+	FetchFile(ctx context.Context, path, ref string) ([]byte, string, error)
+	// FetchDirectory writes a directory tree at ref into destDir and returns the commit.
+	FetchDirectory(ctx context.Context, path, ref, destDir string) (string, error)
+	// ResolveCommit returns the commit ref points to.
+	ResolveCommit(ctx context.Context, ref string) (string, error)
+}
+
+// RefVerifier is implemented by sources whose revisions carry signatures.
+type RefVerifier interface {
+	// VerifyRef returns the commit ref points to and the key that signed it,
+	// or signed the annotated tag ref names. It fails unless one of them is
+	// signed by a key in keys.
+	VerifyRef(ctx context.Context, ref string, keys signing.Keyring) (commit, signer string, err error)
+}
+
+// VerifyRef verifies the signature of the revision ref names in src. Sources
+// without signed revisions (local, http, oci) always fail.
+func VerifyRef(ctx context.Context, src Source, ref string, keys signing.Keyring) (string, string, error) {
+	v, ok := src.(RefVerifier)
+	if !ok {
+		return "", "", fmt.Errorf("source has no signed commits or tags")
+	}
+	return v.VerifyRef(ctx, ref, keys)
+}
+
+// Options carries the environment a Source is created in.
+type Options struct {
+	// WorkspaceRoot anchors relative source paths.
+	WorkspaceRoot string
+	// CacheDir is where providers may keep their own caches (e.g. HTTP
+	// responses). Empty disables such caching.
+	CacheDir string
+	// Rewrites are applied to source and mirror URLs before fetching.
+	Rewrites []models.URLRewrite
+	// Network bounds and retries the requests of network sources. The zero
+	// Policy sends each request once without a timeout.
+	Network netutil.Policy
+	// HTTPClient sends the HTTP requests of providers, with the proxy and
+	// TLS settings applied. Nil means http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// httpClient returns the client providers send HTTP requests with.
+func (o Options) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// Factory creates a Source for a source configuration.
+type Factory func(cfg models.SourceConfig, opts Options) (Source, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[models.SourceType]Factory)
+)
+
+// Register makes a source type available to New. Registering a type twice
+// replaces the previous factory, which lets tests install fakes.
+func Register(t models.SourceType, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[t] = f
+}
+
+// New creates the Source for a source configuration. URL-based sources with
+// mirrors are wrapped in a Mirrored source; rewritten and mirror URLs are
+// treated as plain servers, so the provider API is only used for the
+// configured URL itself. URLs naming a directory written by `arca mirror`
+// are served by a local source, whatever the configured type. Network
+// sources are wrapped in a Retrying source unless opts.Network is zero.
+func New(cfg models.SourceConfig, opts Options) (Source, error) {
+	registryMu.RLock()
+	f, ok := registry[cfg.Type]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported source type: %s", cfg.Type)
+	}
+
+	urls := candidateURLs(cfg, opts.Rewrites)
+	if len(urls) == 0 || (len(urls) == 1 && urls[0] == cfg.URL && !isDirectoryMirror(cfg.URL)) {
+		src, err := f(cfg, opts)
+		if err != nil {
+			return nil, err
+		}
+		return withPolicy(src, opts), nil
+	}
+
+	mirrored := &Mirrored{URLs: urls}
+	for _, u := range urls {
+		c := cfg
+		c.URL = u
+		c.Mirrors = nil
+		if u != cfg.URL {
+			c.Provider = ""
+		}
+		if isDirectoryMirror(u) {
+			mirrored.Sources = append(mirrored.Sources, &Local{Root: strings.TrimPrefix(u, "file://")})
+			continue
+		}
+		src, err := f(c, opts)
+		if err != nil {
+			return nil, err
+		}
+		mirrored.Sources = append(mirrored.Sources, withPolicy(src, opts))
+	}
+	if len(mirrored.Sources) == 1 {
+		return mirrored.Sources[0], nil
+	}
+	return mirrored, nil
+}
+
+// withPolicy wraps a network source in a Retrying source.
+func withPolicy(src Source, opts Options) Source {
+	if _, local := src.(*Local); local || opts.Network.IsZero() {
+		return src
+	}
+	return &Retrying{Source: src, Policy: opts.Network}
+}
+
+// isDirectoryMirror reports whether rawURL is a local path (or file:// URL) to
+// a directory written by `arca mirror`.
+func isDirectoryMirror(rawURL string) bool {
+	path := strings.TrimPrefix(rawURL, "file://")
+	if !filepath.IsAbs(filepath.FromSlash(path)) {
+		return false
+	}
+	idx, err := LoadSnapshotIndex(path)
+	return err == nil && idx != nil
+}
+
+// Types lists the registered source types.
+func Types() []models.SourceType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]models.SourceType, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
@@ -0,0 +1,94 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/adryledo/arca-cli/internal/models"
+)
+
+type fakeSource struct{ Local }
+
+func TestRegistry(t *testing.T) {
+	const fakeType models.SourceType = "fake"
+	Register(fakeType, func(cfg models.SourceConfig, opts Options) (Source, error) {
+		return &fakeSource{Local{Root: cfg.Path}}, nil
+	})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, fakeType)
+		registryMu.Unlock()
+	})
+
+	src, err := New(models.SourceConfig{Type: fakeType, Path: "x"}, Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := src.(*fakeSource); !ok {
+		t.Errorf("Expected *fakeSource, got %T", src)
+	}
+
+	types := Types()
+	for _, want := range []models.SourceType{fakeType, models.SourceGit, models.SourceLocal} {
+		if !slices.Contains(types, want) {
+			t.Errorf("Expected %s to be registered, got %v", want, types)
+		}
+	}
+
+	if _, err := New(models.SourceConfig{Type: "unknown"}, Options{}); err == nil {
+		t.Error("Expected error for unsupported source type")
+	}
+}
+
+func TestLocal(t *testing.T) {
+	workspace := t.TempDir()
+	root := filepath.Join(workspace, "assets")
+	if err := os.MkdirAll(filepath.Join(root, "skills", "demo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		ManifestFileName:       "schema: 1\n",
+		"prompt.md":            "prompt",
+		"skills/demo/SKILL.md": "skill",
+		"skills/demo/ref/a.md": "nested",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Relative paths resolve against the workspace root.
+	src, err := New(models.SourceConfig{Type: models.SourceLocal, Path: "assets"}, Options{WorkspaceRoot: workspace})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	manifest, err := src.FetchManifest(t.Context(), "")
+	if err != nil || string(manifest) != "schema: 1\n" {
+		t.Errorf("Expected manifest content, got %q (%v)", manifest, err)
+	}
+
+	data, commit, err := src.FetchFile(t.Context(), "prompt.md", "main")
+	if err != nil {
+		t.Fatalf("FetchFile failed: %v", err)
+	}
+	if string(data) != "prompt" || commit != LocalCommit {
+		t.Errorf("Expected prompt@%s, got %s@%s", LocalCommit, data, commit)
+	}
+
+	dest := t.TempDir()
+	if _, err := src.FetchDirectory(t.Context(), "skills/demo", "", dest); err != nil {
+		t.Fatalf("FetchDirectory failed: %v", err)
+	}
+	nested, err := os.ReadFile(filepath.Join(dest, "ref", "a.md"))
+	if err != nil || string(nested) != "nested" {
+		t.Errorf("Expected nested file to be copied, got %q (%v)", nested, err)
+	}
+}